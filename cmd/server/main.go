@@ -1,103 +1,473 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	"intern-job-tracker/internal/anomaly"
 	"intern-job-tracker/internal/api"
+	"intern-job-tracker/internal/archive"
+	"intern-job-tracker/internal/classifier"
+	"intern-job-tracker/internal/cli"
+	"intern-job-tracker/internal/compensation"
+	"intern-job-tracker/internal/config"
 	"intern-job-tracker/internal/db"
+	"intern-job-tracker/internal/dedupe"
+	"intern-job-tracker/internal/events"
+	"intern-job-tracker/internal/heartbeat"
+	"intern-job-tracker/internal/history"
+	"intern-job-tracker/internal/location"
+	"intern-job-tracker/internal/logging"
+	"intern-job-tracker/internal/model"
 	"intern-job-tracker/internal/notifier"
 	"intern-job-tracker/internal/repository"
 	"intern-job-tracker/internal/scheduler"
+	"intern-job-tracker/internal/scoring"
 	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
 )
 
 func main() {
+	// Subcommands such as "companies list" share the database with the
+	// server but exit without starting it.
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.New().Run(os.Args[1:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "server" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	os.Exit(serve())
+}
+
+// serve runs the server until it fails or is signalled to stop, and returns
+// the process's exit code. Returning rather than exiting lets deferred
+// cleanup, such as closing the database, run on every path.
+func serve() int {
 	// Command line flags
+	configPath := flag.String("config", "", "YAML config file with settings and companies to track (see config.example.yaml)")
 	port := flag.String("port", "8080", "Server port")
 	dbPath := flag.String("db", "jobs.db", "Database file path")
 	recipient := flag.String("recipient", "", "iMessage recipient (phone or Apple ID)")
 	schedule := flag.String("schedule", "0 9 * * *", "Cron schedule for job checks")
+	timezone := flag.String("timezone", envOr("CRON_TZ", "Local"), "Time zone the schedule is evaluated in, e.g. \"America/New_York\" (default from CRON_TZ)")
+	notificationMode := flag.String("notification-mode", settings.NotifyAll, "Notifications to send: all, new_jobs or off")
+	scrapeConcurrency := flag.Int("scrape-concurrency", 1, "Number of companies to scrape at once")
 	runOnce := flag.Bool("run-once", false, "Run job check once and exit")
+	rulesPath := flag.String("rules", "", "JSON file with title classification rules (default: built-in rules)")
+	fetchDetails := flag.Bool("fetch-details", false, "Fetch each new job's detail page for its description, dates and salary")
+	detailInterval := flag.Duration("detail-interval", 2*time.Second, "Minimum delay between detail page requests")
+	detailSelectors := flag.String("detail-selectors", "", "Fallback selectors for detail pages, e.g. \"description=.job-body,deadline=#apply-by\"")
+	minScore := flag.Float64("min-score", 0, "Minimum relevance score for a job to be notified (0 notifies all)")
+	preferredLocations := flag.String("preferred-locations", "", "Comma-separated locations that raise a job's score, e.g. \"Seattle, New York\"")
+	companyTiers := flag.String("company-tiers", "", "Companies that raise a job's score by tier, e.g. \"Google=1,Stripe=2\" (1 is the most preferred, up to 3)")
+	targetTerm := flag.String("target-term", "", "Internship term that raises a job's score, e.g. \"Summer 2027\" (default: next summer, from July on)")
+	notifyWatched := flag.Bool("notify-watched-changes", false, "Notify when a watched job's posting changes")
+	archiveDir := flag.String("archive-dir", "", "Directory to archive snapshots of detail pages in (requires -fetch-details)")
+	archiveMaxAge := flag.Duration("archive-max-age", 365*24*time.Hour, "Remove snapshots older than this (0 keeps them)")
+	archiveMaxMB := flag.Int64("archive-max-mb", 500, "Remove the oldest snapshots once the archive exceeds this size in MB (0 for no limit)")
+	failurePercent := flag.Float64("alert-failure-percent", 50, "Alert when more than this percentage of companies fail in a run (0 disables)")
+	heartbeatWindow := flag.Duration("heartbeat-window", 26*time.Hour, "Alert when no run succeeds within this window (0 disables)")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "How long to wait for the active run to finish when shutting down")
+	logFormat := flag.String("log-format", logging.FormatText, "Log format: text or json")
+	logLevel := flag.String("log-level", "info", "Minimum level to log: debug, info, warn or error")
+	pingURL := flag.String("ping-url", "", "URL to request at the end of every run, healthchecks.io style (failures request URL/fail)")
 	flag.Parse()
 
-	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
-	log.SetPrefix("")
+	// Load the config file and TRACKER_ environment variables. Flags given on
+	// the command line take precedence.
+	fileConfig, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		return 1
+	}
+	if err := applyConfig(fileConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+
+	// Set up logging. Records of the standard log package go through the
+	// same handler.
+	logger, err := logging.New(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging options: %v\n", err)
+		return 1
+	}
+	slog.SetDefault(logger)
+	if *configPath != "" {
+		slog.Info("loaded configuration", "path", *configPath)
+	}
 
 	// Initialize database
 	database, err := db.New(*dbPath)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize database: %v", err)
+		slog.Error("failed to initialize database", "error", err)
+		return 1
 	}
+	// Closes the database on early returns. A graceful shutdown closes it
+	// itself to report errors; closing twice is harmless.
 	defer database.Close()
-	log.Println("✅ Database initialized")
+	slog.Info("database initialized", "path", *dbPath)
 
 	// Initialize repositories
 	jobRepo := repository.NewJobRepository(database)
 	companyRepo := repository.NewCompanyRepository(database)
 	runLogRepo := repository.NewRunLogRepository(database)
+	incidentRepo := repository.NewIncidentRepository(database)
+	configRepo := repository.NewConfigRepository(database)
+
+	// Seed or sync the companies table
+	if fileConfig.Companies != nil {
+		result, err := config.SyncCompanies(companyRepo, fileConfig.Companies, fileConfig.CompaniesMode)
+		if err != nil {
+			slog.Error("failed to sync companies", "error", err)
+			return 1
+		}
+		if result.Changed() {
+			slog.Info("synced companies from config", "added", result.Created, "updated", result.Updated, "disabled", result.Disabled)
+		}
+	} else if n, err := seedDefaultCompanies(companyRepo); err != nil {
+		slog.Warn("failed to add default companies", "error", err)
+	} else if n > 0 {
+		slog.Info("added default companies", "count", n)
+	}
+
+	// Load settings. Values saved through the API take precedence over flags.
+	settingsSvc, err := settings.NewService(configRepo, settings.Settings{
+		Recipient:         *recipient,
+		Schedule:          *schedule,
+		Timezone:          *timezone,
+		NotificationMode:  *notificationMode,
+		ScrapeConcurrency: *scrapeConcurrency,
+	})
+	if err != nil {
+		slog.Error("failed to load settings", "error", err)
+		return 1
+	}
+	cfg := settingsSvc.Get()
+
+	// Classify titles saved before classification existed
+	classifierRules := classifier.DefaultRules()
+	if *rulesPath != "" {
+		classifierRules, err = classifier.LoadRules(*rulesPath)
+		if err != nil {
+			slog.Error("failed to load classification rules", "error", err)
+			return 1
+		}
+	}
+	titleClassifier, err := classifier.New(classifierRules)
+	if err != nil {
+		slog.Error("invalid classification rules", "error", err)
+		return 1
+	}
+	if n, err := titleClassifier.Backfill(jobRepo); err != nil {
+		slog.Warn("failed to classify existing jobs", "error", err)
+	} else if n > 0 {
+		slog.Info("classified existing jobs", "count", n)
+	}
+
+	// Normalize locations saved before normalization existed
+	locationNormalizer := location.New()
+	if n, err := locationNormalizer.Backfill(jobRepo); err != nil {
+		slog.Warn("failed to normalize existing locations", "error", err)
+	} else if n > 0 {
+		slog.Info("normalized locations of existing jobs", "count", n)
+	}
+
+	// Parse pay from detail pages fetched before compensation parsing existed
+	if n, err := compensation.Backfill(jobRepo); err != nil {
+		slog.Warn("failed to parse compensation of existing jobs", "error", err)
+	} else if n > 0 {
+		slog.Info("parsed compensation of existing jobs", "count", n)
+	}
+
+	// Link duplicates among jobs saved before duplicate detection existed
+	if n, err := dedupe.Backfill(jobRepo); err != nil {
+		slog.Warn("failed to detect duplicates among existing jobs", "error", err)
+	} else if n > 0 {
+		slog.Info("linked existing duplicate jobs", "count", n)
+	}
+
+	// Hash jobs saved before change tracking existed
+	if n, err := history.Backfill(jobRepo); err != nil {
+		slog.Warn("failed to hash existing jobs", "error", err)
+	} else if n > 0 {
+		slog.Info("hashed existing jobs for change tracking", "count", n)
+	}
+
+	// Score jobs saved before scoring existed, then keep freshness decaying
+	// for jobs already stored
+	prefs := scoring.DefaultPreferences()
+	prefs.PreferredLocations = scoring.ParseLocations(*preferredLocations)
+	prefs.CompanyTiers, err = scoring.ParseCompanyTiers(*companyTiers)
+	if err != nil {
+		slog.Error("invalid company tiers", "error", err)
+		return 1
+	}
+	prefs.TargetTerm = *targetTerm
+	scorer := scoring.New(prefs)
+	rescoreJobs := func() {
+		if n, err := scorer.Rescore(jobRepo); err != nil {
+			slog.Warn("failed to rescore existing jobs", "error", err)
+		} else if n > 0 {
+			slog.Info("rescored existing jobs", "count", n)
+		}
+	}
+	rescoreJobs()
+
+	// Open the snapshot archive and apply its retention limits
+	var snapshots *archive.Archive
+	var pruneSnapshots func()
+	if *archiveDir != "" {
+		snapshots, err = archive.Open(*archiveDir)
+		if err != nil {
+			slog.Error("failed to open archive", "error", err)
+			return 1
+		}
+		retention := archive.Retention{MaxAge: *archiveMaxAge, MaxBytes: *archiveMaxMB << 20}
+		pruneSnapshots = func() {
+			if n, err := snapshots.Prune(jobRepo, retention); err != nil {
+				slog.Warn("failed to prune snapshot archive", "error", err)
+			} else if n > 0 {
+				slog.Info("pruned old snapshots", "count", n)
+			}
+		}
+		pruneSnapshots()
+	}
 
 	// Initialize components
 	jobNotifier := notifier.NewDefaultIMessageNotifier()
 	jobScraper := scraper.NewScraper(nil)
-	jobScheduler := scheduler.New(jobRepo, companyRepo, runLogRepo, jobScraper, jobNotifier, *recipient)
+	jobScheduler := scheduler.New(jobRepo, companyRepo, runLogRepo, jobScraper, jobNotifier, cfg.Recipient)
+	eventBus := events.NewBus()
+	jobScheduler.SetEventBus(eventBus)
+	if *fetchDetails {
+		selectors, err := scraper.ParseDetailSelectors(*detailSelectors)
+		if err != nil {
+			slog.Error("invalid detail selectors", "error", err)
+			return 1
+		}
+		jobScheduler.AddEnricher(scraper.NewDetailFetcher(nil, *detailInterval, selectors))
+		if snapshots != nil {
+			jobScheduler.AddEnricher(snapshots)
+		}
+		jobScheduler.AddEnricher(compensation.NewExtractor())
+	} else if snapshots != nil {
+		slog.Warn("-archive-dir has no effect without -fetch-details")
+	}
+	jobScheduler.AddEnricher(titleClassifier)
+	jobScheduler.AddEnricher(locationNormalizer)
+	jobScheduler.AddEnricher(scorer)
+	jobScheduler.SetAnomalyDetection(incidentRepo, anomaly.DefaultDetector())
+	jobScheduler.SetNotificationRules(scheduler.NotificationRules{
+		MinScore:       *minScore,
+		WatchedChanges: *notifyWatched,
+		Mode:           cfg.NotificationMode,
+	})
+	if err := jobScheduler.ApplySettings(cfg); err != nil {
+		slog.Error("invalid settings", "error", err)
+		return 1
+	}
+	if err := jobScheduler.SetStateStore(configRepo); err != nil {
+		slog.Warn("failed to load scheduler state", "error", err)
+	}
+	jobScheduler.SetAlertRules(scheduler.AlertRules{
+		FailurePercent: *failurePercent,
+		PingURL:        *pingURL,
+	})
 
 	// Run once mode
 	if *runOnce {
-		if *recipient == "" {
-			log.Println("⚠️  No recipient specified. Use -recipient=\"+1234567890\"")
+		if cfg.Recipient == "" {
+			slog.Warn("no recipient specified, use -recipient=+1234567890 to send notifications")
 		}
 		if err := jobScheduler.RunNow(); err != nil {
-			log.Fatalf("❌ Job check failed: %v", err)
+			slog.Error("job check failed", "error", err)
+			return 1
 		}
-		return
+		return 0
 	}
 
 	// Start scheduler
-	if *recipient != "" {
-		if err := jobScheduler.StartWithSchedule(*schedule); err != nil {
-			log.Fatalf("❌ Failed to start scheduler: %v", err)
+	var monitor *heartbeat.Monitor
+	if *heartbeatWindow > 0 {
+		monitor = heartbeat.New(runLogRepo, jobNotifier, cfg.Recipient, *heartbeatWindow)
+		monitor.SetPausedFunc(jobScheduler.Paused)
+	}
+	// Background work stops, and is waited for, before the database closes
+	stopBackground := make(chan struct{})
+	var background sync.WaitGroup
+	every(time.Hour, stopBackground, &background, rescoreJobs)
+	if pruneSnapshots != nil {
+		every(24*time.Hour, stopBackground, &background, pruneSnapshots)
+	}
+
+	var startOnce sync.Once
+	startScheduler := func(cfg settings.Settings) error {
+		var err error
+		startOnce.Do(func() {
+			if err = jobScheduler.StartWithSchedule(cfg.Schedule); err != nil {
+				return
+			}
+			if jobScheduler.Paused() {
+				slog.Info("scheduled checks are paused, resume them with POST /api/scheduler/resume")
+			} else {
+				slog.Info("scheduler running", "recipient", cfg.Recipient, "schedule", cfg.Schedule, "timezone", cfg.Location().String())
+				jobScheduler.CatchUp(time.Now())
+			}
+
+			if monitor != nil {
+				go monitor.Start(15*time.Minute, stopBackground)
+				slog.Info("heartbeat monitor started", "window", heartbeatWindow.String())
+			}
+		})
+		return err
+	}
+	if cfg.Recipient != "" {
+		if err := startScheduler(cfg); err != nil {
+			slog.Error("failed to start scheduler", "error", err)
+			return 1
 		}
-		log.Printf("✅ Scheduler started (recipient: %s, schedule: %s)", *recipient, *schedule)
 	} else {
-		log.Println("⚠️  No recipient configured - scheduler disabled")
-		log.Println("   Run with -recipient=\"+1234567890\" to enable notifications")
+		slog.Warn("no recipient configured, scheduler disabled; run with -recipient=+1234567890 or set one in the dashboard")
 	}
 
+	// Apply settings changed through the API
+	settingsSvc.OnChange(func(cfg settings.Settings) {
+		if err := jobScheduler.ApplySettings(cfg); err != nil {
+			slog.Warn("failed to apply settings", "error", err)
+			return
+		}
+		if monitor != nil {
+			monitor.SetRecipient(cfg.Recipient)
+		}
+		if cfg.Recipient != "" {
+			if err := startScheduler(cfg); err != nil {
+				slog.Error("failed to start scheduler", "error", err)
+				return
+			}
+		}
+		slog.Info("settings updated")
+	})
+
 	// Initialize API
 	handler := api.NewHandler(jobRepo, companyRepo, runLogRepo, jobScheduler)
+	if snapshots != nil {
+		handler.SetArchive(snapshots)
+	}
+	handler.SetIncidentRepository(incidentRepo)
+	handler.SetEventBus(eventBus)
+	handler.SetSettings(settingsSvc)
 	router := handler.Router()
 
-	// Graceful shutdown
+	// Start server
+	addr := ":" + *port
+	srv := &http.Server{Addr: addr, Handler: router}
+	// End live event streams, which would otherwise hold up the shutdown
+	srv.RegisterOnShutdown(eventBus.Close)
+	slog.Info("server starting", "addr", addr, "dashboard", "http://localhost"+addr)
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- srv.ListenAndServe() }()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		slog.Error("server failed", "error", err)
+		exitCode = 1
+	case <-sigCh:
+	}
+
+	// Graceful shutdown: stop taking requests and scheduling runs, let the
+	// active run and its notifications finish, then close the database. A
+	// second signal exits immediately.
+	slog.Info("shutting down", "timeout", shutdownTimeout.String())
 	go func() {
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
-		log.Println("\n⏹️  Shutting down...")
-		jobScheduler.Stop()
-		os.Exit(0)
+		slog.Warn("forced exit")
+		os.Exit(1)
 	}()
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
 
-	// Start server
-	addr := ":" + *port
-	log.Println("═══════════════════════════════════════════")
-	log.Printf("🚀 Server starting on http://localhost%s", addr)
-	log.Println("───────────────────────────────────────────")
-	log.Println("📊 Dashboard: http://localhost" + addr)
-	log.Println("📡 API Endpoints:")
-	log.Println("   GET  /api/jobs       - List all jobs")
-	log.Println("   GET  /api/companies  - List companies")
-	log.Println("   POST /api/companies  - Add company")
-	log.Println("   GET  /api/metrics    - View metrics")
-	log.Println("   GET  /api/logs       - View run history")
-	log.Println("   POST /api/refresh    - Trigger job check")
-	log.Println("═══════════════════════════════════════════")
-
-	if err := http.ListenAndServe(addr, router); err != nil {
-		log.Fatalf("❌ Server failed: %v", err)
+	jobScheduler.Stop()
+	close(stopBackground)
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP server shutdown failed", "error", err)
+	}
+	if err := jobScheduler.Shutdown(ctx); err != nil {
+		slog.Warn("active run interrupted", "error", err)
+	}
+	background.Wait()
+	if err := database.Close(); err != nil {
+		slog.Warn("failed to close database", "error", err)
+	}
+	slog.Info("shutdown complete")
+	return exitCode
+}
+
+// applyConfig sets the flags the config file or environment set, unless they
+// were given on the command line.
+func applyConfig(cfg *config.Config) error {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for name, value := range cfg.Flags {
+		if explicit[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("-%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// seedDefaultCompanies adds the built-in companies if none are tracked yet.
+func seedDefaultCompanies(repo *repository.CompanyRepository) (int, error) {
+	existing, err := repo.GetAll()
+	if err != nil || len(existing) > 0 {
+		return 0, err
+	}
+	var companies []*model.Company
+	for _, c := range scraper.DefaultCompanies() {
+		companies = append(companies, &model.Company{Name: c.Name, CareerURL: c.CareerURL, SearchTerm: c.SearchTerm, Enabled: true})
+	}
+	result, err := config.SyncCompanies(repo, companies, config.ModeSeed)
+	return result.Created, err
+}
+
+// every calls fn every interval in the background until stop is closed. wg
+// tracks the call in progress, so it can be waited for.
+func every(interval time.Duration, stop <-chan struct{}, wg *sync.WaitGroup, fn func()) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+}
+
+// envOr returns the environment variable key, or fallback if it is not set.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return fallback
 }
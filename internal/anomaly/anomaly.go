@@ -0,0 +1,102 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+
+	"intern-job-tracker/internal/model"
+)
+
+// Reasons a scrape is flagged.
+const (
+	// ReasonEmpty means the page listed no jobs although it usually lists some.
+	ReasonEmpty = "empty"
+	// ReasonDrop means the page listed far fewer jobs than usual.
+	ReasonDrop = "drop"
+	// ReasonFingerprint means the page's markup structure changed.
+	ReasonFingerprint = "fingerprint"
+	// ReasonChallenge means a bot check was served instead of the page.
+	ReasonChallenge = "challenge"
+)
+
+// Anomaly is one reason a scrape looks broken.
+type Anomaly struct {
+	Reason string
+	Detail string
+}
+
+// Detector compares a company's latest scrape with its recent history.
+type Detector struct {
+	// Window is how many earlier successful scrapes form the baseline.
+	Window int
+	// DropRatio flags a scrape finding fewer than this fraction of the
+	// baseline's average job count.
+	DropRatio float64
+	// MinBaseline is the smallest average job count a drop is judged
+	// against, so that small listings going from 2 to 1 are not flagged.
+	MinBaseline float64
+}
+
+// DefaultDetector returns a detector comparing against the last 7 successful
+// scrapes and flagging drops below half of their average.
+func DefaultDetector() Detector {
+	return Detector{Window: 7, DropRatio: 0.5, MinBaseline: 4}
+}
+
+// Check returns the anomalies in the current scrape, given the company's
+// earlier scrapes, newest first. Failed scrapes are only checked for bot
+// challenges, since their job count says nothing about the page. A company
+// without successful scrapes has no baseline and is not flagged otherwise.
+func (d Detector) Check(current *model.CompanyRun, previous []*model.CompanyRun) []Anomaly {
+	if current.Challenge {
+		return []Anomaly{{Reason: ReasonChallenge, Detail: fmt.Sprintf("bot challenge served (HTTP %d)", current.HTTPStatus)}}
+	}
+	if current.Status != "success" {
+		return nil
+	}
+
+	var baseline []*model.CompanyRun
+	for _, run := range previous {
+		if run.Status == "success" {
+			baseline = append(baseline, run)
+			if len(baseline) == d.Window {
+				break
+			}
+		}
+	}
+	if len(baseline) == 0 {
+		return nil
+	}
+
+	var anomalies []Anomaly
+	total := 0
+	for _, run := range baseline {
+		total += run.JobsFound
+	}
+	avg := float64(total) / float64(len(baseline))
+	switch {
+	case current.JobsFound == 0 && avg >= 1:
+		anomalies = append(anomalies, Anomaly{
+			Reason: ReasonEmpty,
+			Detail: fmt.Sprintf("found no jobs, usually %s", formatAvg(avg)),
+		})
+	case avg >= d.MinBaseline && float64(current.JobsFound) < avg*d.DropRatio:
+		anomalies = append(anomalies, Anomaly{
+			Reason: ReasonDrop,
+			Detail: fmt.Sprintf("found %d jobs, usually %s", current.JobsFound, formatAvg(avg)),
+		})
+	}
+
+	last := baseline[0].Fingerprint
+	if current.Fingerprint != "" && last != "" && current.Fingerprint != last {
+		anomalies = append(anomalies, Anomaly{
+			Reason: ReasonFingerprint,
+			Detail: "career page layout changed",
+		})
+	}
+	return anomalies
+}
+
+func formatAvg(avg float64) string {
+	return fmt.Sprintf("%g", math.Round(avg*10)/10)
+}
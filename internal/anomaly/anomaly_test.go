@@ -0,0 +1,66 @@
+package anomaly
+
+import (
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func success(jobs int, fingerprint string) *model.CompanyRun {
+	return &model.CompanyRun{Status: "success", JobsFound: jobs, Fingerprint: fingerprint}
+}
+
+func TestDetector_Check(t *testing.T) {
+	history := []*model.CompanyRun{
+		success(10, "abc"),
+		{Status: "error", ErrorClass: "timeout"},
+		success(12, "abc"),
+		success(8, "abc"),
+	}
+
+	tests := []struct {
+		name     string
+		current  *model.CompanyRun
+		previous []*model.CompanyRun
+		reasons  []string
+	}{
+		{"steady", success(9, "abc"), history, nil},
+		{"empty", success(0, "abc"), history, []string{ReasonEmpty}},
+		{"sharp drop", success(3, "abc"), history, []string{ReasonDrop}},
+		{"redesign", success(0, "def"), history, []string{ReasonEmpty, ReasonFingerprint}},
+		{"challenge", &model.CompanyRun{Status: "error", Challenge: true, HTTPStatus: 403}, history, []string{ReasonChallenge}},
+		{"plain failure", &model.CompanyRun{Status: "error", ErrorClass: "network"}, history, nil},
+		{"no baseline", success(0, "abc"), nil, nil},
+		{"small listing", success(1, "abc"), []*model.CompanyRun{success(2, "abc"), success(3, "abc")}, nil},
+		{"always empty", success(0, "abc"), []*model.CompanyRun{success(0, "abc")}, nil},
+		{"no fingerprint before", success(9, "abc"), []*model.CompanyRun{success(9, "")}, nil},
+	}
+	for _, tt := range tests {
+		got := DefaultDetector().Check(tt.current, tt.previous)
+		if len(got) != len(tt.reasons) {
+			t.Errorf("%s: expected %v, got %+v", tt.name, tt.reasons, got)
+			continue
+		}
+		for i, a := range got {
+			if a.Reason != tt.reasons[i] {
+				t.Errorf("%s: expected %v, got %+v", tt.name, tt.reasons, got)
+			}
+		}
+	}
+}
+
+func TestDetector_CheckDetail(t *testing.T) {
+	got := DefaultDetector().Check(success(0, ""), []*model.CompanyRun{success(5, ""), success(6, "")})
+	if len(got) != 1 || got[0].Detail != "found no jobs, usually 5.5" {
+		t.Errorf("unexpected anomalies %+v", got)
+	}
+}
+
+func TestDetector_Window(t *testing.T) {
+	// Only the most recent scrapes count towards the baseline.
+	previous := []*model.CompanyRun{success(2, ""), success(2, ""), success(50, "")}
+	d := Detector{Window: 2, DropRatio: 0.5, MinBaseline: 1}
+	if got := d.Check(success(1, ""), previous); len(got) != 0 {
+		t.Errorf("expected no anomalies, got %+v", got)
+	}
+}
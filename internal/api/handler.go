@@ -2,19 +2,43 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"intern-job-tracker/internal/archive"
+	"intern-job-tracker/internal/events"
+	"intern-job-tracker/internal/logging"
+	"intern-job-tracker/internal/metrics"
 	"intern-job-tracker/internal/model"
 	"intern-job-tracker/internal/repository"
+	"intern-job-tracker/internal/scheduler"
+	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// SchedulerRunner interface for triggering manual refresh.
+// SchedulerRunner interface for triggering manual refresh and following its
+// progress.
 type SchedulerRunner interface {
-	RunNow() error
+	// Enqueue starts a run in the background, or returns the active run with
+	// created set to false.
+	Enqueue() (run *model.Run, created bool)
+	GetRun(id int64) (*model.Run, bool)
+	CancelRun(id int64) bool
+	// SyncCompanySchedules picks up changes to companies' own schedules.
+	SyncCompanySchedules() error
+	Status() model.SchedulerStatus
+	// Pause and Resume stop and restart scheduled checks.
+	Pause() error
+	Resume() error
 }
 
 // Handler manages HTTP API endpoints.
@@ -23,16 +47,47 @@ type Handler struct {
 	companyRepo *repository.CompanyRepository
 	runLogRepo  *repository.RunLogRepository
 	scheduler   SchedulerRunner
+	archive     *archive.Archive
+	incidents   *repository.IncidentRepository
+	events      *events.Bus
+	settings    *settings.Service
+	scraper     *scraper.Scraper
+	// detect finds the applicant tracking system behind a career URL when
+	// a company is added without a source type.
+	detect func(careerURL string) (*scraper.Detection, error)
 }
 
 // NewHandler creates a new API handler.
 func NewHandler(jobRepo *repository.JobRepository, companyRepo *repository.CompanyRepository, runLogRepo *repository.RunLogRepository, scheduler SchedulerRunner) *Handler {
-	return &Handler{
+	h := &Handler{
 		jobRepo:     jobRepo,
 		companyRepo: companyRepo,
 		runLogRepo:  runLogRepo,
 		scheduler:   scheduler,
+		scraper:     scraper.NewScraper(nil),
 	}
+	h.detect = h.scraper.Detect
+	return h
+}
+
+// SetArchive enables serving archived snapshots of detail pages.
+func (h *Handler) SetArchive(a *archive.Archive) {
+	h.archive = a
+}
+
+// SetIncidentRepository enables listing scrape incidents.
+func (h *Handler) SetIncidentRepository(incidents *repository.IncidentRepository) {
+	h.incidents = incidents
+}
+
+// SetEventBus enables streaming scheduler events to the dashboard.
+func (h *Handler) SetEventBus(bus *events.Bus) {
+	h.events = bus
+}
+
+// SetSettings enables viewing and changing runtime settings.
+func (h *Handler) SetSettings(svc *settings.Service) {
+	h.settings = svc
 }
 
 // Router returns the configured chi router.
@@ -40,7 +95,8 @@ func (h *Handler) Router() *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middleware
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	r.Use(requestLogger)
 	r.Use(middleware.Recoverer)
 	r.Use(corsMiddleware)
 
@@ -49,22 +105,43 @@ func (h *Handler) Router() *chi.Mux {
 		// Jobs
 		r.Get("/jobs", h.listJobs)
 		r.Get("/jobs/{id}", h.getJob)
+		r.Get("/jobs/{id}/history", h.getJobHistory)
+		r.Get("/jobs/{id}/snapshot", h.getJobSnapshot)
+		r.Put("/jobs/{id}/watch", h.watchJob)
 
 		// Companies
 		r.Get("/companies", h.listCompanies)
 		r.Post("/companies", h.createCompany)
+		r.Post("/companies/preview", h.previewCompany)
 		r.Put("/companies/{id}", h.updateCompany)
 		r.Delete("/companies/{id}", h.deleteCompany)
+		r.Get("/companies/health", h.listCompanyHealth)
+		r.Get("/companies/{id}/health", h.getCompanyHealth)
 
 		// Metrics & Stats
 		r.Get("/stats", h.getStats)
 		r.Get("/metrics", h.getMetrics)
 		r.Get("/logs", h.getRunLogs)
+		r.Get("/incidents", h.listIncidents)
 
 		// Actions
 		r.Post("/refresh", h.triggerRefresh)
+		r.Get("/runs/{id}", h.getRun)
+		r.Delete("/runs/{id}", h.cancelRun)
+		r.Get("/events", h.streamEvents)
+		r.Get("/scheduler", h.getScheduler)
+		r.Put("/scheduler", h.updateScheduler)
+		r.Post("/scheduler/pause", h.pauseScheduler)
+		r.Post("/scheduler/resume", h.resumeScheduler)
+
+		// Settings
+		r.Get("/settings", h.getSettings)
+		r.Put("/settings", h.updateSettings)
 	})
 
+	// Prometheus metrics
+	r.Handle("/metrics", metrics.Handler())
+
 	// Serve static files
 	r.Handle("/*", http.FileServer(http.Dir("web")))
 
@@ -72,7 +149,39 @@ func (h *Handler) Router() *chi.Mux {
 }
 
 func (h *Handler) listJobs(w http.ResponseWriter, r *http.Request) {
-	jobs, err := h.jobRepo.GetAll()
+	q := r.URL.Query()
+	filter := repository.JobFilter{
+		Company:           q.Get("company"),
+		Source:            q.Get("source"),
+		Season:            q.Get("season"),
+		DegreeLevel:       q.Get("degree_level"),
+		RoleFamily:        q.Get("role_family"),
+		EmploymentType:    q.Get("employment_type"),
+		Region:            strings.ToUpper(q.Get("state")),
+		Country:           strings.ToUpper(q.Get("country")),
+		Remote:            q.Get("remote") == "true",
+		IncludeDuplicates: q.Get("duplicates") == "true",
+	}
+	if y := q.Get("year"); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil {
+			http.Error(w, "invalid year", http.StatusBadRequest)
+			return
+		}
+		filter.Year = year
+	}
+	for param, dest := range map[string]*float64{"min_hourly": &filter.MinHourly, "max_hourly": &filter.MaxHourly} {
+		if v := q.Get(param); v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil || parsed < 0 {
+				http.Error(w, "invalid "+param, http.StatusBadRequest)
+				return
+			}
+			*dest = parsed
+		}
+	}
+
+	jobs, err := h.jobRepo.List(filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -102,6 +211,117 @@ func (h *Handler) getJob(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, job)
 }
 
+func (h *Handler) getJobHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := h.jobRepo.GetRevisions(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, revisions)
+}
+
+// getJobSnapshot serves the archived detail page of a job, or its extracted
+// text with ?format=text.
+func (h *Handler) getJobSnapshot(w http.ResponseWriter, r *http.Request) {
+	if h.archive == nil {
+		http.Error(w, "snapshot archive not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil || job.Snapshot == nil {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+
+	hash, contentType := job.Snapshot.HTMLHash, "text/html; charset=utf-8"
+	switch r.URL.Query().Get("format") {
+	case "", "html":
+	case "text":
+		hash, contentType = job.Snapshot.TextHash, "text/plain; charset=utf-8"
+	default:
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.archive.Get(hash)
+	if err == archive.ErrNotFound {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	// Archived pages are third-party HTML; keep their scripts from running
+	// on the dashboard's origin.
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Write(data)
+}
+
+func (h *Handler) watchJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Watched bool `json:"watched"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.jobRepo.SetWatched(id, body.Watched); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	job.Watched = body.Watched
+	respondJSON(w, job)
+}
+
 func (h *Handler) listCompanies(w http.ResponseWriter, r *http.Request) {
 	if h.companyRepo == nil {
 		respondJSON(w, []interface{}{})
@@ -136,16 +356,49 @@ func (h *Handler) createCompany(w http.ResponseWriter, r *http.Request) {
 		company.SearchTerm = "intern"
 	}
 	company.Enabled = true
+	if err := scheduler.ValidateCompany(&company); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := checkLocalFile(&company, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if company.SourceType == "" && company.Selectors == "" {
+		h.detectSource(r, &company)
+	}
 
 	if err := h.companyRepo.Create(&company); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	h.syncCompanySchedules(r)
 	w.WriteHeader(http.StatusCreated)
 	respondJSON(w, company)
 }
 
+// detectSource fills in a company's source from the applicant tracking
+// system behind its career URL, if one is recognized. Companies on other
+// sites, or whose page can't be fetched, keep the HTML source.
+func (h *Handler) detectSource(r *http.Request, company *model.Company) {
+	if h.detect == nil {
+		return
+	}
+	logger := logging.FromContext(r.Context())
+	d, err := h.detect(company.CareerURL)
+	if err != nil {
+		logger.Warn("failed to detect applicant tracking system", "company", company.Name, "error", err)
+		return
+	}
+	if d == nil {
+		return
+	}
+	company.SourceType, company.SourceID = d.Platform, d.SourceID
+	logger.Info("detected applicant tracking system", "company", company.Name, "platform", d.Platform,
+		"source_id", d.SourceID, "evidence", d.Evidence)
+}
+
 func (h *Handler) updateCompany(w http.ResponseWriter, r *http.Request) {
 	if h.companyRepo == nil {
 		http.Error(w, "company management not available", http.StatusServiceUnavailable)
@@ -166,14 +419,44 @@ func (h *Handler) updateCompany(w http.ResponseWriter, r *http.Request) {
 	}
 
 	company.ID = id
+	if err := scheduler.ValidateCompany(&company); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if company.SourceType == scraper.SourceMarkdown && company.SourceID != "" {
+		stored, err := h.companyRepo.GetByID(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := checkLocalFile(&company, stored); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 	if err := h.companyRepo.Update(&company); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.syncCompanySchedules(r)
 
 	respondJSON(w, company)
 }
 
+// checkLocalFile refuses Markdown lists read from a local file, unless the
+// stored company already reads that file, so the API can't be used to read
+// files on the server. Such lists are set up in the config file or with the
+// command line.
+func checkLocalFile(company, stored *model.Company) error {
+	if company.SourceType != scraper.SourceMarkdown || company.SourceID == "" {
+		return nil
+	}
+	if stored != nil && stored.SourceType == scraper.SourceMarkdown && stored.SourceID == company.SourceID {
+		return nil
+	}
+	return errors.New("lists read from a local file can only be set up in the config file or with the command line")
+}
+
 func (h *Handler) deleteCompany(w http.ResponseWriter, r *http.Request) {
 	if h.companyRepo == nil {
 		http.Error(w, "company management not available", http.StatusServiceUnavailable)
@@ -191,10 +474,170 @@ func (h *Handler) deleteCompany(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.syncCompanySchedules(r)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// companyPreview is the outcome of scraping a company that is not saved.
+type companyPreview struct {
+	Jobs       []*previewJob       `json:"jobs"`
+	Rejected   []scraper.Rejection `json:"rejected"`
+	StatusCode int                 `json:"status_code"`
+	FinalURL   string              `json:"final_url,omitempty"`
+	Pages      int                 `json:"pages"`
+	Bytes      int64               `json:"bytes"`
+	DurationMs int64               `json:"duration_ms"`
+	// Platform is the company's source type if it reads from a platform,
+	// or else the platform detected on its career page.
+	Platform   string             `json:"platform,omitempty"`
+	Detection  *scraper.Detection `json:"detection,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	ErrorClass string             `json:"error_class,omitempty"`
+}
+
+// previewJob is a job found by a preview. Known jobs are already saved.
+type previewJob struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Location string `json:"location,omitempty"`
+	Known    bool   `json:"known"`
+}
+
+// previewCompany scrapes a candidate company without saving anything, so its
+// URL, search term and selectors can be tried out before it is added. A
+// failed scrape is reported in the preview rather than as an error.
+func (h *Handler) previewCompany(w http.ResponseWriter, r *http.Request) {
+	var company model.Company
+	if err := json.NewDecoder(r.Body).Decode(&company); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if u, err := url.Parse(company.CareerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "career_url must be an http or https URL", http.StatusBadRequest)
+		return
+	}
+	if company.SearchTerm == "" {
+		company.SearchTerm = "intern"
+	}
+	config, err := scraper.ConfigFor(&company)
+	if err == nil {
+		err = checkLocalFile(&company, nil)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.scraper.Preview(config)
+	preview := companyPreview{
+		Jobs:       []*previewJob{},
+		Rejected:   result.Rejected,
+		StatusCode: result.StatusCode,
+		FinalURL:   result.FinalURL,
+		Pages:      result.Pages,
+		Bytes:      result.Bytes,
+		DurationMs: result.Duration.Milliseconds(),
+		Detection:  result.Detection,
+		ErrorClass: result.ErrorClass,
+	}
+	switch {
+	case config.SourceType != "" && config.SourceType != scraper.SourceHTML:
+		preview.Platform = config.SourceType
+	case result.Detection != nil:
+		preview.Platform = result.Detection.Platform
+	}
+	if err != nil {
+		preview.Error = err.Error()
+	}
+	if preview.Rejected == nil {
+		preview.Rejected = []scraper.Rejection{}
+	}
+	for _, job := range result.Jobs {
+		p := &previewJob{Title: job.Title, URL: job.URL, Location: job.Location}
+		if h.jobRepo != nil {
+			existing, err := h.jobRepo.GetByURL(job.URL)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			p.Known = existing != nil
+		}
+		preview.Jobs = append(preview.Jobs, p)
+	}
+	respondJSON(w, preview)
+}
+
+// syncCompanySchedules tells the scheduler that companies changed.
+func (h *Handler) syncCompanySchedules(r *http.Request) {
+	if h.scheduler == nil {
+		return
+	}
+	if err := h.scheduler.SyncCompanySchedules(); err != nil {
+		logging.FromContext(r.Context()).Warn("failed to update company schedules", "error", err)
+	}
+}
+
+// healthWindow is the number of recent scrapes a company's health covers.
+const healthWindow = 30
+
+func (h *Handler) getCompanyHealth(w http.ResponseWriter, r *http.Request) {
+	if h.companyRepo == nil || h.runLogRepo == nil {
+		http.Error(w, "company health not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	company, err := h.companyRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if company == nil {
+		http.Error(w, "company not found", http.StatusNotFound)
+		return
+	}
+
+	health, err := h.runLogRepo.GetCompanyHealth(company, healthWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, health)
+}
+
+// listCompanyHealth returns the health of every company, for the dashboard.
+func (h *Handler) listCompanyHealth(w http.ResponseWriter, r *http.Request) {
+	if h.companyRepo == nil || h.runLogRepo == nil {
+		respondJSON(w, []interface{}{})
+		return
+	}
+
+	companies, err := h.companyRepo.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	healths := make([]*model.CompanyHealth, 0, len(companies))
+	for _, company := range companies {
+		health, err := h.runLogRepo.GetCompanyHealth(company, healthWindow)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		healths = append(healths, health)
+	}
+
+	respondJSON(w, healths)
+}
+
 func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
 	jobs, err := h.jobRepo.GetAll()
 	if err != nil {
@@ -211,10 +654,31 @@ func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	facets, err := h.jobRepo.Facets()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	byLocation, err := h.jobRepo.LocationCounts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	compensation, err := h.jobRepo.CompensationByCompany()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	stats := map[string]interface{}{
-		"total_jobs": len(jobs),
-		"notified":   notified,
-		"by_company": byCompany,
+		"total_jobs":              len(jobs),
+		"notified":                notified,
+		"by_company":              byCompany,
+		"by_location":             byLocation,
+		"facets":                  facets,
+		"compensation_by_company": compensation,
 	}
 
 	respondJSON(w, stats)
@@ -275,18 +739,242 @@ func (h *Handler) getRunLogs(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, logs)
 }
 
+func (h *Handler) listIncidents(w http.ResponseWriter, r *http.Request) {
+	if h.incidents == nil {
+		respondJSON(w, []interface{}{})
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	incidents, err := h.incidents.List(r.URL.Query().Get("open") == "true", limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if incidents == nil {
+		incidents = []*model.Incident{}
+	}
+
+	respondJSON(w, incidents)
+}
+
 func (h *Handler) triggerRefresh(w http.ResponseWriter, r *http.Request) {
 	if h.scheduler == nil {
 		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
 		return
 	}
 
-	if err := h.scheduler.RunNow(); err != nil {
+	run, created := h.scheduler.Enqueue()
+	w.Header().Set("Location", fmt.Sprintf("/api/runs/%d", run.ID))
+	w.WriteHeader(http.StatusAccepted)
+	respondJSON(w, map[string]interface{}{
+		"run_id":    run.ID,
+		"state":     run.State,
+		"coalesced": !created,
+	})
+}
+
+func (h *Handler) getRun(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	run, ok := h.scheduler.GetRun(id)
+	if !ok {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, run)
+}
+
+func (h *Handler) cancelRun(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.scheduler.GetRun(id); !ok {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return
+	}
+	if !h.scheduler.CancelRun(id) {
+		http.Error(w, "run already finished", http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) getScheduler(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	respondJSON(w, h.scheduler.Status())
+}
+
+// updateScheduler changes the schedule or its time zone. The change is saved
+// as a setting, so it applies the same way as through /api/settings.
+func (h *Handler) updateScheduler(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil || h.settings == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		Schedule *string `json:"schedule"`
+		Timezone *string `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.settings.Update(settings.Update{Schedule: body.Schedule, Timezone: body.Timezone})
+	var invalid *settings.ValidationError
+	if errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, h.scheduler.Status())
+}
+
+func (h *Handler) pauseScheduler(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.scheduler.Pause(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, h.scheduler.Status())
+}
+
+func (h *Handler) resumeScheduler(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.scheduler.Resume(); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	respondJSON(w, map[string]string{"status": "ok", "message": "refresh triggered"})
+	respondJSON(w, h.scheduler.Status())
+}
+
+func (h *Handler) getSettings(w http.ResponseWriter, r *http.Request) {
+	if h.settings == nil {
+		http.Error(w, "settings not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	respondJSON(w, h.settings.Get())
+}
+
+func (h *Handler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	if h.settings == nil {
+		http.Error(w, "settings not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var update settings.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.settings.Update(update)
+	var invalid *settings.ValidationError
+	if errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, updated)
+}
+
+// eventKeepAlive is how often an idle event stream sends a comment, so that
+// proxies do not close the connection.
+const eventKeepAlive = 30 * time.Second
+
+// streamEvents streams scheduler events as Server-Sent Events until the client
+// disconnects.
+func (h *Handler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.events == nil {
+		http.Error(w, "events not configured", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
 }
 
 func respondJSON(w http.ResponseWriter, data interface{}) {
@@ -294,6 +982,32 @@ func respondJSON(w http.ResponseWriter, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// requestLogger logs every request with the ID middleware.RequestID gave it,
+// and passes handlers a logger carrying the ID in the request context. The ID
+// is returned in the X-Request-Id header so that users can quote it.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqID := middleware.GetReqID(r.Context())
+		w.Header().Set("X-Request-Id", reqID)
+		logger := slog.With("request_id", reqID)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		next.ServeHTTP(ww, r.WithContext(logging.WithLogger(r.Context(), logger)))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(r.Context(), level, "request", "method", r.Method, "path", r.URL.Path, "status", status,
+			"bytes", ww.BytesWritten(), "duration_ms", time.Since(start).Milliseconds(), "remote", r.RemoteAddr)
+	})
+}
+
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
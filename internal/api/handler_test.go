@@ -1,16 +1,26 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"intern-job-tracker/internal/archive"
 	"intern-job-tracker/internal/db"
+	"intern-job-tracker/internal/events"
 	"intern-job-tracker/internal/model"
 	"intern-job-tracker/internal/repository"
+	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
 )
 
 func setupTestAPI(t *testing.T) (*Handler, func()) {
@@ -30,6 +40,16 @@ func setupTestAPI(t *testing.T) (*Handler, func()) {
 	companyRepo := repository.NewCompanyRepository(database)
 	runLogRepo := repository.NewRunLogRepository(database)
 	handler := NewHandler(jobRepo, companyRepo, runLogRepo, nil)
+	handler.SetIncidentRepository(repository.NewIncidentRepository(database))
+	svc, err := settings.NewService(repository.NewConfigRepository(database), settings.Settings{
+		Schedule: "0 9 * * *", Timezone: "Local", NotificationMode: settings.NotifyAll, ScrapeConcurrency: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	handler.SetSettings(svc)
+	// Companies added in tests are not looked up on the network.
+	handler.detect = nil
 
 	cleanup := func() {
 		database.Close()
@@ -94,6 +114,136 @@ func TestAPI_ListCompanies(t *testing.T) {
 	}
 }
 
+func TestAPI_CompanySchedule(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+	sched := &fakeScheduler{runs: make(map[int64]*model.Run)}
+	handler.scheduler = sched
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	for _, body := range []string{
+		`{"name": "Meta", "career_url": "https://meta.com/careers", "schedule": "every day"}`,
+		`{"name": "Meta", "career_url": "https://meta.com/careers", "schedule": "10s"}`,
+		`{"name": "Meta", "career_url": "https://meta.com/careers", "active_from": "August 1"}`,
+		`{"name": "Meta", "career_url": "https://meta.com/careers", "active_from": "2026-09-01", "active_until": "2026-08-01"}`,
+	} {
+		if w := send("POST", "/api/companies", body); w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+	if sched.synced != 0 {
+		t.Errorf("expected no schedule sync for rejected companies, got %d", sched.synced)
+	}
+
+	w := send("POST", "/api/companies", `{"name": "Meta", "career_url": "https://meta.com/careers", "schedule": "15m", "active_from": "2026-08-01", "active_until": "2026-08-31"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created model.Company
+	json.NewDecoder(w.Body).Decode(&created)
+
+	saved, _ := handler.companyRepo.GetByID(created.ID)
+	if saved.Schedule != "15m" || saved.ActiveFrom != "2026-08-01" || saved.ActiveUntil != "2026-08-31" {
+		t.Errorf("unexpected saved company %+v", saved)
+	}
+
+	w = send("PUT", fmt.Sprintf("/api/companies/%d", created.ID), `{"name": "Meta", "career_url": "https://meta.com/careers", "enabled": true, "schedule": "0 9 * * 1"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	saved, _ = handler.companyRepo.GetByID(created.ID)
+	if saved.Schedule != "0 9 * * 1" || saved.ActiveFrom != "" {
+		t.Errorf("unexpected updated company %+v", saved)
+	}
+
+	send("DELETE", fmt.Sprintf("/api/companies/%d", created.ID), "")
+	if sched.synced != 3 {
+		t.Errorf("expected the schedules to be synced after each change, got %d", sched.synced)
+	}
+}
+
+func TestAPI_CreateCompany_DetectsSource(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	careers := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<html><body><div id="grnhse_app"></div>
+			<script src="https://boards.greenhouse.io/embed/job_board/js?for=acme"></script></body></html>`))
+	}))
+	defer careers.Close()
+	handler.detect = scraper.NewScraper(careers.Client()).Detect
+
+	create := func(body string) model.Company {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/companies", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var c model.Company
+		json.NewDecoder(w.Body).Decode(&c)
+		return c
+	}
+
+	c := create(fmt.Sprintf(`{"name": "Acme", "career_url": %q}`, careers.URL+"/careers"))
+	saved, _ := handler.companyRepo.GetByID(c.ID)
+	if saved.SourceType != "greenhouse" || saved.SourceID != "acme" {
+		t.Errorf("expected the Greenhouse board to be detected, got %+v", saved)
+	}
+
+	// A failed detection, or a source given in the request, leaves the
+	// company as sent.
+	c = create(fmt.Sprintf(`{"name": "Down", "career_url": %q}`, careers.URL+"/down"))
+	if c.SourceType != "" || c.SourceID != "" {
+		t.Errorf("expected no source for an unreachable page, got %+v", c)
+	}
+	c = create(fmt.Sprintf(`{"name": "Plain", "career_url": %q, "source_type": "html"}`, careers.URL+"/careers"))
+	if c.SourceType != "html" || c.SourceID != "" {
+		t.Errorf("expected the given source to be kept, got %+v", c)
+	}
+}
+
+func TestAPI_Companies_LocalMarkdownFile(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"name": "List", "career_url": "https://github.com/example/list", "source_type": "markdown", "source_id": "/etc/passwd"}`
+	if w := send("POST", "/api/companies", body); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a local file, got %d", w.Code)
+	}
+
+	// Lists set up in the config file can still be edited.
+	list := &model.Company{Name: "List", CareerURL: "https://github.com/example/list", SearchTerm: "intern", Enabled: true,
+		SourceType: "markdown", SourceID: "/data/README.md"}
+	handler.companyRepo.Create(list)
+	body = `{"name": "List", "career_url": "https://github.com/example/list", "enabled": false, "source_type": "markdown", "source_id": "/data/README.md"}`
+	if w := send("PUT", fmt.Sprintf("/api/companies/%d", list.ID), body); w.Code != http.StatusOK {
+		t.Errorf("expected status 200 keeping the file, got %d: %s", w.Code, w.Body.String())
+	}
+	body = strings.Replace(body, "/data/README.md", "/etc/passwd", 1)
+	if w := send("PUT", fmt.Sprintf("/api/companies/%d", list.ID), body); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 changing the file, got %d", w.Code)
+	}
+}
+
 func TestAPI_GetMetrics(t *testing.T) {
 	handler, cleanup := setupTestAPI(t)
 	defer cleanup()
@@ -117,6 +267,22 @@ func TestAPI_GetMetrics(t *testing.T) {
 	}
 }
 
+func TestAPI_PrometheusMetrics(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("expected text exposition format, got %q", w.Header().Get("Content-Type"))
+	}
+}
+
 func TestAPI_GetLogs(t *testing.T) {
 	handler, cleanup := setupTestAPI(t)
 	defer cleanup()
@@ -130,3 +296,694 @@ func TestAPI_GetLogs(t *testing.T) {
 		t.Errorf("expected status 200, got %d", w.Code)
 	}
 }
+
+func TestAPI_ListJobs_Filters(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	handler.jobRepo.Create(&model.Job{
+		Company: "Google", Title: "SWE Intern, Summer 2027", URL: "https://google.com/1",
+		Classification: model.Classification{Season: "summer", Year: 2027, RoleFamily: "SWE", EmploymentType: "internship"},
+	})
+	handler.jobRepo.Create(&model.Job{
+		Company: "Google", Title: "ML Intern, Fall 2026 (PhD)", URL: "https://google.com/2",
+		Classification: model.Classification{Season: "fall", Year: 2026, DegreeLevel: "PhD", RoleFamily: "ML", EmploymentType: "internship"},
+	})
+
+	req := httptest.NewRequest("GET", "/api/jobs?season=summer&year=2027&role_family=SWE", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var jobs []*model.Job
+	json.NewDecoder(w.Body).Decode(&jobs)
+	if len(jobs) != 1 || jobs[0].URL != "https://google.com/1" {
+		t.Errorf("expected only the summer SWE job, got %+v", jobs)
+	}
+
+	req = httptest.NewRequest("GET", "/api/jobs?year=soon", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid year, got %d", w.Code)
+	}
+}
+
+func TestAPI_GetStats_Facets(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	handler.jobRepo.Create(&model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Classification: model.Classification{RoleFamily: "SWE", Year: 2027},
+	})
+
+	req := httptest.NewRequest("GET", "/api/stats", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var stats struct {
+		Facets map[string]map[string]int `json:"facets"`
+	}
+	json.NewDecoder(w.Body).Decode(&stats)
+
+	if stats.Facets["role_family"]["SWE"] != 1 {
+		t.Errorf("expected SWE facet count 1, got %+v", stats.Facets)
+	}
+	if stats.Facets["year"]["2027"] != 1 {
+		t.Errorf("expected 2027 facet count 1, got %+v", stats.Facets)
+	}
+}
+
+func TestAPI_ListJobs_LocationFilters(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	handler.jobRepo.Create(&model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Locations: []model.Location{{Raw: "Mountain View, CA", City: "Mountain View", Region: "CA", Country: "US"}},
+	})
+	handler.jobRepo.Create(&model.Job{
+		Company: "Shopify", Title: "Dev Intern", URL: "https://shopify.com/1",
+		Locations: []model.Location{{Raw: "Remote - Canada", Country: "CA", Remote: true}},
+	})
+
+	tests := map[string]string{
+		"/api/jobs?state=ca":    "https://google.com/1",
+		"/api/jobs?country=CA":  "https://shopify.com/1",
+		"/api/jobs?remote=true": "https://shopify.com/1",
+	}
+	for path, wantURL := range tests {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+
+		var jobs []*model.Job
+		json.NewDecoder(w.Body).Decode(&jobs)
+		if len(jobs) != 1 || jobs[0].URL != wantURL {
+			t.Errorf("%s: expected only %s, got %+v", path, wantURL, jobs)
+			continue
+		}
+		if len(jobs[0].Locations) != 1 {
+			t.Errorf("%s: expected locations to be included, got %+v", path, jobs[0].Locations)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/stats", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var stats struct {
+		ByLocation map[string]map[string]int `json:"by_location"`
+	}
+	json.NewDecoder(w.Body).Decode(&stats)
+	if stats.ByLocation["region"]["US-CA"] != 1 || stats.ByLocation["country"]["CA"] != 1 || stats.ByLocation["remote"]["remote"] != 1 {
+		t.Errorf("unexpected location breakdown: %+v", stats.ByLocation)
+	}
+}
+
+func TestAPI_Compensation(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	handler.jobRepo.Create(&model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Compensation: &model.Compensation{Currency: "USD", Min: 50, Max: 60, Unit: "hour", HourlyMin: 50, HourlyMax: 60},
+	})
+	handler.jobRepo.Create(&model.Job{
+		Company: "Uber", Title: "SWE Intern", URL: "https://uber.com/1",
+		Compensation: &model.Compensation{Currency: "USD", Min: 30, Max: 35, Unit: "hour", HourlyMin: 30, HourlyMax: 35},
+	})
+	handler.jobRepo.Create(&model.Job{Company: "Amazon", Title: "SDE Intern", URL: "https://amazon.com/1"})
+
+	req := httptest.NewRequest("GET", "/api/jobs?min_hourly=40", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var jobs []*model.Job
+	json.NewDecoder(w.Body).Decode(&jobs)
+	if len(jobs) != 1 || jobs[0].Company != "Google" || jobs[0].Compensation == nil {
+		t.Errorf("expected only the Google job with compensation, got %+v", jobs)
+	}
+
+	req = httptest.NewRequest("GET", "/api/jobs?max_hourly=-1", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for negative max_hourly, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/stats", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var stats struct {
+		Compensation []repository.CompanyCompensation `json:"compensation_by_company"`
+	}
+	json.NewDecoder(w.Body).Decode(&stats)
+	if len(stats.Compensation) != 2 || stats.Compensation[0].Company != "Google" || stats.Compensation[0].AvgHourly != 55 {
+		t.Errorf("unexpected compensation summary: %+v", stats.Compensation)
+	}
+}
+
+func TestAPI_ListJobs_CollapsesDuplicates(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	original := &model.Job{Company: "Google", Title: "SWE Intern", URL: "https://google.com/jobs/12345"}
+	handler.jobRepo.Create(original)
+	handler.jobRepo.Create(&model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://boards.greenhouse.io/google/jobs/12345",
+		DuplicateOf: original.ID,
+	})
+
+	for path, want := range map[string]int{"/api/jobs": 1, "/api/jobs?duplicates=true": 2} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+
+		var jobs []*model.Job
+		json.NewDecoder(w.Body).Decode(&jobs)
+		if len(jobs) != want {
+			t.Errorf("%s: expected %d jobs, got %d", path, want, len(jobs))
+		}
+		if path == "/api/jobs" && len(jobs) == 1 && jobs[0].Duplicates != 1 {
+			t.Errorf("expected duplicate count 1, got %d", jobs[0].Duplicates)
+		}
+	}
+}
+
+func TestAPI_JobHistory(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	job := &model.Job{Company: "Google", Title: "SWE Intern", URL: "https://google.com/1"}
+	handler.jobRepo.Create(job)
+
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/api/jobs/%d/watch", job.ID), strings.NewReader(`{"watched": true}`))
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if found, _ := handler.jobRepo.GetByID(job.ID); !found.Watched {
+		t.Error("expected job to be watched")
+	}
+
+	job.Title = "SWE Intern, Summer 2027"
+	handler.jobRepo.SaveRevision(job, []model.FieldChange{{Field: "title", Old: "SWE Intern", New: job.Title}})
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/api/jobs/%d/history", job.ID), nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var revisions []*model.JobRevision
+	json.NewDecoder(w.Body).Decode(&revisions)
+	if len(revisions) != 1 || revisions[0].Changes[0].New != "SWE Intern, Summer 2027" {
+		t.Errorf("expected the title change, got %+v", revisions)
+	}
+
+	req = httptest.NewRequest("GET", "/api/jobs/999/history", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestAPI_JobSnapshot(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	a, err := archive.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	handler.SetArchive(a)
+
+	job := &model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Detail: &model.JobDetail{Description: "Build things.", Page: []byte("<html><body>Build things.</body></html>")},
+	}
+	a.Enrich(job)
+	handler.jobRepo.Create(job)
+
+	tests := map[string]string{
+		"":             "<body>Build things.</body>",
+		"?format=text": "Build things.",
+	}
+	for query, want := range tests {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/jobs/%d/snapshot%s", job.ID, query), nil)
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%q: expected %q, got %d %q", query, want, w.Code, w.Body.String())
+		}
+		if w.Header().Get("Content-Security-Policy") != "sandbox" {
+			t.Errorf("%q: expected sandboxed response", query)
+		}
+	}
+
+	other := &model.Job{Company: "Google", Title: "Data Intern", URL: "https://google.com/2"}
+	handler.jobRepo.Create(other)
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/jobs/%d/snapshot", other.ID), nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a job without snapshot, got %d", w.Code)
+	}
+}
+
+func TestAPI_CompanyHealth(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	company := &model.Company{Name: "Acme", CareerURL: "https://acme.com/careers", SearchTerm: "intern", Enabled: true}
+	handler.companyRepo.Create(company)
+
+	for _, jobs := range []int{5, 0} {
+		run := &model.RunLog{Status: "success"}
+		handler.runLogRepo.Create(run)
+		handler.runLogRepo.CreateCompanyRuns(run.ID, []*model.CompanyRun{
+			{CompanyID: company.ID, Company: company.Name, Status: "success", HTTPStatus: 200, JobsFound: jobs},
+		})
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/companies/%d/health", company.ID), nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var health model.CompanyHealth
+	json.NewDecoder(w.Body).Decode(&health)
+	if health.Runs != 2 || health.SuccessRate != 1 || len(health.Trend) != 2 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	if len(health.Flags) != 1 || health.Flags[0] != "empty" {
+		t.Errorf("expected company flagged empty, got %v", health.Flags)
+	}
+
+	req = httptest.NewRequest("GET", "/api/companies/health", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	var all []*model.CompanyHealth
+	json.NewDecoder(w.Body).Decode(&all)
+	found := false
+	for _, h := range all {
+		if h.CompanyID == company.ID {
+			found = len(h.Flags) == 1
+		}
+	}
+	if !found {
+		t.Errorf("expected flagged company in health list, got %d entries", len(all))
+	}
+
+	req = httptest.NewRequest("GET", "/api/companies/999/health", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestAPI_Incidents(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	resolved := &model.Incident{CompanyID: 1, Company: "Google", Reason: "fingerprint", Detail: "career page layout changed"}
+	handler.incidents.Open(resolved)
+	handler.incidents.Resolve(resolved.ID)
+	handler.incidents.Open(&model.Incident{CompanyID: 2, Company: "Uber", Reason: "empty", Detail: "found no jobs, usually 8"})
+
+	get := func(url string) []*model.Incident {
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		var incidents []*model.Incident
+		json.NewDecoder(w.Body).Decode(&incidents)
+		return incidents
+	}
+
+	if all := get("/api/incidents"); len(all) != 2 {
+		t.Errorf("expected 2 incidents, got %d", len(all))
+	}
+	open := get("/api/incidents?open=true")
+	if len(open) != 1 || open[0].Company != "Uber" || open[0].ResolvedAt != nil {
+		t.Errorf("expected only the open Uber incident, got %+v", open)
+	}
+}
+
+// fakeScheduler keeps a single active run.
+type fakeScheduler struct {
+	active   *model.Run
+	runs     map[int64]*model.Run
+	synced   int
+	paused   bool
+	schedule string
+}
+
+func (f *fakeScheduler) Enqueue() (*model.Run, bool) {
+	if f.active != nil {
+		return f.active, false
+	}
+	f.active = &model.Run{ID: int64(len(f.runs) + 1), State: model.RunQueued}
+	f.runs[f.active.ID] = f.active
+	return f.active, true
+}
+
+func (f *fakeScheduler) GetRun(id int64) (*model.Run, bool) {
+	run, ok := f.runs[id]
+	return run, ok
+}
+
+func (f *fakeScheduler) Status() model.SchedulerStatus {
+	if f.paused {
+		return model.SchedulerStatus{State: model.SchedulerPaused, Schedule: f.schedule, Timezone: "UTC", ActiveRun: f.active}
+	}
+	next := time.Date(2026, 8, 3, 9, 0, 0, 0, time.UTC)
+	return model.SchedulerStatus{State: model.SchedulerRunning, Schedule: f.schedule, Timezone: "UTC", NextRun: &next, ActiveRun: f.active}
+}
+
+func (f *fakeScheduler) Pause() error {
+	f.paused = true
+	return nil
+}
+
+func (f *fakeScheduler) Resume() error {
+	f.paused = false
+	return nil
+}
+
+func (f *fakeScheduler) SyncCompanySchedules() error {
+	f.synced++
+	return nil
+}
+
+func (f *fakeScheduler) CancelRun(id int64) bool {
+	if f.active == nil || f.active.ID != id {
+		return false
+	}
+	f.active.State = model.RunCancelled
+	f.active = nil
+	return true
+}
+
+func TestAPI_RefreshRuns(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+	handler.scheduler = &fakeScheduler{runs: make(map[int64]*model.Run)}
+
+	refresh := func() (int, map[string]interface{}, string) {
+		req := httptest.NewRequest("POST", "/api/refresh", nil)
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		var body map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&body)
+		return w.Code, body, w.Header().Get("Location")
+	}
+
+	code, body, location := refresh()
+	if code != http.StatusAccepted || body["run_id"] != float64(1) || body["coalesced"] != false || location != "/api/runs/1" {
+		t.Errorf("unexpected response %d %v %q", code, body, location)
+	}
+	if _, body, _ := refresh(); body["run_id"] != float64(1) || body["coalesced"] != true {
+		t.Errorf("expected duplicate request to be coalesced, got %v", body)
+	}
+
+	req := httptest.NewRequest("GET", "/api/runs/1", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	var run model.Run
+	json.NewDecoder(w.Body).Decode(&run)
+	if run.ID != 1 || run.State != model.RunQueued {
+		t.Errorf("unexpected run %+v", run)
+	}
+
+	for _, tc := range []struct {
+		method, url string
+		code        int
+	}{
+		{"DELETE", "/api/runs/1", http.StatusAccepted},
+		{"DELETE", "/api/runs/1", http.StatusConflict},
+		{"GET", "/api/runs/2", http.StatusNotFound},
+		{"GET", "/api/runs/abc", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, tc.url, nil)
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.url, tc.code, w.Code)
+		}
+	}
+}
+
+func TestAPI_EventStream(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+	bus := events.NewBus()
+	handler.SetEventBus(bus)
+
+	server := httptest.NewServer(handler.Router())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("expected connected comment, got %q", line)
+	}
+	reader.ReadString('\n')
+
+	bus.Publish(events.Event{Type: events.CompanyStarted, RunID: 4, Data: map[string]string{"company": "Google"}})
+
+	eventLine, _ := reader.ReadString('\n')
+	dataLine, _ := reader.ReadString('\n')
+	if eventLine != "event: company_started\n" {
+		t.Errorf("unexpected event line %q", eventLine)
+	}
+	var e events.Event
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(dataLine, "data: ")), &e); err != nil {
+		t.Fatalf("invalid data line %q: %v", dataLine, err)
+	}
+	if e.RunID != 4 || e.Data.(map[string]interface{})["company"] != "Google" {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestAPI_Scheduler(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	req := httptest.NewRequest("GET", "/api/scheduler", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 without a scheduler, got %d", w.Code)
+	}
+
+	sched := &fakeScheduler{runs: make(map[int64]*model.Run), schedule: "0 9 * * *"}
+	handler.scheduler = sched
+	handler.settings.OnChange(func(cfg settings.Settings) { sched.schedule = cfg.Schedule })
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		var status map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&status)
+		return w.Code, status
+	}
+
+	code, status := send("GET", "/api/scheduler", "")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if status["state"] != "running" || status["next_run"] != "2026-08-03T09:00:00Z" {
+		t.Errorf("unexpected status %v", status)
+	}
+	if _, ok := status["previous_run"]; ok {
+		t.Errorf("expected no previous run, got %v", status["previous_run"])
+	}
+
+	sched.Enqueue()
+	code, status = send("POST", "/api/scheduler/pause", "")
+	if code != http.StatusOK || status["state"] != "paused" || status["next_run"] != nil {
+		t.Errorf("expected a paused scheduler, got %d %v", code, status)
+	}
+	if run, ok := status["active_run"].(map[string]interface{}); !ok || run["id"] != float64(1) {
+		t.Errorf("expected the active run, got %v", status["active_run"])
+	}
+	code, status = send("POST", "/api/scheduler/resume", "")
+	if code != http.StatusOK || status["state"] != "running" {
+		t.Errorf("expected a running scheduler, got %d %v", code, status)
+	}
+
+	if code, _ := send("PUT", "/api/scheduler", `{"schedule": "every day"}`); code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid schedule, got %d", code)
+	}
+	code, status = send("PUT", "/api/scheduler", `{"schedule": "*/30 * * * *", "timezone": "UTC"}`)
+	if code != http.StatusOK || status["schedule"] != "*/30 * * * *" {
+		t.Errorf("expected the new schedule, got %d %v", code, status)
+	}
+	if cfg := handler.settings.Get(); cfg.Schedule != "*/30 * * * *" || cfg.Timezone != "UTC" {
+		t.Errorf("expected the schedule to be saved as a setting, got %+v", cfg)
+	}
+}
+
+func TestAPI_Settings(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	var applied settings.Settings
+	handler.settings.OnChange(func(s settings.Settings) { applied = s })
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/settings", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	w := put(`{"recipient": "+1234567890", "schedule": "*/15 * * * *", "timezone": "America/Los_Angeles"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if applied.Schedule != "*/15 * * * *" || applied.Recipient != "+1234567890" {
+		t.Errorf("expected the change to be applied, got %+v", applied)
+	}
+
+	if w := put(`{"scrape_concurrency": 99}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "scrape_concurrency") {
+		t.Errorf("expected a validation error, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := put(`not json`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a bad body, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/api/settings", nil)
+	w = httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+	var got settings.Settings
+	json.NewDecoder(w.Body).Decode(&got)
+	if got.Timezone != "America/Los_Angeles" || got.ScrapeConcurrency != 1 || got.NotificationMode != settings.NotifyAll {
+		t.Errorf("unexpected settings %+v", got)
+	}
+}
+
+func TestAPI_RequestLogging(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	logger, output, flags := slog.Default(), log.Writer(), log.Flags()
+	defer func() {
+		slog.SetDefault(logger)
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	req := httptest.NewRequest("GET", "/api/jobs/999", nil)
+	w := httptest.NewRecorder()
+	handler.Router().ServeHTTP(w, req)
+
+	reqID := w.Header().Get("X-Request-Id")
+	if reqID == "" {
+		t.Fatal("expected an X-Request-Id header")
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q", buf.String())
+	}
+	if record["msg"] != "request" || record["request_id"] != reqID || record["method"] != "GET" ||
+		record["path"] != "/api/jobs/999" || record["status"] != float64(w.Code) {
+		t.Errorf("unexpected record %v", record)
+	}
+}
+
+func TestAPI_PreviewCompany(t *testing.T) {
+	handler, cleanup := setupTestAPI(t)
+	defer cleanup()
+
+	careers := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<html><body>
+			<a href="/about">About us</a>
+			<ul>
+				<li class="opening"><a href="/jobs/1"><h3>Software Intern</h3></a><span class="city">Austin</span></li>
+				<li class="opening"><a href="/jobs/2"><h3>Data Intern</h3></a><span class="city">Remote</span></li>
+			</ul>
+			<script src="https://boards.greenhouse.io/embed/job_board/js?for=acme"></script>
+		</body></html>`))
+	}))
+	defer careers.Close()
+	handler.jobRepo.Create(&model.Job{Company: "Acme", Title: "Software Intern", URL: careers.URL + "/jobs/1"})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/companies/preview", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(fmt.Sprintf(`{"career_url": %q, "selectors": "job=li.opening,title=h3,location=.city"}`, careers.URL))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var preview companyPreview
+	json.NewDecoder(w.Body).Decode(&preview)
+	if len(preview.Jobs) != 2 || preview.Jobs[0].Title != "Software Intern" || preview.Jobs[0].Location != "Austin" {
+		t.Fatalf("unexpected jobs %+v", preview.Jobs)
+	}
+	if !preview.Jobs[0].Known || preview.Jobs[1].Known {
+		t.Errorf("expected only the saved job to be known, got %+v", preview.Jobs)
+	}
+	if preview.StatusCode != 200 || preview.Platform != "greenhouse" || preview.Error != "" {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	// Without selectors, the page's other links are reported as rejected.
+	w = send(fmt.Sprintf(`{"career_url": %q}`, careers.URL))
+	preview = companyPreview{}
+	json.NewDecoder(w.Body).Decode(&preview)
+	found := false
+	for _, r := range preview.Rejected {
+		if r.Text == "About us" && r.Reason == "search term not in text" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the About link to be rejected, got %+v", preview.Rejected)
+	}
+
+	w = send(fmt.Sprintf(`{"career_url": %q}`, careers.URL+"/missing"))
+	preview = companyPreview{}
+	json.NewDecoder(w.Body).Decode(&preview)
+	if w.Code != http.StatusOK || preview.StatusCode != 404 || preview.ErrorClass != "http_status" || preview.Error == "" {
+		t.Errorf("expected a failed scrape to be reported, got %d %+v", w.Code, preview)
+	}
+
+	for _, body := range []string{
+		`{"career_url": "careers.example.com"}`,
+		`{"career_url": "https://example.com", "selectors": "title=h3"}`,
+		`{"career_url": "https://example.com", "source_type": "rss"}`,
+		`{"career_url": "https://example.com", "source_type": "markdown", "source_id": "/etc/passwd"}`,
+	} {
+		if w := send(body); w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+
+	companies, _ := handler.companyRepo.GetAll()
+	if len(companies) != 0 {
+		t.Errorf("expected a preview to save nothing, got %d companies", len(companies))
+	}
+}
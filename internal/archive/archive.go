@@ -0,0 +1,270 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"intern-job-tracker/internal/logging"
+	"intern-job-tracker/internal/model"
+)
+
+// ErrNotFound is returned by Get when no blob has the requested hash.
+var ErrNotFound = errors.New("blob not found")
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Archive is a content-addressed store of gzip-compressed blobs on disk.
+// Blobs are named by the SHA-256 of their uncompressed content, so identical
+// pages are stored once.
+type Archive struct {
+	dir string
+}
+
+// Open creates the archive directory if needed and returns the archive.
+func Open(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// Put stores data and returns its hash and compressed size on disk.
+func (a *Archive) Put(data []byte) (string, int64, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path := a.path(hash)
+
+	if info, err := os.Stat(path); err == nil {
+		return hash, info.Size(), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated blob
+	// under its final name.
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".tmp*")
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	return hash, int64(buf.Len()), nil
+}
+
+// Get returns the uncompressed content of a blob.
+func (a *Archive) Get(hash string) ([]byte, error) {
+	if !hashPattern.MatchString(hash) {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(a.path(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// Delete removes a blob. Deleting a missing blob is not an error.
+func (a *Archive) Delete(hash string) error {
+	if !hashPattern.MatchString(hash) {
+		return nil
+	}
+	err := os.Remove(a.path(hash))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// size returns the compressed size of a blob, or 0 if it does not exist.
+func (a *Archive) size(hash string) int64 {
+	if !hashPattern.MatchString(hash) {
+		return 0
+	}
+	info, err := os.Stat(a.path(hash))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// path spreads blobs over subdirectories named by the first two hex digits
+// of their hash.
+func (a *Archive) path(hash string) string {
+	return filepath.Join(a.dir, hash[:2], hash+".gz")
+}
+
+// Snapshot archives a job's detail page HTML and the text extracted from it.
+func (a *Archive) Snapshot(job *model.Job) (*model.Snapshot, error) {
+	if job.Detail == nil || len(job.Detail.Page) == 0 {
+		return nil, errors.New("no detail page to archive")
+	}
+	htmlHash, htmlSize, err := a.Put(job.Detail.Page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive page: %w", err)
+	}
+	textHash, textSize, err := a.Put([]byte(Text(job)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive text: %w", err)
+	}
+	return &model.Snapshot{
+		HTMLHash: htmlHash,
+		TextHash: textHash,
+		Size:     htmlSize + textSize,
+		TakenAt:  time.Now(),
+	}, nil
+}
+
+// Enrich archives the detail page of a new job, if it was fetched. Jobs that
+// already have a snapshot keep it. It implements scheduler.Enricher and must
+// run after the detail fetcher.
+func (a *Archive) Enrich(job *model.Job) {
+	a.EnrichContext(context.Background(), job)
+}
+
+// EnrichContext is Enrich logging to the logger carried by ctx.
+func (a *Archive) EnrichContext(ctx context.Context, job *model.Job) {
+	if job.Snapshot != nil || job.Detail == nil || len(job.Detail.Page) == 0 {
+		return
+	}
+	snapshot, err := a.Snapshot(job)
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not archive job", "url", job.URL, "error", err)
+		return
+	}
+	job.Snapshot = snapshot
+}
+
+// Text renders the information extracted from a job's detail page as plain
+// text for reading offline.
+func Text(job *model.Job) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", job.Title, job.Company)
+	if job.Location != "" {
+		fmt.Fprintf(&b, "%s\n", job.Location)
+	}
+	fmt.Fprintf(&b, "%s\n", job.URL)
+
+	d := job.Detail
+	if d == nil {
+		return b.String()
+	}
+	if d.PostedAt != nil {
+		fmt.Fprintf(&b, "Posted: %s\n", d.PostedAt.Format("2006-01-02"))
+	}
+	if d.Deadline != nil {
+		fmt.Fprintf(&b, "Deadline: %s\n", d.Deadline.Format("2006-01-02"))
+	}
+	if d.Salary != "" {
+		fmt.Fprintf(&b, "Salary: %s\n", d.Salary)
+	}
+	if d.Description != "" {
+		fmt.Fprintf(&b, "\nDescription\n\n%s\n", d.Description)
+	}
+	if d.Requirements != "" {
+		fmt.Fprintf(&b, "\nRequirements\n\n%s\n", d.Requirements)
+	}
+	return b.String()
+}
+
+// Retention limits how much the archive keeps. Zero fields disable a limit.
+type Retention struct {
+	MaxAge   time.Duration // snapshots older than this are removed
+	MaxBytes int64         // the oldest snapshots are removed until the archive fits
+}
+
+// Store is the job storage needed to prune snapshots.
+type Store interface {
+	// GetWithSnapshots returns jobs with a snapshot, oldest snapshot first.
+	GetWithSnapshots() ([]*model.Job, error)
+	ClearSnapshot(id int64) error
+}
+
+// Prune removes snapshots outside the retention limits, oldest first, and
+// deletes blobs no longer referenced by any job. Returns the number of
+// snapshots removed.
+func (a *Archive) Prune(store Store, r Retention) (int, error) {
+	if r.MaxAge <= 0 && r.MaxBytes <= 0 {
+		return 0, nil
+	}
+	jobs, err := store.GetWithSnapshots()
+	if err != nil {
+		return 0, err
+	}
+
+	refs := make(map[string]int)
+	for _, job := range jobs {
+		refs[job.Snapshot.HTMLHash]++
+		refs[job.Snapshot.TextHash]++
+	}
+	var total int64
+	for hash := range refs {
+		total += a.size(hash)
+	}
+
+	removed := 0
+	for _, job := range jobs {
+		expired := r.MaxAge > 0 && time.Since(job.Snapshot.TakenAt) > r.MaxAge
+		oversize := r.MaxBytes > 0 && total > r.MaxBytes
+		if !expired && !oversize {
+			break
+		}
+		if err := store.ClearSnapshot(job.ID); err != nil {
+			return removed, err
+		}
+		removed++
+		for _, hash := range []string{job.Snapshot.HTMLHash, job.Snapshot.TextHash} {
+			refs[hash]--
+			if refs[hash] > 0 {
+				continue
+			}
+			size := a.size(hash)
+			if err := a.Delete(hash); err != nil {
+				return removed, err
+			}
+			total -= size
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,128 @@
+package archive
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestArchive_PutGet(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+
+	page := []byte(strings.Repeat("<p>Build things with us.</p>", 100))
+	hash, size, err := a.Put(page)
+	if err != nil {
+		t.Fatalf("failed to put blob: %v", err)
+	}
+	if size <= 0 || size >= int64(len(page)) {
+		t.Errorf("expected compressed size below %d, got %d", len(page), size)
+	}
+
+	again, _, _ := a.Put(page)
+	if again != hash {
+		t.Errorf("expected identical content to share a hash, got %s and %s", hash, again)
+	}
+
+	got, err := a.Get(hash)
+	if err != nil {
+		t.Fatalf("failed to get blob: %v", err)
+	}
+	if string(got) != string(page) {
+		t.Error("expected blob to round-trip")
+	}
+
+	if _, err := a.Get("../../etc/passwd"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for an invalid hash, got %v", err)
+	}
+	if err := a.Delete(hash); err != nil {
+		t.Fatalf("failed to delete blob: %v", err)
+	}
+	if _, err := a.Get(hash); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestArchive_Enrich(t *testing.T) {
+	a, _ := Open(t.TempDir())
+	job := &model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Detail: &model.JobDetail{Description: "Build things.", Page: []byte("<html>Build things.</html>")},
+	}
+	a.Enrich(job)
+
+	if job.Snapshot == nil {
+		t.Fatal("expected a snapshot")
+	}
+	text, _ := a.Get(job.Snapshot.TextHash)
+	if !strings.Contains(string(text), "SWE Intern") || !strings.Contains(string(text), "Build things.") {
+		t.Errorf("unexpected snapshot text: %q", text)
+	}
+
+	unfetched := &model.Job{Title: "Data Intern"}
+	a.Enrich(unfetched)
+	if unfetched.Snapshot != nil {
+		t.Error("expected no snapshot without a fetched page")
+	}
+}
+
+type memoryStore struct {
+	jobs    []*model.Job
+	cleared []int64
+}
+
+func (m *memoryStore) GetWithSnapshots() ([]*model.Job, error) {
+	return m.jobs, nil
+}
+
+func (m *memoryStore) ClearSnapshot(id int64) error {
+	m.cleared = append(m.cleared, id)
+	return nil
+}
+
+func TestArchive_Prune(t *testing.T) {
+	dir := t.TempDir()
+	a, _ := Open(dir)
+
+	snapshot := func(id int64, page string, age time.Duration) *model.Job {
+		job := &model.Job{ID: id, Title: "Intern", Detail: &model.JobDetail{Page: []byte(page)}}
+		job.Snapshot, _ = a.Snapshot(job)
+		job.Snapshot.TakenAt = time.Now().Add(-age)
+		return job
+	}
+	old := snapshot(1, "<html>shared</html>", 48*time.Hour)
+	reposted := snapshot(2, "<html>shared</html>", 47*time.Hour)
+	recent := snapshot(3, "<html>recent</html>", time.Hour)
+	store := &memoryStore{jobs: []*model.Job{old, reposted, recent}}
+
+	n, err := a.Prune(store, Retention{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if n != 2 || len(store.cleared) != 2 {
+		t.Fatalf("expected the two old snapshots pruned, got %d (%v)", n, store.cleared)
+	}
+	if _, err := a.Get(old.Snapshot.HTMLHash); err != ErrNotFound {
+		t.Error("expected unreferenced blob to be deleted")
+	}
+	if _, err := a.Get(recent.Snapshot.HTMLHash); err != nil {
+		t.Errorf("expected recent blob kept, got %v", err)
+	}
+
+	// The jobs share a text blob, so removing the oldest snapshot frees only
+	// its page, which is enough to fit the limit.
+	store = &memoryStore{jobs: []*model.Job{recent, snapshot(4, "<html>newest</html>", 0)}}
+	if n, _ := a.Prune(store, Retention{MaxBytes: recent.Snapshot.Size + 10}); n != 1 || store.cleared[0] != 3 {
+		t.Errorf("expected the oldest snapshot pruned to fit, got %d (%v)", n, store.cleared)
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(dir, "*", "*.tmp*"))
+	if len(entries) != 0 {
+		t.Errorf("expected no temporary files left behind, got %v", entries)
+	}
+}
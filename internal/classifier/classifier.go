@@ -0,0 +1,173 @@
+package classifier
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"intern-job-tracker/internal/model"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Rule assigns Value when any of its patterns matches a job title. Patterns
+// are case-insensitive regular expressions matched on word boundaries.
+type Rule struct {
+	Value    string   `json:"value"`
+	Patterns []string `json:"patterns"`
+}
+
+// Rules holds the ordered rules for each classification dimension. Within a
+// dimension the first matching rule wins, so more specific rules go first.
+type Rules struct {
+	Season         []Rule `json:"season"`
+	DegreeLevel    []Rule `json:"degree_level"`
+	RoleFamily     []Rule `json:"role_family"`
+	EmploymentType []Rule `json:"employment_type"`
+}
+
+// DefaultRules returns the built-in rules.
+func DefaultRules() Rules {
+	var rules Rules
+	if err := json.Unmarshal(defaultRules, &rules); err != nil {
+		panic(fmt.Sprintf("classifier: invalid built-in rules: %v", err))
+	}
+	return rules
+}
+
+// LoadRules reads rules from a JSON file in the same format as the built-in
+// rules, so the tables can be extended without code changes.
+func LoadRules(path string) (Rules, error) {
+	var rules Rules
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("failed to parse rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+type compiledRule struct {
+	value   string
+	pattern *regexp.Regexp
+}
+
+// Classifier extracts structured information from job titles.
+type Classifier struct {
+	season         []compiledRule
+	degreeLevel    []compiledRule
+	roleFamily     []compiledRule
+	employmentType []compiledRule
+}
+
+// New creates a new Classifier from the given rules.
+func New(rules Rules) (*Classifier, error) {
+	c := &Classifier{}
+	var err error
+	if c.season, err = compile(rules.Season); err != nil {
+		return nil, err
+	}
+	if c.degreeLevel, err = compile(rules.DegreeLevel); err != nil {
+		return nil, err
+	}
+	if c.roleFamily, err = compile(rules.RoleFamily); err != nil {
+		return nil, err
+	}
+	if c.employmentType, err = compile(rules.EmploymentType); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewDefault creates a Classifier using the built-in rules.
+func NewDefault() *Classifier {
+	c, err := New(DefaultRules())
+	if err != nil {
+		panic(fmt.Sprintf("classifier: invalid built-in rules: %v", err))
+	}
+	return c
+}
+
+func compile(rules []Rule) ([]compiledRule, error) {
+	var compiled []compiledRule
+	for _, rule := range rules {
+		for _, p := range rule.Patterns {
+			re, err := regexp.Compile(`(?i)(^|[^\pL\pN])(?:` + p + `)($|[^\pL\pN])`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q for %q: %w", p, rule.Value, err)
+			}
+			compiled = append(compiled, compiledRule{value: rule.Value, pattern: re})
+		}
+	}
+	return compiled, nil
+}
+
+func match(rules []compiledRule, title string) string {
+	for _, r := range rules {
+		if r.pattern.MatchString(title) {
+			return r.value
+		}
+	}
+	return ""
+}
+
+var yearPattern = regexp.MustCompile(`(?:^|\D)(20\d{2})(?:\D|$)|(?:^|\s)'(\d{2})(?:\D|$)`)
+
+// Classify extracts the season, year, degree level, role family and
+// employment type from a job title.
+func (c *Classifier) Classify(title string) model.Classification {
+	return model.Classification{
+		Season:         match(c.season, title),
+		Year:           extractYear(title),
+		DegreeLevel:    match(c.degreeLevel, title),
+		RoleFamily:     match(c.roleFamily, title),
+		EmploymentType: match(c.employmentType, title),
+	}
+}
+
+// Enrich classifies the job's title and stores the result on it.
+func (c *Classifier) Enrich(job *model.Job) {
+	job.Classification = c.Classify(job.Title)
+}
+
+// extractYear finds a four-digit year such as "2027" or an abbreviated one
+// such as "'27".
+func extractYear(title string) int {
+	m := yearPattern.FindStringSubmatch(title)
+	if m == nil {
+		return 0
+	}
+	if m[1] != "" {
+		year, _ := strconv.Atoi(m[1])
+		return year
+	}
+	year, _ := strconv.Atoi(m[2])
+	return 2000 + year
+}
+
+// Store is the job storage needed to back-fill classifications.
+type Store interface {
+	GetUnclassified() ([]*model.Job, error)
+	UpdateClassification(id int64, c model.Classification) error
+}
+
+// Backfill classifies every stored job that has not been classified yet and
+// returns the number of jobs updated.
+func (c *Classifier) Backfill(store Store) (int, error) {
+	jobs, err := store.GetUnclassified()
+	if err != nil {
+		return 0, err
+	}
+	for i, job := range jobs {
+		if err := store.UpdateClassification(job.ID, c.Classify(job.Title)); err != nil {
+			return i, err
+		}
+	}
+	return len(jobs), nil
+}
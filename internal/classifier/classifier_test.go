@@ -0,0 +1,114 @@
+package classifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	c := NewDefault()
+
+	tests := []struct {
+		title string
+		want  model.Classification
+	}{
+		{
+			title: "Software Engineering Intern, Summer 2027 (PhD)",
+			want:  model.Classification{Season: "summer", Year: 2027, DegreeLevel: "PhD", RoleFamily: "SWE", EmploymentType: "internship"},
+		},
+		{
+			title: "Machine Learning Engineer Co-op - Fall '26",
+			want:  model.Classification{Season: "fall", Year: 2026, RoleFamily: "ML", EmploymentType: "co-op"},
+		},
+		{
+			title: "Data Science Internship (BS/MS)",
+			want:  model.Classification{DegreeLevel: "MS", RoleFamily: "data", EmploymentType: "internship"},
+		},
+		{
+			title: "Associate Product Manager Intern",
+			want:  model.Classification{RoleFamily: "PM", EmploymentType: "internship"},
+		},
+		{
+			title: "Hardware Engineering Intern - ASIC",
+			want:  model.Classification{RoleFamily: "hardware", EmploymentType: "internship"},
+		},
+		{
+			title: "International Sales Lead",
+			want:  model.Classification{},
+		},
+	}
+
+	for _, tt := range tests {
+		got := c.Classify(tt.title)
+		if got != tt.want {
+			t.Errorf("Classify(%q) = %+v, want %+v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rules := `{"role_family": [{"value": "security", "patterns": ["security", "appsec"]}]}`
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+
+	loaded, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	c, err := New(loaded)
+	if err != nil {
+		t.Fatalf("failed to compile rules: %v", err)
+	}
+
+	if got := c.Classify("AppSec Intern").RoleFamily; got != "security" {
+		t.Errorf("expected custom role family, got %q", got)
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New(Rules{Season: []Rule{{Value: "summer", Patterns: []string{"("}}}})
+	if err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+type mockStore struct {
+	jobs    []*model.Job
+	updated map[int64]model.Classification
+}
+
+func (m *mockStore) GetUnclassified() ([]*model.Job, error) {
+	return m.jobs, nil
+}
+
+func (m *mockStore) UpdateClassification(id int64, c model.Classification) error {
+	m.updated[id] = c
+	return nil
+}
+
+func TestClassifier_Backfill(t *testing.T) {
+	store := &mockStore{
+		jobs: []*model.Job{
+			{ID: 1, Title: "SWE Intern, Summer 2027"},
+			{ID: 2, Title: "Data Analyst Intern"},
+		},
+		updated: make(map[int64]model.Classification),
+	}
+
+	n, err := NewDefault().Backfill(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n != 2 || len(store.updated) != 2 {
+		t.Fatalf("expected 2 jobs back-filled, got %d", n)
+	}
+	if store.updated[1].Year != 2027 || store.updated[2].RoleFamily != "data" {
+		t.Errorf("unexpected classifications: %+v", store.updated)
+	}
+}
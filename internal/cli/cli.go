@@ -0,0 +1,284 @@
+// Package cli implements the tracker's subcommands, such as "companies add"
+// and "jobs export", for scripting the tracker from cron or a shell without
+// the HTTP API. Commands open the same database with the same repositories
+// as the server, so they can run while it does.
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"intern-job-tracker/internal/config"
+	"intern-job-tracker/internal/db"
+	"intern-job-tracker/internal/notifier"
+	"intern-job-tracker/internal/repository"
+	"intern-job-tracker/internal/scheduler"
+)
+
+// App runs commands. Its exported fields may be replaced before Run, as
+// tests do.
+type App struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Notifier sends the notifications of "notify test" and "scrape".
+	Notifier scheduler.Notifier
+	// Client fetches career pages.
+	Client *http.Client
+
+	config     *config.Config
+	db         *sql.DB
+	jobs       *repository.JobRepository
+	companies  *repository.CompanyRepository
+	runLogs    *repository.RunLogRepository
+	configRepo *repository.ConfigRepository
+}
+
+// New creates an App writing to the standard output and sending iMessages.
+func New() *App {
+	return &App{
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+		Notifier: notifier.NewDefaultIMessageNotifier(),
+	}
+}
+
+// command is a subcommand. flags registers the command's flags and returns
+// the function running it with the remaining arguments.
+type command struct {
+	args  string // arguments after the flags, for the usage message
+	help  string
+	flags func(fs *flag.FlagSet) func(a *App, args []string) error
+}
+
+// commands lists the subcommands by group and action. Groups with a single
+// command, such as scrape, use the empty action.
+var commands = map[string]map[string]*command{
+	"companies": {
+		"list":    companiesList,
+		"add":     companiesAdd,
+		"edit":    companiesEdit,
+		"enable":  companiesEnable,
+		"disable": companiesDisable,
+		"remove":  companiesRemove,
+	},
+	"jobs": {
+		"list":   jobsList,
+		"show":   jobsShow,
+		"export": jobsExport,
+	},
+	"runs": {
+		"list": runsList,
+	},
+	"scrape": {
+		"": scrapeCommand,
+	},
+	"notify": {
+		"test": notifyTest,
+	},
+	"db": {
+		"migrate": dbMigrate,
+		"backup":  dbBackup,
+		"vacuum":  dbVacuum,
+	},
+}
+
+// IsCommand reports whether name is a command group, so that the server can
+// tell subcommands from its own flags.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok || name == "help"
+}
+
+// errUsage reports a usage error whose message has already been printed.
+var errUsage = errors.New("usage")
+
+// Run runs the command in args, such as ["companies", "list"], and returns
+// the process exit code.
+func (a *App) Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" {
+		a.usage()
+		return 2
+	}
+	group, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(a.Stderr, "unknown command %q\n", args[0])
+		a.usage()
+		return 2
+	}
+
+	name, rest := args[0], args[1:]
+	cmd := group[""]
+	if cmd == nil {
+		if len(rest) == 0 || group[rest[0]] == nil {
+			if len(rest) > 0 {
+				fmt.Fprintf(a.Stderr, "unknown command %q\n", name+" "+rest[0])
+			}
+			a.groupUsage(name)
+			return 2
+		}
+		name, cmd, rest = name+" "+rest[0], group[rest[0]], rest[1:]
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	dbPath := fs.String("db", "", "Database file path (default from the config file, or jobs.db)")
+	configPath := fs.String("config", "", "YAML config file")
+	run := cmd.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(a.Stderr, "Usage: tracker %s [flags] %s\n\n%s\n\nFlags:\n", name, cmd.args, cmd.help)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterleaved(fs, rest)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if err := a.open(*configPath, *dbPath); err != nil {
+		fmt.Fprintf(a.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer a.db.Close()
+
+	if err := run(a, positional); err != nil {
+		if err == errUsage {
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(a.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterleaved parses flags given before or after the positional
+// arguments, as in "companies edit acme -schedule 6h", and returns the
+// positional arguments. Arguments after "--" are never parsed as flags.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// open loads the configuration and opens the database, running migrations
+// like the server does.
+func (a *App) open(configPath, dbPath string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%v", err)
+	}
+	a.config = cfg
+	if dbPath == "" {
+		dbPath = a.option("db", "jobs.db")
+	}
+
+	database, err := db.New(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %v", dbPath, err)
+	}
+	a.db = database
+	a.jobs = repository.NewJobRepository(database)
+	a.companies = repository.NewCompanyRepository(database)
+	a.runLogs = repository.NewRunLogRepository(database)
+	a.configRepo = repository.NewConfigRepository(database)
+	return nil
+}
+
+// option returns the value the config file or environment gives the server
+// flag name, or fallback.
+func (a *App) option(name, fallback string) string {
+	if value, ok := a.config.Flags[name]; ok {
+		return value
+	}
+	return fallback
+}
+
+func (a *App) usage() {
+	fmt.Fprintln(a.Stderr, "Usage: tracker <command> [flags] [args]")
+	fmt.Fprintln(a.Stderr, "\nCommands:")
+	w := tabwriter.NewWriter(a.Stderr, 0, 4, 2, ' ', 0)
+	for _, name := range sortedKeys(commands) {
+		for _, action := range sortedKeys(commands[name]) {
+			cmd := commands[name][action]
+			fmt.Fprintf(w, "  %s\t%s\n", strings.TrimSpace(name+" "+action+" "+cmd.args), cmd.help)
+		}
+	}
+	w.Flush()
+	fmt.Fprintln(a.Stderr, "\nRun \"tracker <command> -h\" for a command's flags. Without a command, the server starts.")
+}
+
+func (a *App) groupUsage(group string) {
+	fmt.Fprintf(a.Stderr, "Usage: tracker %s <%s> [flags] [args]\n", group, strings.Join(sortedKeys(commands[group]), "|"))
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]map[string]*command:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*command:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// table writes aligned columns.
+func (a *App) table(header ...string) *tabwriter.Writer {
+	w := tabwriter.NewWriter(a.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	return w
+}
+
+// printJSON writes v as indented JSON.
+func (a *App) printJSON(v interface{}) error {
+	enc := json.NewEncoder(a.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// parseID parses a numeric ID argument.
+func parseID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", arg)
+	}
+	return id, nil
+}
+
+// truncate shortens s to n characters for table output.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
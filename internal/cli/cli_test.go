@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+type fakeNotifier struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (n *fakeNotifier) NotifyJob(recipient string, job *model.Job) error {
+	return n.Send(recipient, job.Title)
+}
+
+func (n *fakeNotifier) Send(recipient, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, recipient+": "+message)
+	return nil
+}
+
+// testEnv runs commands against a temporary database.
+type testEnv struct {
+	t        *testing.T
+	db       string
+	notifier *fakeNotifier
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	return &testEnv{t: t, db: filepath.Join(t.TempDir(), "jobs.db"), notifier: &fakeNotifier{}}
+}
+
+// run runs a command and returns its exit code and output.
+func (e *testEnv) run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	app := &App{Stdout: &stdout, Stderr: &stderr, Notifier: e.notifier}
+	// Pass -db after the command name, like a user would.
+	n := 1
+	if args[0] != "scrape" {
+		n = 2
+	}
+	full := append(append(append([]string{}, args[:n]...), "-db", e.db), args[n:]...)
+	code := app.Run(full)
+	return code, stdout.String(), stderr.String()
+}
+
+// mustRun runs a command that is expected to succeed.
+func (e *testEnv) mustRun(args ...string) string {
+	e.t.Helper()
+	code, stdout, stderr := e.run(args...)
+	if code != 0 {
+		e.t.Fatalf("%v: exit code %d, stderr %q", args, code, stderr)
+	}
+	return stdout
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stderr bytes.Buffer
+	app := &App{Stdout: &bytes.Buffer{}, Stderr: &stderr}
+	if code := app.Run(nil); code != 2 {
+		t.Errorf("expected exit code 2 without a command, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "companies add") || !strings.Contains(stderr.String(), "db vacuum") {
+		t.Errorf("expected usage to list commands, got %q", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := app.Run([]string{"jobs", "delete"}); code != 2 {
+		t.Errorf("expected exit code 2 for an unknown action, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `unknown command "jobs delete"`) {
+		t.Errorf("unexpected output %q", stderr.String())
+	}
+
+	if !IsCommand("companies") || IsCommand("-port") || IsCommand("server") {
+		t.Error("IsCommand should only accept command groups")
+	}
+}
+
+func TestCompanies(t *testing.T) {
+	e := newTestEnv(t)
+
+	out := e.mustRun("companies", "add", "-name", "Acme", "-url", "https://acme.example/careers", "-schedule", "6h")
+	if out != "Added Acme (id 1)\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if code, _, stderr := e.run("companies", "add", "-name", "Bad", "-url", "https://bad.example", "-schedule", "often"); code != 1 || !strings.Contains(stderr, "schedule") {
+		t.Errorf("expected an invalid schedule to fail, got %d %q", code, stderr)
+	}
+	if code, _, _ := e.run("companies", "add", "-name", "NoURL"); code != 1 {
+		t.Error("expected a company without a URL to fail")
+	}
+
+	e.mustRun("companies", "edit", "ACME", "-active-until", "2026-12-31")
+	e.mustRun("companies", "disable", "1")
+
+	var companies []*model.Company
+	if err := json.Unmarshal([]byte(e.mustRun("companies", "list", "-json")), &companies); err != nil {
+		t.Fatalf("expected JSON: %v", err)
+	}
+	if len(companies) != 1 {
+		t.Fatalf("expected 1 company, got %d", len(companies))
+	}
+	c := companies[0]
+	if c.Name != "Acme" || c.Schedule != "6h" || c.ActiveUntil != "2026-12-31" || c.Enabled || c.SearchTerm != "intern" {
+		t.Errorf("unexpected company %+v", c)
+	}
+
+	if code, _, stderr := e.run("companies", "enable", "Globex"); code != 1 || !strings.Contains(stderr, `no company named "Globex"`) {
+		t.Errorf("expected an unknown company to fail, got %d %q", code, stderr)
+	}
+	e.mustRun("companies", "remove", "acme")
+	if out := e.mustRun("companies", "list"); strings.Contains(out, "Acme") {
+		t.Errorf("expected Acme to be removed, got %q", out)
+	}
+}
+
+func careerServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+			<a href="/jobs/1">Software Engineering Intern</a>
+			<a href="/jobs/2">Data Science Intern</a>
+			<a href="/jobs/3">Senior Engineer</a>
+		</body></html>`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestScrape(t *testing.T) {
+	t.Setenv("TRACKER_NOTIFICATIONS_RECIPIENT", "+15550001111")
+	server := careerServer(t)
+	e := newTestEnv(t)
+	e.mustRun("companies", "add", "-name", "Acme", "-url", server.URL)
+
+	out := e.mustRun("scrape", "-company", "acme", "-dry-run")
+	if !strings.Contains(out, "Acme: 2 jobs, 2 new") || !strings.Contains(out, "Nothing was saved") {
+		t.Errorf("unexpected dry run output %q", out)
+	}
+	if out := e.mustRun("jobs", "list"); strings.Contains(out, "Intern") {
+		t.Errorf("expected a dry run to save nothing, got %q", out)
+	}
+
+	if out := e.mustRun("scrape", "-company", "1"); out != "Acme: 2 jobs, 2 new\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if len(e.notifier.messages) != 2 || !strings.HasPrefix(e.notifier.messages[0], "+15550001111: ") {
+		t.Errorf("expected both new jobs to be notified, got %q", e.notifier.messages)
+	}
+	if out := e.mustRun("scrape", "-company", "1", "-dry-run"); !strings.Contains(out, "2 jobs, 0 new") {
+		t.Errorf("expected saved jobs not to be new, got %q", out)
+	}
+	if out := e.mustRun("runs", "list"); !strings.Contains(out, "success") {
+		t.Errorf("expected the run to be logged, got %q", out)
+	}
+
+	e.mustRun("companies", "disable", "acme")
+	if out := e.mustRun("scrape", "-company", "acme"); !strings.HasPrefix(out, "Skipped Acme") {
+		t.Errorf("expected a disabled company to be skipped, got %q", out)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	server := careerServer(t)
+	e := newTestEnv(t)
+	e.mustRun("companies", "add", "-name", "Acme", "-url", server.URL)
+	e.mustRun("scrape", "-company", "Acme")
+
+	var jobs []*model.Job
+	if err := json.Unmarshal([]byte(e.mustRun("jobs", "list", "-json", "-limit", "1")), &jobs); err != nil {
+		t.Fatalf("expected JSON: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected -limit to apply, got %d jobs", len(jobs))
+	}
+
+	out := e.mustRun("jobs", "show", "1")
+	if !strings.Contains(out, "at Acme (id 1)") || !strings.Contains(out, server.URL+"/jobs/") {
+		t.Errorf("unexpected output %q", out)
+	}
+	if code, _, _ := e.run("jobs", "show", "99"); code != 1 {
+		t.Error("expected an unknown job to fail")
+	}
+
+	path := filepath.Join(t.TempDir(), "jobs.csv")
+	e.mustRun("jobs", "export", "-o", path)
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("expected CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][2] != "title" || records[1][1] != "Acme" {
+		t.Errorf("unexpected export %q", records)
+	}
+
+	if code, _, _ := e.run("jobs", "export", "-format", "xml"); code != 1 {
+		t.Error("expected an unknown format to fail")
+	}
+}
+
+func TestNotifyTest(t *testing.T) {
+	e := newTestEnv(t)
+	if code, _, stderr := e.run("notify", "test"); code != 1 || !strings.Contains(stderr, "no recipient") {
+		t.Errorf("expected failure without a recipient, got %d %q", code, stderr)
+	}
+	e.mustRun("notify", "test", "-recipient", "me@example.com", "-message", "hello")
+	if len(e.notifier.messages) != 1 || e.notifier.messages[0] != "me@example.com: hello" {
+		t.Errorf("unexpected messages %q", e.notifier.messages)
+	}
+}
+
+func TestDB(t *testing.T) {
+	e := newTestEnv(t)
+	e.mustRun("db", "migrate")
+	e.mustRun("companies", "add", "-name", "Acme", "-url", "https://acme.example")
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	e.mustRun("db", "backup", backup)
+	if code, _, _ := e.run("db", "backup", backup); code != 1 {
+		t.Error("expected a backup not to overwrite an existing file")
+	}
+	e.mustRun("db", "vacuum")
+
+	restored := &testEnv{t: t, db: backup, notifier: e.notifier}
+	if out := restored.mustRun("companies", "list"); !strings.Contains(out, "Acme") {
+		t.Errorf("expected the backup to hold the companies, got %q", out)
+	}
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"intern-job-tracker/internal/model"
+	"intern-job-tracker/internal/scheduler"
+)
+
+// findCompany looks a company up by ID or, ignoring case, by name.
+func (a *App) findCompany(arg string) (*model.Company, error) {
+	if id, err := parseID(arg); err == nil {
+		company, err := a.companies.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if company == nil {
+			return nil, fmt.Errorf("no company with id %d", id)
+		}
+		return company, nil
+	}
+
+	companies, err := a.companies.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var found *model.Company
+	for _, c := range companies {
+		if !strings.EqualFold(c.Name, arg) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several companies are named %q, use an id", arg)
+		}
+		found = c
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no company named %q", arg)
+	}
+	return found, nil
+}
+
+// companyFlags are the flags setting a company's fields.
+type companyFlags struct {
+	name, url, searchTerm, schedule, activeFrom, activeUntil, sourceType, sourceID, selectors *string
+}
+
+func addCompanyFlags(fs *flag.FlagSet) companyFlags {
+	return companyFlags{
+		name:        fs.String("name", "", "Company name"),
+		url:         fs.String("url", "", "Career page URL"),
+		searchTerm:  fs.String("search-term", "intern", "Term job titles must contain"),
+		schedule:    fs.String("schedule", "", "Own check frequency: an interval such as 6h, or a cron expression (empty follows the global schedule)"),
+		activeFrom:  fs.String("active-from", "", "First day to check the company on (YYYY-MM-DD)"),
+		activeUntil: fs.String("active-until", "", "Last day to check the company on (YYYY-MM-DD)"),
+		sourceType:  fs.String("source-type", "", "How jobs are read: html (links on the career page), markdown (a curated list's tables) or a platform such as greenhouse"),
+		sourceID:    fs.String("source-id", "", "The company's board on its platform, e.g. a Greenhouse board token or Workday host/tenant/site, or a markdown list's local file"),
+		selectors:   fs.String("selectors", "", "Selectors narrowing the page to its job listings, e.g. \"job=li.opening,title=h3\""),
+	}
+}
+
+// apply copies the flags given on the command line to company.
+func (f companyFlags) apply(fs *flag.FlagSet, company *model.Company) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "name":
+			company.Name = *f.name
+		case "url":
+			company.CareerURL = *f.url
+		case "search-term":
+			company.SearchTerm = *f.searchTerm
+		case "schedule":
+			company.Schedule = *f.schedule
+		case "active-from":
+			company.ActiveFrom = *f.activeFrom
+		case "active-until":
+			company.ActiveUntil = *f.activeUntil
+		case "source-type":
+			company.SourceType = *f.sourceType
+		case "source-id":
+			company.SourceID = *f.sourceID
+		case "selectors":
+			company.Selectors = *f.selectors
+		}
+	})
+}
+
+// validateCompany applies the checks the API applies to companies.
+func validateCompany(company *model.Company) error {
+	if company.Name == "" || company.CareerURL == "" {
+		return errors.New("-name and -url are required")
+	}
+	if u, err := url.Parse(company.CareerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q, expected an http or https URL", company.CareerURL)
+	}
+	return scheduler.ValidateCompany(company)
+}
+
+var companiesList = &command{
+	help: "List tracked companies",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		asJSON := fs.Bool("json", false, "Print JSON")
+		return func(a *App, args []string) error {
+			companies, err := a.companies.GetAll()
+			if err != nil {
+				return err
+			}
+			if *asJSON {
+				if companies == nil {
+					companies = []*model.Company{}
+				}
+				return a.printJSON(companies)
+			}
+			w := a.table("ID", "NAME", "ENABLED", "SCHEDULE", "ACTIVE", "URL")
+			for _, c := range companies {
+				active := ""
+				if c.ActiveFrom != "" || c.ActiveUntil != "" {
+					active = c.ActiveFrom + ".." + c.ActiveUntil
+				}
+				fmt.Fprintf(w, "%d\t%s\t%t\t%s\t%s\t%s\n", c.ID, c.Name, c.Enabled, c.Schedule, active, c.CareerURL)
+			}
+			return w.Flush()
+		}
+	},
+}
+
+var companiesAdd = &command{
+	help: "Add a company to track",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		f := addCompanyFlags(fs)
+		disabled := fs.Bool("disabled", false, "Add the company disabled")
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			company := &model.Company{SearchTerm: "intern", Enabled: !*disabled}
+			f.apply(fs, company)
+			if err := validateCompany(company); err != nil {
+				return err
+			}
+			if err := a.companies.Create(company); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.Stdout, "Added %s (id %d)\n", company.Name, company.ID)
+			return nil
+		}
+	},
+}
+
+var companiesEdit = &command{
+	args: "<id|name>",
+	help: "Change a company's fields; only the flags given are changed",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		f := addCompanyFlags(fs)
+		return func(a *App, args []string) error {
+			if len(args) != 1 || fs.NFlag() == 0 {
+				return errUsage
+			}
+			company, err := a.findCompany(args[0])
+			if err != nil {
+				return err
+			}
+			f.apply(fs, company)
+			if err := validateCompany(company); err != nil {
+				return err
+			}
+			if err := a.companies.Update(company); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.Stdout, "Updated %s (id %d)\n", company.Name, company.ID)
+			return nil
+		}
+	},
+}
+
+// setEnabled returns a command enabling or disabling a company.
+func setEnabled(enabled bool, help string) *command {
+	return &command{
+		args: "<id|name>",
+		help: help,
+		flags: func(fs *flag.FlagSet) func(*App, []string) error {
+			return func(a *App, args []string) error {
+				if len(args) != 1 {
+					return errUsage
+				}
+				company, err := a.findCompany(args[0])
+				if err != nil {
+					return err
+				}
+				company.Enabled = enabled
+				if err := a.companies.Update(company); err != nil {
+					return err
+				}
+				state := "Disabled"
+				if enabled {
+					state = "Enabled"
+				}
+				fmt.Fprintf(a.Stdout, "%s %s (id %d)\n", state, company.Name, company.ID)
+				return nil
+			}
+		},
+	}
+}
+
+var (
+	companiesEnable  = setEnabled(true, "Resume checking a company")
+	companiesDisable = setEnabled(false, "Stop checking a company, keeping its jobs")
+)
+
+var companiesRemove = &command{
+	args: "<id|name>",
+	help: "Remove a company; its jobs are kept",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		return func(a *App, args []string) error {
+			if len(args) != 1 {
+				return errUsage
+			}
+			company, err := a.findCompany(args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.companies.Delete(company.ID); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.Stdout, "Removed %s (id %d)\n", company.Name, company.ID)
+			return nil
+		}
+	},
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"intern-job-tracker/internal/db"
+)
+
+var dbMigrate = &command{
+	help: "Bring the database schema up to date",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			// Opening the database already migrated it; migrating again is
+			// harmless and reports errors from this command.
+			if err := db.Migrate(a.db); err != nil {
+				return err
+			}
+			fmt.Fprintln(a.Stdout, "Database schema is up to date")
+			return nil
+		}
+	},
+}
+
+var dbBackup = &command{
+	args: "<path>",
+	help: "Write a consistent copy of the database to a new file, safe while the server runs",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		return func(a *App, args []string) error {
+			if len(args) != 1 {
+				return errUsage
+			}
+			if err := db.Backup(a.db, args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.Stdout, "Backed up database to %s\n", args[0])
+			return nil
+		}
+	},
+}
+
+var dbVacuum = &command{
+	help: "Rebuild the database file to reclaim unused space",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			if err := db.Vacuum(a.db); err != nil {
+				return err
+			}
+			fmt.Fprintln(a.Stdout, "Vacuumed database")
+			return nil
+		}
+	},
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"intern-job-tracker/internal/model"
+	"intern-job-tracker/internal/repository"
+)
+
+var jobsList = &command{
+	help: "List the newest jobs",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		company := fs.String("company", "", "Only jobs at this company")
+		limit := fs.Int("limit", 20, "Maximum number of jobs to list (0 for all)")
+		asJSON := fs.Bool("json", false, "Print JSON")
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			jobs, err := a.jobs.List(repository.JobFilter{Company: *company})
+			if err != nil {
+				return err
+			}
+			if *limit > 0 && len(jobs) > *limit {
+				jobs = jobs[:*limit]
+			}
+			if *asJSON {
+				if jobs == nil {
+					jobs = []*model.Job{}
+				}
+				return a.printJSON(jobs)
+			}
+			w := a.table("ID", "COMPANY", "TITLE", "LOCATION", "DISCOVERED")
+			for _, j := range jobs {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", j.ID, j.Company, truncate(j.Title, 60), truncate(j.Location, 30), j.DiscoveredAt.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		}
+	},
+}
+
+var jobsShow = &command{
+	args: "<id>",
+	help: "Show a job with its details and revisions",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		asJSON := fs.Bool("json", false, "Print JSON")
+		return func(a *App, args []string) error {
+			if len(args) != 1 {
+				return errUsage
+			}
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			job, err := a.jobs.GetByID(id)
+			if err != nil {
+				return err
+			}
+			if job == nil {
+				return fmt.Errorf("no job with id %d", id)
+			}
+			revisions, err := a.jobs.GetRevisions(id)
+			if err != nil {
+				return err
+			}
+			if *asJSON {
+				if revisions == nil {
+					revisions = []*model.JobRevision{}
+				}
+				return a.printJSON(struct {
+					*model.Job
+					Revisions []*model.JobRevision `json:"revisions"`
+				}{job, revisions})
+			}
+			a.printJob(job, revisions)
+			return nil
+		}
+	},
+}
+
+func (a *App) printJob(job *model.Job, revisions []*model.JobRevision) {
+	w := a.Stdout
+	fmt.Fprintf(w, "%s at %s (id %d)\n", job.Title, job.Company, job.ID)
+	fmt.Fprintf(w, "URL:         %s\n", job.URL)
+	if job.Location != "" {
+		fmt.Fprintf(w, "Location:    %s\n", job.Location)
+	}
+	if job.Source != "" {
+		fmt.Fprintf(w, "Source:      %s\n", job.Source)
+	}
+	fmt.Fprintf(w, "Discovered:  %s\n", job.DiscoveredAt.Local().Format(time.RFC1123))
+	fmt.Fprintf(w, "Score:       %.1f\n", job.Score)
+	if job.Season != "" || job.Year != 0 {
+		fmt.Fprintf(w, "Term:        %s %s\n", job.Season, yearString(job.Year))
+	}
+	if job.DuplicateOf != 0 {
+		fmt.Fprintf(w, "Duplicate of job %d\n", job.DuplicateOf)
+	}
+	if d := job.Detail; d != nil {
+		if d.Salary != "" {
+			fmt.Fprintf(w, "Salary:      %s\n", d.Salary)
+		}
+		if d.Deadline != nil {
+			fmt.Fprintf(w, "Deadline:    %s\n", d.Deadline.Format("2006-01-02"))
+		}
+		if d.Description != "" {
+			fmt.Fprintf(w, "\n%s\n", d.Excerpt(500))
+		}
+	}
+	if len(revisions) > 0 {
+		fmt.Fprintln(w, "\nRevisions:")
+		for _, rev := range revisions {
+			for _, c := range rev.Changes {
+				fmt.Fprintf(w, "  %s  %s: %q -> %q\n", rev.ChangedAt.Local().Format("2006-01-02 15:04"), c.Field, c.Old, c.New)
+			}
+		}
+	}
+}
+
+func yearString(year int) string {
+	if year == 0 {
+		return ""
+	}
+	return strconv.Itoa(year)
+}
+
+// exportColumns are the columns of a CSV export.
+var exportColumns = []string{"id", "company", "title", "url", "location", "discovered_at", "score", "season", "year", "role_family", "duplicate_of"}
+
+var jobsExport = &command{
+	help: "Export jobs as CSV or JSON",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		format := fs.String("format", "csv", "Export format: csv or json")
+		output := fs.String("o", "", "File to write to (default standard output)")
+		company := fs.String("company", "", "Only jobs at this company")
+		all := fs.Bool("all", false, "Include jobs linked as duplicates of another job")
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			if *format != "csv" && *format != "json" {
+				return fmt.Errorf("unknown format %q, expected csv or json", *format)
+			}
+			jobs, err := a.jobs.List(repository.JobFilter{Company: *company, IncludeDuplicates: *all})
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = a.Stdout
+			if *output != "" {
+				f, err := os.Create(*output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			if *format == "json" {
+				if jobs == nil {
+					jobs = []*model.Job{}
+				}
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(jobs)
+			} else {
+				err = writeCSV(w, jobs)
+			}
+			if err != nil {
+				return err
+			}
+			if *output != "" {
+				fmt.Fprintf(a.Stderr, "Exported %d jobs to %s\n", len(jobs), *output)
+			}
+			return nil
+		}
+	},
+}
+
+func writeCSV(w io.Writer, jobs []*model.Job) error {
+	cw := csv.NewWriter(w)
+	cw.Write(exportColumns)
+	for _, j := range jobs {
+		duplicateOf := ""
+		if j.DuplicateOf != 0 {
+			duplicateOf = strconv.FormatInt(j.DuplicateOf, 10)
+		}
+		cw.Write([]string{
+			strconv.FormatInt(j.ID, 10), j.Company, j.Title, j.URL, j.Location,
+			j.DiscoveredAt.UTC().Format(time.RFC3339), strconv.FormatFloat(j.Score, 'f', -1, 64),
+			j.Season, yearString(j.Year), j.RoleFamily, duplicateOf,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
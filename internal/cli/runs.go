@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"intern-job-tracker/internal/classifier"
+	"intern-job-tracker/internal/location"
+	"intern-job-tracker/internal/model"
+	"intern-job-tracker/internal/scheduler"
+	"intern-job-tracker/internal/scoring"
+	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
+)
+
+var runsList = &command{
+	help: "List recent job check runs",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		limit := fs.Int("limit", 10, "Number of runs to list")
+		asJSON := fs.Bool("json", false, "Print JSON")
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			logs, err := a.runLogs.GetRecent(*limit)
+			if err != nil {
+				return err
+			}
+			if *asJSON {
+				if logs == nil {
+					logs = []*model.RunLog{}
+				}
+				return a.printJSON(logs)
+			}
+			w := a.table("ID", "RUN AT", "STATUS", "COMPANIES", "JOBS", "NEW", "SENT", "DURATION", "ERROR")
+			for _, l := range logs {
+				duration := (time.Duration(l.DurationMs) * time.Millisecond).Round(100 * time.Millisecond)
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n", l.ID, l.RunAt.Local().Format("2006-01-02 15:04"), l.Status,
+					l.CompaniesChecked, l.JobsFound, l.NewJobs, l.NotificationsSent, duration, truncate(l.ErrorMessage, 50))
+			}
+			return w.Flush()
+		}
+	},
+}
+
+// settings loads the settings the server would run with: values saved
+// through the API, then the config file, then the server's defaults.
+func (a *App) settings() (settings.Settings, error) {
+	concurrency, err := strconv.Atoi(a.option("scrape-concurrency", "1"))
+	if err != nil {
+		return settings.Settings{}, fmt.Errorf("invalid scrape concurrency: %v", err)
+	}
+	timezone := os.Getenv("CRON_TZ")
+	if timezone == "" {
+		timezone = "Local"
+	}
+	svc, err := settings.NewService(a.configRepo, settings.Settings{
+		Recipient:         a.option("recipient", ""),
+		Schedule:          a.option("schedule", "0 9 * * *"),
+		Timezone:          a.option("timezone", timezone),
+		NotificationMode:  a.option("notification-mode", settings.NotifyAll),
+		ScrapeConcurrency: concurrency,
+	})
+	if err != nil {
+		return settings.Settings{}, err
+	}
+	return svc.Get(), nil
+}
+
+var scrapeCommand = &command{
+	help: "Check one company now, saving and notifying new jobs unless -dry-run is given",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		company := fs.String("company", "", "Company to check, by id or name (required)")
+		dryRun := fs.Bool("dry-run", false, "Print the jobs found without saving them or sending notifications")
+		return func(a *App, args []string) error {
+			if len(args) > 0 || *company == "" {
+				return errUsage
+			}
+			c, err := a.findCompany(*company)
+			if err != nil {
+				return err
+			}
+			if *dryRun {
+				return a.dryRun(c)
+			}
+			return a.scrape(c)
+		}
+	},
+}
+
+// dryRun scrapes a company and prints what it found, marking the jobs not
+// saved yet.
+func (a *App) dryRun(company *model.Company) error {
+	config, err := scraper.ConfigFor(company)
+	if err != nil {
+		return err
+	}
+	result, err := scraper.NewScraper(a.Client).Preview(config)
+	if err != nil {
+		return err
+	}
+
+	w := a.table("", "TITLE", "LOCATION", "URL")
+	newJobs := 0
+	for _, job := range result.Jobs {
+		existing, err := a.jobs.GetByURL(job.URL)
+		if err != nil {
+			return err
+		}
+		mark := ""
+		if existing == nil {
+			mark = "new"
+			newJobs++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, truncate(job.Title, 60), truncate(job.Location, 30), job.URL)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Stdout, "\n%s: %d jobs, %d new, %d other links (HTTP %d, %d pages, %s). Nothing was saved.\n",
+		company.Name, len(result.Jobs), newJobs, len(result.Rejected), result.StatusCode, result.Pages, result.Duration.Round(time.Millisecond))
+	return nil
+}
+
+// scrape checks a company the way a scheduled run would, saving new jobs and
+// notifying them.
+func (a *App) scrape(company *model.Company) error {
+	cfg, err := a.settings()
+	if err != nil {
+		return err
+	}
+	minScore, err := strconv.ParseFloat(a.option("min-score", "0"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid minimum score: %v", err)
+	}
+	titleClassifier, err := classifier.New(classifier.DefaultRules())
+	if err != nil {
+		return err
+	}
+	prefs := scoring.DefaultPreferences()
+	prefs.PreferredLocations = scoring.ParseLocations(a.option("preferred-locations", ""))
+	prefs.CompanyTiers, err = scoring.ParseCompanyTiers(a.option("company-tiers", ""))
+	if err != nil {
+		return fmt.Errorf("invalid company tiers: %v", err)
+	}
+	prefs.TargetTerm = a.option("target-term", "")
+
+	s := scheduler.New(a.jobs, a.companies, a.runLogs, scraper.NewScraper(a.Client), a.Notifier, cfg.Recipient)
+	s.AddEnricher(titleClassifier)
+	s.AddEnricher(location.New())
+	s.AddEnricher(scoring.New(prefs))
+	s.SetNotificationRules(scheduler.NotificationRules{MinScore: minScore, Mode: cfg.NotificationMode})
+	if err := s.ApplySettings(cfg); err != nil {
+		return err
+	}
+
+	run, err := s.RunCompanies(company.ID)
+	if err != nil {
+		return err
+	}
+	if run.CompaniesTotal == 0 {
+		fmt.Fprintf(a.Stdout, "Skipped %s: it is disabled or outside its active dates\n", company.Name)
+		return nil
+	}
+	if run.Error != "" {
+		return fmt.Errorf("checking %s: %s", company.Name, run.Error)
+	}
+	fmt.Fprintf(a.Stdout, "%s: %d jobs, %d new\n", company.Name, run.JobsFound, run.NewJobs)
+	return nil
+}
+
+var notifyTest = &command{
+	help: "Send a test notification to check delivery",
+	flags: func(fs *flag.FlagSet) func(*App, []string) error {
+		recipient := fs.String("recipient", "", "Recipient (default the configured recipient)")
+		message := fs.String("message", "Test notification from the intern job tracker", "Message to send")
+		return func(a *App, args []string) error {
+			if len(args) > 0 {
+				return errUsage
+			}
+			to := *recipient
+			if to == "" {
+				cfg, err := a.settings()
+				if err != nil {
+					return err
+				}
+				to = cfg.Recipient
+			}
+			if to == "" {
+				return fmt.Errorf("no recipient configured, pass -recipient")
+			}
+			if err := a.Notifier.Send(to, *message); err != nil {
+				return fmt.Errorf("sending to %s: %v", to, err)
+			}
+			fmt.Fprintf(a.Stdout, "Sent test notification to %s\n", to)
+			return nil
+		}
+	},
+}
@@ -0,0 +1,207 @@
+package compensation
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"intern-job-tracker/internal/model"
+)
+
+// Pay units.
+const (
+	Hour  = "hour"
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+	Year  = "year"
+)
+
+// hoursPer converts a pay unit to working hours, assuming a 40-hour week and
+// 52 paid weeks a year.
+var hoursPer = map[string]float64{
+	Hour:  1,
+	Day:   8,
+	Week:  40,
+	Month: 40 * 52 / 12.0,
+	Year:  40 * 52,
+}
+
+const (
+	currencyPattern = `(?:USD|CAD|EUR|GBP|INR|C\$|CA\$|US\$|\$|€|£|₹)`
+	amountPattern   = `(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d+))?\s*([kK])?`
+)
+
+var (
+	// rangePattern matches "$40 - $55/hr", "USD 40 - 55.50 per HOUR" or
+	// "$120,000 to $150,000 annually".
+	rangePattern = regexp.MustCompile(
+		`(?i)(` + currencyPattern + `)\s*` + amountPattern +
+			`(?:\s*(?:-|–|—|to)\s*` + currencyPattern + `?\s*` + amountPattern + `)?` +
+			`(?:\s*(` + currencyPattern + `))?` +
+			`\s*(/\s*|per\s+|an?\s+)?(hourly|hour|hr|h|daily|day|weekly|week|wk|monthly|month|mo|annually|annual|yearly|year|yr|annum)?\b`,
+	)
+	// magnitudePattern rejects amounts such as "$10 billion in revenue".
+	magnitudePattern = regexp.MustCompile(`^\s*(million|billion|m\b|bn\b|b\b)`)
+)
+
+var units = map[string]string{
+	"hourly": Hour, "hour": Hour, "hr": Hour, "h": Hour,
+	"daily": Day, "day": Day,
+	"weekly": Week, "week": Week, "wk": Week,
+	"monthly": Month, "month": Month, "mo": Month,
+	"annually": Year, "annual": Year, "yearly": Year, "year": Year, "yr": Year, "annum": Year,
+}
+
+var currencies = map[string]string{
+	"$": "USD", "US$": "USD", "USD": "USD",
+	"C$": "CAD", "CA$": "CAD", "CAD": "CAD",
+	"€": "EUR", "EUR": "EUR",
+	"£": "GBP", "GBP": "GBP",
+	"₹": "INR", "INR": "INR",
+}
+
+// Parse finds the first pay amount or range in text, such as "$40 - $55/hr"
+// or the "USD 40 - 55.50 per HOUR" form the detail fetcher produces from a
+// JSON-LD baseSalary. Amounts without a unit must be a range, and their unit
+// is inferred from their size. Returns nil if no pay is mentioned.
+func Parse(text string) *model.Compensation {
+	for _, m := range rangePattern.FindAllStringSubmatchIndex(text, -1) {
+		if magnitudePattern.MatchString(text[m[1]:]) {
+			continue
+		}
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return text[m[2*i]:m[2*i+1]]
+		}
+
+		min := amount(group(2), group(3), group(4))
+		max := min
+		isRange := group(5) != ""
+		if isRange {
+			max = amount(group(5), group(6), group(7))
+			// "$40-55k" applies the suffix to both ends.
+			if group(4) == "" && group(7) != "" && min < 1000 {
+				min *= 1000
+			}
+		}
+
+		unit := units[strings.ToLower(group(10))]
+		if unit == "" {
+			if !isRange {
+				continue
+			}
+			unit = inferUnit(max)
+		}
+		if min <= 0 || max < min {
+			continue
+		}
+
+		return New(currencies[strings.ToUpper(group(1))], min, max, unit)
+	}
+	return nil
+}
+
+// New creates a Compensation and fills in its hourly equivalents.
+func New(currency string, min, max float64, unit string) *model.Compensation {
+	hours := hoursPer[unit]
+	if hours == 0 {
+		hours = 1
+	}
+	return &model.Compensation{
+		Currency:  currency,
+		Min:       min,
+		Max:       max,
+		Unit:      unit,
+		HourlyMin: round(min / hours),
+		HourlyMax: round(max / hours),
+	}
+}
+
+// inferUnit guesses the pay unit of an amount with none stated.
+func inferUnit(amount float64) string {
+	switch {
+	case amount < 300:
+		return Hour
+	case amount < 20000:
+		return Month
+	default:
+		return Year
+	}
+}
+
+func amount(whole, frac, thousands string) float64 {
+	s := strings.ReplaceAll(whole, ",", "")
+	if frac != "" {
+		s += "." + frac
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	if thousands != "" {
+		f *= 1000
+	}
+	return f
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// Extractor parses compensation from a job's fetched detail page.
+type Extractor struct{}
+
+// NewExtractor creates a new Extractor.
+func NewExtractor() *Extractor {
+	return &Extractor{}
+}
+
+// Enrich parses the job's salary, falling back to its description, and
+// stores the result on it.
+func (e *Extractor) Enrich(job *model.Job) {
+	job.Compensation = Extract(job)
+}
+
+// Extract returns the compensation stated on a job's detail page, if any.
+func Extract(job *model.Job) *model.Compensation {
+	if job.Detail == nil {
+		return nil
+	}
+	for _, text := range []string{job.Detail.Salary, job.Detail.Description, job.Detail.Requirements} {
+		if c := Parse(text); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// Store is the job storage needed to back-fill compensation.
+type Store interface {
+	GetWithoutCompensation() ([]*model.Job, error)
+	UpdateCompensation(id int64, c *model.Compensation) error
+}
+
+// Backfill parses compensation for every stored job with fetched details that
+// has not been parsed yet, and returns the number of jobs found to state
+// their pay. Jobs stating none are recorded too, so they are parsed once.
+func Backfill(store Store) (int, error) {
+	jobs, err := store.GetWithoutCompensation()
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, job := range jobs {
+		c := Extract(job)
+		if err := store.UpdateCompensation(job.ID, c); err != nil {
+			return updated, err
+		}
+		if c != nil {
+			updated++
+		}
+	}
+	return updated, nil
+}
@@ -0,0 +1,112 @@
+package compensation
+
+import (
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want *model.Compensation
+	}{
+		{
+			text: "The hourly range for this role is $40 - $55/hr plus benefits.",
+			want: &model.Compensation{Currency: "USD", Min: 40, Max: 55, Unit: Hour, HourlyMin: 40, HourlyMax: 55},
+		},
+		{
+			text: "USD 40 - 55.50 per HOUR",
+			want: &model.Compensation{Currency: "USD", Min: 40, Max: 55.5, Unit: Hour, HourlyMin: 40, HourlyMax: 55.5},
+		},
+		{
+			text: "Base salary: $104,000 to $156,000 annually",
+			want: &model.Compensation{Currency: "USD", Min: 104000, Max: 156000, Unit: Year, HourlyMin: 50, HourlyMax: 75},
+		},
+		{
+			text: "Pay: $8,000 - $9,500 per month",
+			want: &model.Compensation{Currency: "USD", Min: 8000, Max: 9500, Unit: Month, HourlyMin: 46.15, HourlyMax: 54.81},
+		},
+		{
+			text: "Compensation CA$30-35 an hour",
+			want: &model.Compensation{Currency: "CAD", Min: 30, Max: 35, Unit: Hour, HourlyMin: 30, HourlyMax: 35},
+		},
+		{
+			text: "Salary range $90-110k",
+			want: &model.Compensation{Currency: "USD", Min: 90000, Max: 110000, Unit: Year, HourlyMin: 43.27, HourlyMax: 52.88},
+		},
+		{
+			text: "£2,500 monthly stipend",
+			want: &model.Compensation{Currency: "GBP", Min: 2500, Max: 2500, Unit: Month, HourlyMin: 14.42, HourlyMax: 14.42},
+		},
+		{
+			text: "We are a $10 billion company serving $5 - $7 million customers",
+			want: nil,
+		},
+		{
+			text: "Win a $50 gift card",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := Parse(tt.text)
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractor_Enrich(t *testing.T) {
+	job := &model.Job{
+		Detail: &model.JobDetail{
+			Description: "Interns earn $45/hour.",
+		},
+	}
+	NewExtractor().Enrich(job)
+
+	if job.Compensation == nil || job.Compensation.HourlyMax != 45 {
+		t.Errorf("expected compensation from description, got %+v", job.Compensation)
+	}
+
+	noDetail := &model.Job{Title: "$45/hour Intern"}
+	NewExtractor().Enrich(noDetail)
+	if noDetail.Compensation != nil {
+		t.Error("expected no compensation without a detail page")
+	}
+}
+
+type mockStore struct {
+	jobs    []*model.Job
+	updated map[int64]*model.Compensation
+}
+
+func (m *mockStore) GetWithoutCompensation() ([]*model.Job, error) {
+	return m.jobs, nil
+}
+
+func (m *mockStore) UpdateCompensation(id int64, c *model.Compensation) error {
+	m.updated[id] = c
+	return nil
+}
+
+func TestBackfill(t *testing.T) {
+	store := &mockStore{
+		jobs: []*model.Job{
+			{ID: 1, Detail: &model.JobDetail{Salary: "USD 50 per HOUR"}},
+			{ID: 2, Detail: &model.JobDetail{Description: "No pay listed"}},
+		},
+		updated: make(map[int64]*model.Compensation),
+	}
+
+	n, err := Backfill(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || store.updated[1] == nil || store.updated[1].HourlyMin != 50 {
+		t.Errorf("expected 1 job back-filled, got %d (%+v)", n, store.updated)
+	}
+	if c, ok := store.updated[2]; !ok || c != nil {
+		t.Errorf("expected the job without pay to be recorded as parsed, got %+v", store.updated)
+	}
+}
@@ -0,0 +1,441 @@
+// Package config loads the tracker's configuration file. The file sets the
+// same options as the command line flags, plus the companies to track; see
+// config.example.yaml for the schema. Values may refer to environment
+// variables as ${NAME} or ${NAME:-default}, and every option can be
+// overridden with a TRACKER_ environment variable named after its path, such
+// as TRACKER_NOTIFICATIONS_RECIPIENT for notifications.recipient.
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"intern-job-tracker/internal/logging"
+	"intern-job-tracker/internal/model"
+	"intern-job-tracker/internal/scheduler"
+	"intern-job-tracker/internal/scoring"
+	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Company sync modes.
+const (
+	// ModeSync makes the companies table match the file: companies are
+	// added and updated, and companies missing from the file are disabled.
+	ModeSync = "sync"
+	// ModeSeed only adds companies that do not exist yet, leaving
+	// changes made in the dashboard alone.
+	ModeSeed = "seed"
+)
+
+// EnvPrefix starts the names of environment variables overriding options.
+const EnvPrefix = "TRACKER_"
+
+// kind says how an option's value is checked.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindDuration
+	kindCron
+	kindTimezone
+	kindMode
+	kindURL
+	kindLogFormat
+	kindLogLevel
+	kindCompanyTiers
+)
+
+// option is a setting in the config file and the command line flag it sets.
+type option struct {
+	path string
+	flag string
+	kind kind
+}
+
+// options lists every setting of the config file, by section.
+var options = []option{
+	{"server.port", "port", kindInt},
+	{"server.db", "db", kindString},
+	{"server.shutdown_timeout", "shutdown-timeout", kindDuration},
+	{"logging.format", "log-format", kindLogFormat},
+	{"logging.level", "log-level", kindLogLevel},
+	{"schedule.cron", "schedule", kindCron},
+	{"schedule.timezone", "timezone", kindTimezone},
+	{"notifications.recipient", "recipient", kindString},
+	{"notifications.mode", "notification-mode", kindMode},
+	{"notifications.min_score", "min-score", kindFloat},
+	{"notifications.watched_changes", "notify-watched-changes", kindBool},
+	{"alerts.failure_percent", "alert-failure-percent", kindFloat},
+	{"alerts.heartbeat_window", "heartbeat-window", kindDuration},
+	{"alerts.ping_url", "ping-url", kindURL},
+	{"scrape.concurrency", "scrape-concurrency", kindInt},
+	{"scrape.rules", "rules", kindString},
+	{"scrape.fetch_details", "fetch-details", kindBool},
+	{"scrape.detail_interval", "detail-interval", kindDuration},
+	{"scrape.detail_selectors", "detail-selectors", kindString},
+	{"archive.dir", "archive-dir", kindString},
+	{"archive.max_age", "archive-max-age", kindDuration},
+	{"archive.max_mb", "archive-max-mb", kindInt},
+	{"scoring.preferred_locations", "preferred-locations", kindString},
+	{"scoring.company_tiers", "company-tiers", kindCompanyTiers},
+	{"scoring.target_term", "target-term", kindString},
+}
+
+// Config is a loaded configuration.
+type Config struct {
+	// Flags holds the options set in the file or the environment, keyed by
+	// the command line flag they correspond to.
+	Flags map[string]string
+	// Companies lists the companies to track. It is nil if the file has no
+	// companies section, in which case the companies table is left alone.
+	Companies []*model.Company
+	// CompaniesMode is ModeSync or ModeSeed.
+	CompaniesMode string
+}
+
+// Error is a problem with the configuration, at a line of the file if known.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	case e.File != "":
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return e.Msg
+}
+
+// Errors lists every problem found in a configuration.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Load reads the config file at path and applies environment overrides. An
+// empty path loads the overrides alone. Problems are returned as Errors.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		return parse("", "", os.LookupEnv)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parse(string(data), path, os.LookupEnv)
+}
+
+// Parse parses a config file's contents, applying environment overrides.
+// name is used in error messages.
+func Parse(data, name string) (*Config, error) {
+	return parse(data, name, os.LookupEnv)
+}
+
+// decoder collects errors while decoding a file.
+type decoder struct {
+	file   string
+	lookup func(string) (string, bool)
+	errs   Errors
+}
+
+func (d *decoder) errorf(line int, format string, args ...interface{}) {
+	d.errs = append(d.errs, &Error{File: d.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+func parse(data, name string, lookup func(string) (string, bool)) (*Config, error) {
+	root, err := parseYAML(data)
+	if err != nil {
+		e, ok := err.(*Error)
+		if !ok {
+			e = &Error{Msg: err.Error()}
+		}
+		e.File = name
+		return nil, Errors{e}
+	}
+
+	d := &decoder{file: name, lookup: lookup}
+	cfg := &Config{Flags: make(map[string]string), CompaniesMode: ModeSync}
+
+	sections := make(map[string]bool)
+	for _, opt := range options {
+		sections[strings.SplitN(opt.path, ".", 2)[0]] = true
+	}
+	for _, key := range root.keys {
+		value := root.fields[key]
+		switch {
+		case key == "companies":
+			cfg.Companies = d.companies(value)
+		case key == "companies_mode":
+			mode := d.scalar(value, key)
+			if mode != ModeSync && mode != ModeSeed {
+				d.errorf(value.line, "companies_mode: must be %q or %q", ModeSync, ModeSeed)
+			}
+			cfg.CompaniesMode = mode
+		case sections[key]:
+			d.section(cfg, key, value)
+		default:
+			d.errorf(value.line, "unknown key %q", key)
+		}
+	}
+
+	// Environment overrides take precedence over the file.
+	for _, opt := range options {
+		env := EnvName(opt.path)
+		value, ok := lookup(env)
+		if !ok {
+			continue
+		}
+		if msg := check(opt.kind, value); msg != "" {
+			d.errs = append(d.errs, &Error{Msg: fmt.Sprintf("%s: %s", env, msg)})
+			continue
+		}
+		cfg.Flags[opt.flag] = value
+	}
+	if mode, ok := lookup(EnvPrefix + "COMPANIES_MODE"); ok {
+		if mode != ModeSync && mode != ModeSeed {
+			d.errs = append(d.errs, &Error{Msg: fmt.Sprintf("%sCOMPANIES_MODE: must be %q or %q", EnvPrefix, ModeSync, ModeSeed)})
+		}
+		cfg.CompaniesMode = mode
+	}
+
+	if len(d.errs) > 0 {
+		sort.SliceStable(d.errs, func(i, j int) bool { return d.errs[i].Line < d.errs[j].Line })
+		return nil, d.errs
+	}
+	return cfg, nil
+}
+
+// EnvName returns the environment variable overriding the option at path.
+func EnvName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// section decodes one section of options.
+func (d *decoder) section(cfg *Config, name string, n *node) {
+	if n.kind != mapNode {
+		d.errorf(n.line, "%s: expected a mapping, got %s", name, n.kindName())
+		return
+	}
+	for _, key := range n.keys {
+		value := n.fields[key]
+		path := name + "." + key
+		opt, ok := findOption(path)
+		if !ok {
+			d.errorf(value.line, "unknown key %q", path)
+			continue
+		}
+		s := d.scalar(value, path)
+		if msg := check(opt.kind, s); msg != "" {
+			d.errorf(value.line, "%s: %s", path, msg)
+			continue
+		}
+		cfg.Flags[opt.flag] = s
+	}
+}
+
+func findOption(path string) (option, bool) {
+	for _, opt := range options {
+		if opt.path == path {
+			return opt, true
+		}
+	}
+	return option{}, false
+}
+
+// companies decodes the companies list.
+func (d *decoder) companies(n *node) []*model.Company {
+	companies := []*model.Company{}
+	if n.kind == scalarNode && n.value == "" {
+		return companies
+	}
+	if n.kind != listNode {
+		d.errorf(n.line, "companies: expected a list, got %s", n.kindName())
+		return companies
+	}
+
+	seen := make(map[string]int)
+	for i, item := range n.items {
+		prefix := fmt.Sprintf("companies[%d]", i)
+		if item.kind != mapNode {
+			d.errorf(item.line, "%s: expected a mapping, got %s", prefix, item.kindName())
+			continue
+		}
+		c := &model.Company{SearchTerm: "intern", Enabled: true}
+		for _, key := range item.keys {
+			value := item.fields[key]
+			s := d.scalar(value, prefix+"."+key)
+			switch key {
+			case "name":
+				c.Name = s
+			case "career_url":
+				if msg := check(kindURL, s); msg != "" {
+					d.errorf(value.line, "%s.career_url: %s", prefix, msg)
+				}
+				c.CareerURL = s
+			case "search_term":
+				c.SearchTerm = s
+			case "enabled":
+				if msg := check(kindBool, s); msg != "" {
+					d.errorf(value.line, "%s.enabled: %s", prefix, msg)
+				}
+				c.Enabled, _ = strconv.ParseBool(s)
+			case "schedule":
+				if _, err := scheduler.ParseSchedule(s); s != "" && err != nil {
+					d.errorf(value.line, "%s.schedule: %v", prefix, err)
+				}
+				c.Schedule = s
+			case "active_from", "active_until":
+				if _, err := time.Parse("2006-01-02", s); s != "" && err != nil {
+					d.errorf(value.line, "%s.%s: expected a YYYY-MM-DD date, got %q", prefix, key, s)
+				}
+				if key == "active_from" {
+					c.ActiveFrom = s
+				} else {
+					c.ActiveUntil = s
+				}
+			case "source_type":
+				if s != "" && !slices.Contains(scraper.SourceTypes(), s) {
+					d.errorf(value.line, "%s.source_type: unknown source type %q, expected %s", prefix, s, strings.Join(scraper.SourceTypes(), ", "))
+				}
+				c.SourceType = s
+			case "source_id":
+				c.SourceID = s
+			case "selectors":
+				if _, err := scraper.ParseListSelectors(s); err != nil {
+					d.errorf(value.line, "%s.selectors: %v", prefix, err)
+				}
+				c.Selectors = s
+			default:
+				d.errorf(value.line, "%s: unknown key %q", prefix, key)
+			}
+		}
+
+		if c.Name == "" {
+			d.errorf(item.line, "%s: name is required", prefix)
+		} else if first, dup := seen[strings.ToLower(c.Name)]; dup {
+			d.errorf(item.line, "%s: duplicate company %q, first listed on line %d", prefix, c.Name, first)
+		} else {
+			seen[strings.ToLower(c.Name)] = item.line
+		}
+		if c.CareerURL == "" {
+			d.errorf(item.line, "%s: career_url is required", prefix)
+		}
+		if c.ActiveFrom != "" && c.ActiveUntil != "" && c.ActiveFrom > c.ActiveUntil {
+			d.errorf(item.line, "%s: active_from must not be after active_until", prefix)
+		}
+		// Source types and selectors are checked on their own lines above.
+		_, selErr := scraper.ParseListSelectors(c.Selectors)
+		if selErr == nil && (c.SourceType == "" || slices.Contains(scraper.SourceTypes(), c.SourceType)) {
+			if err := scraper.ValidateSource(c.SourceType, c.SourceID, c.Selectors); err != nil {
+				d.errorf(item.line, "%s: %v", prefix, err)
+			}
+		}
+		companies = append(companies, c)
+	}
+	return companies
+}
+
+// envRef matches ${NAME} and ${NAME:-default}.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// scalar returns a scalar's value with environment variables substituted.
+func (d *decoder) scalar(n *node, path string) string {
+	if n.kind != scalarNode {
+		d.errorf(n.line, "%s: expected a value, got %s", path, n.kindName())
+		return ""
+	}
+	return envRef.ReplaceAllStringFunc(n.value, func(ref string) string {
+		m := envRef.FindStringSubmatch(ref)
+		if value, ok := d.lookup(m[1]); ok && value != "" {
+			return value
+		}
+		if m[2] != "" {
+			return m[3]
+		}
+		d.errorf(n.line, "%s: environment variable %s is not set", path, m[1])
+		return ""
+	})
+}
+
+// check validates a value of the given kind, returning a message describing
+// the problem, or "" if it is valid. Empty values are valid for strings and
+// URLs only.
+func check(k kind, s string) string {
+	if s == "" && (k == kindString || k == kindURL || k == kindCompanyTiers) {
+		return ""
+	}
+	switch k {
+	case kindInt:
+		if n, err := strconv.Atoi(s); err != nil || n < 0 {
+			return fmt.Sprintf("expected a whole number, got %q", s)
+		}
+	case kindFloat:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return fmt.Sprintf("expected a number, got %q", s)
+		}
+	case kindBool:
+		if _, err := strconv.ParseBool(s); err != nil {
+			return fmt.Sprintf("expected true or false, got %q", s)
+		}
+	case kindDuration:
+		if _, err := time.ParseDuration(s); err != nil {
+			return fmt.Sprintf("expected a duration such as 30s or 26h, got %q", s)
+		}
+	case kindCron:
+		if _, err := cron.ParseStandard(s); err != nil {
+			return fmt.Sprintf("invalid cron schedule %q: %v", s, err)
+		}
+	case kindTimezone:
+		if _, err := time.LoadLocation(s); err != nil {
+			return fmt.Sprintf("unknown time zone %q", s)
+		}
+	case kindMode:
+		switch s {
+		case settings.NotifyAll, settings.NotifyNewJobs, settings.NotifyOff:
+		default:
+			return fmt.Sprintf("must be %q, %q or %q", settings.NotifyAll, settings.NotifyNewJobs, settings.NotifyOff)
+		}
+	case kindLogFormat:
+		if s != logging.FormatText && s != logging.FormatJSON {
+			return fmt.Sprintf("must be %q or %q", logging.FormatText, logging.FormatJSON)
+		}
+	case kindLogLevel:
+		if _, err := logging.ParseLevel(s); err != nil {
+			return err.Error()
+		}
+	case kindURL:
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Sprintf("expected an http or https URL, got %q", s)
+		}
+	case kindCompanyTiers:
+		if _, err := scoring.ParseCompanyTiers(s); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+const sample = `# Tracker configuration
+server:
+  port: 9090
+  db: "${DATA_DIR:-/var/lib/tracker}/jobs.db"
+
+schedule:
+  cron: "0 8 * * 1-5"   # weekdays
+  timezone: America/New_York
+
+notifications:
+  recipient: ${RECIPIENT}
+  mode: new_jobs
+
+companies_mode: seed
+companies:
+  - name: Google
+    career_url: https://careers.google.com/jobs?q=intern#results
+  - name: Stripe
+    career_url: 'https://stripe.com/jobs/search?query=intern'
+    search_term: internship
+    enabled: false
+    schedule: 6h
+    active_from: 2026-08-01
+    active_until: 2026-11-30
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := parse(sample, "tracker.yaml", env(map[string]string{"RECIPIENT": "me@example.com"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"port":              "9090",
+		"db":                "/var/lib/tracker/jobs.db",
+		"schedule":          "0 8 * * 1-5",
+		"timezone":          "America/New_York",
+		"recipient":         "me@example.com",
+		"notification-mode": "new_jobs",
+	}
+	if len(cfg.Flags) != len(want) {
+		t.Errorf("expected %d flags, got %v", len(want), cfg.Flags)
+	}
+	for k, v := range want {
+		if cfg.Flags[k] != v {
+			t.Errorf("flag %s: expected %q, got %q", k, v, cfg.Flags[k])
+		}
+	}
+
+	if cfg.CompaniesMode != ModeSeed {
+		t.Errorf("expected seed mode, got %q", cfg.CompaniesMode)
+	}
+	if len(cfg.Companies) != 2 {
+		t.Fatalf("expected 2 companies, got %d", len(cfg.Companies))
+	}
+	google, stripe := cfg.Companies[0], cfg.Companies[1]
+	if google.CareerURL != "https://careers.google.com/jobs?q=intern#results" || google.SearchTerm != "intern" || !google.Enabled {
+		t.Errorf("unexpected defaults %+v", google)
+	}
+	if stripe.SearchTerm != "internship" || stripe.Enabled || stripe.Schedule != "6h" ||
+		stripe.ActiveFrom != "2026-08-01" || stripe.ActiveUntil != "2026-11-30" {
+		t.Errorf("unexpected company %+v", stripe)
+	}
+}
+
+func TestParse_EnvOverrides(t *testing.T) {
+	cfg, err := parse(sample, "tracker.yaml", env(map[string]string{
+		"RECIPIENT":                       "me@example.com",
+		"TRACKER_NOTIFICATIONS_RECIPIENT": "+15550100",
+		"TRACKER_SCRAPE_CONCURRENCY":      "4",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Flags["recipient"] != "+15550100" || cfg.Flags["scrape-concurrency"] != "4" {
+		t.Errorf("expected environment to override the file, got %v", cfg.Flags)
+	}
+
+	if _, err := parse("", "", env(map[string]string{"TRACKER_SCHEDULE_CRON": "often"})); err == nil ||
+		!strings.Contains(err.Error(), "TRACKER_SCHEDULE_CRON") {
+		t.Errorf("expected error naming the variable, got %v", err)
+	}
+}
+
+func TestParse_YAMLFeatures(t *testing.T) {
+	data := `companies:
+  - &acme
+    name: Acme
+    career_url: https://acme.com/jobs
+    search_term: &term internship
+    schedule: 6h
+  - <<: *acme
+    name: >-
+      Globex
+      Corporation
+    career_url: https://globex.com/jobs
+    schedule: 12h
+  - name: Initech
+    career_url: https://initech.com/jobs
+    search_term: *term
+`
+	cfg, err := parse(data, "", env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Companies) != 3 {
+		t.Fatalf("expected 3 companies, got %d", len(cfg.Companies))
+	}
+	globex, initech := cfg.Companies[1], cfg.Companies[2]
+	if globex.Name != "Globex Corporation" || globex.SearchTerm != "internship" || globex.Schedule != "12h" {
+		t.Errorf("expected merged keys not to override the company's own, got %+v", globex)
+	}
+	if initech.SearchTerm != "internship" {
+		t.Errorf("expected the aliased search term, got %+v", initech)
+	}
+
+	_, err = parse("server:\n  port: 1\n  port: 2\n", "", env(nil))
+	var errs Errors
+	if !errors.As(err, &errs) || errs[0].Line != 3 || !strings.Contains(errs[0].Msg, "duplicate key") {
+		t.Errorf("expected a duplicate key error on line 3, got %v", err)
+	}
+}
+
+func TestParse_EmptyFile(t *testing.T) {
+	cfg, err := parse("# nothing here\n", "", env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Flags) != 0 || cfg.Companies != nil || cfg.CompaniesMode != ModeSync {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		line int
+		msg  string
+	}{
+		{"unknown section", "servr:\n  port: 1\n", 1, `unknown key "servr"`},
+		{"unknown key", "server:\n  port: 1\n  host: x\n", 3, `unknown key "server.host"`},
+		{"bad int", "server:\n  port: http\n", 2, "expected a whole number"},
+		{"bad cron", "\nschedule:\n  cron: sometimes\n", 3, "invalid cron schedule"},
+		{"bad mode", "notifications:\n  mode: loud\n", 2, "notifications.mode"},
+		{"bad log level", "logging:\n  level: loud\n", 2, "unknown log level"},
+		{"bad company tier", "scoring:\n  company_tiers: Google=first\n", 2, "invalid tier"},
+		{"unset env", "notifications:\n  recipient: ${NOPE}\n", 2, "NOPE is not set"},
+		{"tab", "server:\n\tport: 1\n", 2, "tabs"},
+		{"bad indent", "server:\n  port: 1\n    db: x\n", 3, "mapping values are not allowed"},
+		{"missing url", "companies:\n  - name: Acme\n", 2, "career_url is required"},
+		{"bad url", "companies:\n  - name: Acme\n    career_url: acme.com/jobs\n", 3, "http or https URL"},
+		{"bad schedule", "companies:\n  - name: Acme\n    career_url: https://acme.com\n    schedule: 10s\n", 4, "companies[0].schedule"},
+		{"bad selectors", "companies:\n  - name: Acme\n    career_url: https://acme.com\n    selectors: \"title=h3\"\n", 4, "companies[0].selectors"},
+		{"bad source type", "companies:\n  - name: Acme\n    career_url: https://acme.com\n    source_type: rss\n", 4, "unknown source type"},
+		{"missing source id", "companies:\n  - name: Acme\n    career_url: https://acme.com\n    source_type: greenhouse\n", 2, "greenhouse source_id"},
+		{"bad date", "companies:\n  - name: Acme\n    career_url: https://acme.com\n    active_from: 1/2/2026\n", 4, "YYYY-MM-DD"},
+		{"duplicate", "companies:\n  - name: Acme\n    career_url: https://acme.com\n  - name: acme\n    career_url: https://acme.com\n", 4, "first listed on line 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.data, "tracker.yaml", env(nil))
+			var errs Errors
+			if !errors.As(err, &errs) || len(errs) == 0 {
+				t.Fatalf("expected Errors, got %v", err)
+			}
+			if errs[0].Line != tt.line || !strings.Contains(errs[0].Msg, tt.msg) {
+				t.Errorf("expected line %d containing %q, got %v", tt.line, tt.msg, errs[0])
+			}
+			if !strings.HasPrefix(err.Error(), "tracker.yaml:") {
+				t.Errorf("expected the file name in %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestParse_ReportsAllErrors(t *testing.T) {
+	_, err := parse("server:\n  port: x\nscrape:\n  concurrency: y\n", "", env(nil))
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Line != 2 || errs[1].Line != 4 {
+		t.Errorf("expected errors on lines 2 and 4, got %v", err)
+	}
+}
+
+type memStore struct {
+	companies []*model.Company
+	nextID    int64
+}
+
+func (m *memStore) GetAll() ([]*model.Company, error) {
+	out := make([]*model.Company, len(m.companies))
+	for i, c := range m.companies {
+		copy := *c
+		out[i] = &copy
+	}
+	return out, nil
+}
+
+func (m *memStore) Create(c *model.Company) error {
+	m.nextID++
+	c.ID = m.nextID
+	copy := *c
+	m.companies = append(m.companies, &copy)
+	return nil
+}
+
+func (m *memStore) Update(c *model.Company) error {
+	for i, existing := range m.companies {
+		if existing.ID == c.ID {
+			copy := *c
+			m.companies[i] = &copy
+		}
+	}
+	return nil
+}
+
+func TestSyncCompanies(t *testing.T) {
+	store := &memStore{}
+	store.Create(&model.Company{Name: "Google", CareerURL: "https://google.com/old", SearchTerm: "intern", Enabled: true})
+	store.Create(&model.Company{Name: "Uber", CareerURL: "https://uber.com", SearchTerm: "intern", Enabled: true})
+
+	companies := []*model.Company{
+		{Name: "google", CareerURL: "https://google.com/new", SearchTerm: "intern", Enabled: true},
+		{Name: "Stripe", CareerURL: "https://stripe.com", SearchTerm: "intern", Enabled: true},
+	}
+
+	result, err := SyncCompanies(store, companies, ModeSeed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (SyncResult{Created: 1}) || store.companies[0].CareerURL != "https://google.com/old" {
+		t.Errorf("expected seeding to only add Stripe, got %+v", result)
+	}
+
+	result, err = SyncCompanies(store, companies, ModeSync)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (SyncResult{Updated: 1, Disabled: 1}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if c := store.companies[0]; c.ID != 1 || c.Name != "google" || c.CareerURL != "https://google.com/new" {
+		t.Errorf("expected Google to be updated in place, got %+v", c)
+	}
+	if store.companies[1].Enabled {
+		t.Error("expected Uber to be disabled")
+	}
+
+	result, _ = SyncCompanies(store, companies, ModeSync)
+	if result.Changed() {
+		t.Errorf("expected a second sync to change nothing, got %+v", result)
+	}
+}
+
+func TestLoad_Example(t *testing.T) {
+	cfg, err := Load("../../config.example.yaml")
+	if err != nil {
+		t.Fatalf("config.example.yaml is invalid: %v", err)
+	}
+	if len(cfg.Flags) != len(options) || len(cfg.Companies) != 4 {
+		t.Errorf("expected the example to set every option and 4 companies, got %d and %d", len(cfg.Flags), len(cfg.Companies))
+	}
+}
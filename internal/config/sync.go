@@ -0,0 +1,101 @@
+package config
+
+import (
+	"strings"
+
+	"intern-job-tracker/internal/model"
+)
+
+// CompanyStore is the part of the company repository SyncCompanies uses.
+type CompanyStore interface {
+	GetAll() ([]*model.Company, error)
+	Create(c *model.Company) error
+	Update(c *model.Company) error
+}
+
+// SyncResult counts the changes SyncCompanies made.
+type SyncResult struct {
+	Created  int
+	Updated  int
+	Disabled int
+}
+
+// Changed reports whether any company was changed.
+func (r SyncResult) Changed() bool {
+	return r.Created+r.Updated+r.Disabled > 0
+}
+
+// SyncCompanies applies the companies of a config file to the store. They are
+// matched to stored companies by name, ignoring case. In ModeSeed,
+// companies that are missing are created and nothing else changes. In
+// ModeSync, stored companies are also updated to match the file,
+// and enabled companies the file does not list are disabled rather than
+// deleted, so that their jobs keep their company.
+func SyncCompanies(store CompanyStore, companies []*model.Company, mode string) (SyncResult, error) {
+	var result SyncResult
+
+	existing, err := store.GetAll()
+	if err != nil {
+		return result, err
+	}
+	byName := make(map[string]*model.Company)
+	for _, c := range existing {
+		key := strings.ToLower(c.Name)
+		if _, ok := byName[key]; !ok {
+			byName[key] = c
+		}
+	}
+
+	listed := make(map[int64]bool)
+	for _, want := range companies {
+		have, ok := byName[strings.ToLower(want.Name)]
+		if !ok {
+			c := *want
+			if err := store.Create(&c); err != nil {
+				return result, err
+			}
+			result.Created++
+			continue
+		}
+		listed[have.ID] = true
+		if mode == ModeSeed || sameCompany(have, want) {
+			continue
+		}
+		c := *want
+		c.ID = have.ID
+		c.CreatedAt = have.CreatedAt
+		if err := store.Update(&c); err != nil {
+			return result, err
+		}
+		result.Updated++
+	}
+
+	if mode == ModeSeed {
+		return result, nil
+	}
+	for _, c := range existing {
+		if listed[c.ID] || !c.Enabled {
+			continue
+		}
+		c.Enabled = false
+		if err := store.Update(c); err != nil {
+			return result, err
+		}
+		result.Disabled++
+	}
+	return result, nil
+}
+
+// sameCompany reports whether a stored company matches its config entry.
+func sameCompany(a, b *model.Company) bool {
+	return a.Name == b.Name &&
+		a.CareerURL == b.CareerURL &&
+		a.SearchTerm == b.SearchTerm &&
+		a.Enabled == b.Enabled &&
+		a.Schedule == b.Schedule &&
+		a.ActiveFrom == b.ActiveFrom &&
+		a.ActiveUntil == b.ActiveUntil &&
+		a.SourceType == b.SourceType &&
+		a.SourceID == b.SourceID &&
+		a.Selectors == b.Selectors
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The config file is parsed with yaml.v3 and converted to a tree of nodes
+// holding only what the options need: mappings with their keys in file
+// order, lists and scalar values. Aliases are resolved and "<<" merge keys
+// applied along the way. Every node remembers its line, so that errors can
+// point at it.
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	mapNode
+	listNode
+)
+
+type node struct {
+	kind   nodeKind
+	line   int
+	value  string           // scalarNode
+	keys   []string         // mapNode, in file order
+	fields map[string]*node // mapNode
+	items  []*node          // listNode
+}
+
+func (n *node) kindName() string {
+	switch n.kind {
+	case mapNode:
+		return "a mapping"
+	case listNode:
+		return "a list"
+	}
+	return "a value"
+}
+
+// parseYAML parses a document whose top level is a mapping.
+func parseYAML(data string) (*node, error) {
+	for i, l := range strings.Split(data, "\n") {
+		if indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]; strings.Contains(indent, "\t") {
+			return nil, &Error{Line: i + 1, Msg: "tabs are not allowed for indentation"}
+		}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, yamlError(err)
+	}
+	if len(doc.Content) == 0 {
+		return &node{kind: mapNode, line: 1, fields: map[string]*node{}}, nil
+	}
+	root := resolve(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, &Error{Line: root.Line, Msg: "expected a mapping at the top level"}
+	}
+	return convert(root)
+}
+
+// yamlLine matches the position yaml.v3 puts in front of its messages.
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// yamlError turns a yaml.v3 error into an *Error with its line.
+func yamlError(err error) error {
+	msg := err.Error()
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &Error{Line: line, Msg: strings.TrimPrefix(msg, m[0])}
+	}
+	return &Error{Msg: strings.TrimPrefix(msg, "yaml: ")}
+}
+
+// resolve follows aliases to the node they refer to.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func convert(y *yaml.Node) (*node, error) {
+	line := y.Line
+	y = resolve(y)
+	switch y.Kind {
+	case yaml.MappingNode:
+		n := &node{kind: mapNode, line: line, fields: map[string]*node{}}
+		if err := n.addPairs(y); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case yaml.SequenceNode:
+		n := &node{kind: listNode, line: line}
+		for _, item := range y.Content {
+			child, err := convert(item)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, child)
+		}
+		return n, nil
+	case yaml.ScalarNode:
+		value := y.Value
+		if y.ShortTag() == "!!null" {
+			value = ""
+		}
+		return &node{kind: scalarNode, line: line, value: value}, nil
+	}
+	return nil, &Error{Line: line, Msg: "unsupported YAML node"}
+}
+
+// addPairs adds the key/value pairs of the mapping y to n. Keys merged in
+// with "<<" never replace the mapping's own.
+func (n *node) addPairs(y *yaml.Node) error {
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(y.Content); i += 2 {
+		k, v := y.Content[i], y.Content[i+1]
+		if k.Kind != yaml.ScalarNode {
+			return &Error{Line: k.Line, Msg: "mapping keys must be plain values"}
+		}
+		if k.ShortTag() == "!!merge" {
+			merges = append(merges, v)
+			continue
+		}
+		if _, ok := n.fields[k.Value]; ok {
+			return &Error{Line: k.Line, Msg: fmt.Sprintf("duplicate key %q", k.Value)}
+		}
+		child, err := convert(v)
+		if err != nil {
+			return err
+		}
+		if child.kind != scalarNode {
+			// A block below its key points at the key
+			child.line = k.Line
+		}
+		n.keys = append(n.keys, k.Value)
+		n.fields[k.Value] = child
+	}
+
+	for _, m := range merges {
+		sources := []*yaml.Node{resolve(m)}
+		if sources[0].Kind == yaml.SequenceNode {
+			sources = sources[0].Content
+		}
+		for _, source := range sources {
+			merged, err := convert(source)
+			if err != nil {
+				return err
+			}
+			if merged.kind != mapNode {
+				return &Error{Line: m.Line, Msg: "\"<<\" expects a mapping or a list of mappings"}
+			}
+			for _, key := range merged.keys {
+				if _, ok := n.fields[key]; !ok {
+					n.keys = append(n.keys, key)
+					n.fields[key] = merged.fields[key]
+				}
+			}
+		}
+	}
+	return nil
+}
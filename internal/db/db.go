@@ -3,6 +3,9 @@ package db
 import (
 	"database/sql"
 	_ "embed"
+	"fmt"
+	"os"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -10,18 +13,162 @@ import (
 //go:embed schema.sql
 var schema string
 
+// column describes a column added to an existing table after its initial
+// release. schema.sql only creates missing tables, so databases created by an
+// older version need these columns added explicitly.
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+// columns lists every column added since the initial schema, in order.
+var columns = []column{
+	{"jobs", "score", "REAL DEFAULT 0"},
+	{"jobs", "score_breakdown", "TEXT"},
+	{"jobs", "season", "TEXT"},
+	{"jobs", "year", "INTEGER"},
+	{"jobs", "degree_level", "TEXT"},
+	{"jobs", "role_family", "TEXT"},
+	{"jobs", "employment_type", "TEXT"},
+	{"jobs", "classified", "BOOLEAN DEFAULT FALSE"},
+	{"jobs", "salary_currency", "TEXT"},
+	{"jobs", "salary_min", "REAL"},
+	{"jobs", "salary_max", "REAL"},
+	{"jobs", "salary_unit", "TEXT"},
+	{"jobs", "hourly_min", "REAL"},
+	{"jobs", "hourly_max", "REAL"},
+	{"jobs", "canonical_url", "TEXT"},
+	{"jobs", "posting_id", "TEXT"},
+	{"jobs", "title_key", "TEXT"},
+	{"jobs", "duplicate_of", "INTEGER REFERENCES jobs(id)"},
+	{"jobs", "content_hash", "TEXT"},
+	{"jobs", "watched", "BOOLEAN DEFAULT FALSE"},
+	{"jobs", "snapshot_html", "TEXT"},
+	{"jobs", "snapshot_text", "TEXT"},
+	{"jobs", "snapshot_size", "INTEGER"},
+	{"jobs", "snapshot_at", "DATETIME"},
+	{"company_runs", "fingerprint", "TEXT"},
+	{"company_runs", "challenge", "BOOLEAN DEFAULT FALSE"},
+	{"companies", "schedule", "TEXT"},
+	{"companies", "active_from", "TEXT"},
+	{"companies", "active_until", "TEXT"},
+	{"companies", "source_type", "TEXT"},
+	{"companies", "selectors", "TEXT"},
+	{"companies", "source_id", "TEXT"},
+	{"jobs", "source", "TEXT"},
+	{"jobs", "compensation_parsed", "BOOLEAN DEFAULT FALSE"},
+}
+
+// indexes lists indexes on columns from the columns list. They are created
+// after addColumns because the columns may not exist when schema.sql runs.
+var indexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_jobs_canonical_url ON jobs(canonical_url)",
+	"CREATE INDEX IF NOT EXISTS idx_jobs_company_posting ON jobs(company, posting_id)",
+}
+
+// busyTimeout is how long, in milliseconds, a connection waits for another
+// connection's write to finish before failing with SQLITE_BUSY. The server
+// and CLI commands such as "scrape" write to the same file at once.
+const busyTimeout = 5000
+
+// dsn returns the data source name opening path with a busy timeout and
+// write-ahead logging, which lets readers go on while another process
+// writes.
+func dsn(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%s_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", path, sep, busyTimeout)
+}
+
 // New creates a new SQLite database connection and runs migrations.
 func New(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", dsn(path))
 	if err != nil {
 		return nil, err
 	}
-
-	// Run migrations
-	if _, err := db.Exec(schema); err != nil {
+	if err := Migrate(db); err != nil {
 		db.Close()
 		return nil, err
 	}
-
 	return db, nil
 }
+
+// Migrate brings the schema of a database up to date. It is safe to run
+// repeatedly.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	if err := addColumns(db); err != nil {
+		return err
+	}
+	for _, stmt := range indexes {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backup writes a consistent copy of the database to path, which must not
+// exist yet. It is safe while the server is running.
+func Backup(db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	_, err := db.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+// Vacuum rebuilds the database file, reclaiming the space of deleted rows.
+func Vacuum(db *sql.DB) error {
+	_, err := db.Exec(`VACUUM`)
+	return err
+}
+
+// addColumns adds any columns from the columns list that are missing.
+func addColumns(db *sql.DB) error {
+	for _, c := range columns {
+		exists, err := hasColumn(db, c.table, c.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", c.table, c.name, err)
+		}
+	}
+	return nil
+}
+
+func hasColumn(db *sql.DB, table, name string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			colName   string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if colName == name {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
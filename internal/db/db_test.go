@@ -3,7 +3,9 @@ package db
 import (
 	"database/sql"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -85,3 +87,134 @@ func TestDB_Implements_SQLDB(t *testing.T) {
 		Close() error
 	} = (*sql.DB)(nil)
 }
+
+func TestNewDB_AddsColumnsToExistingTables(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	// Simulate a database created before the columns were added
+	old, err := sql.Open("sqlite", tmpFile.Name())
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = old.Exec(`CREATE TABLE jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company TEXT NOT NULL,
+		title TEXT NOT NULL,
+		url TEXT UNIQUE NOT NULL,
+		location TEXT,
+		discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		notified BOOLEAN DEFAULT FALSE
+	)`)
+	old.Close()
+	if err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+
+	database, err := New(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	for _, c := range columns {
+		exists, err := hasColumn(database, c.table, c.name)
+		if err != nil {
+			t.Fatalf("failed to inspect %s: %v", c.table, err)
+		}
+		if !exists {
+			t.Errorf("expected column %s.%s to be added", c.table, c.name)
+		}
+	}
+	database.Close()
+
+	// Opening again must be a no-op
+	database, err = New(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	database.Close()
+}
+
+func TestBackupAndVacuum(t *testing.T) {
+	dir := t.TempDir()
+	database, err := New(dir + "/jobs.db")
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer database.Close()
+	if _, err := database.Exec(`INSERT INTO companies (name, career_url) VALUES ('Acme', 'https://acme.com')`); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	if err := Vacuum(database); err != nil {
+		t.Fatalf("vacuum failed: %v", err)
+	}
+
+	backup := dir + "/backup.db"
+	if err := Backup(database, backup); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if err := Backup(database, backup); err == nil {
+		t.Error("expected an error when the backup file exists")
+	}
+
+	restored, err := New(backup)
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer restored.Close()
+	var name string
+	if err := restored.QueryRow(`SELECT name FROM companies`).Scan(&name); err != nil || name != "Acme" {
+		t.Errorf("expected the backup to contain the company, got %q, %v", name, err)
+	}
+}
+
+func TestNew_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	server, err := New(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer server.Close()
+	// A second process, such as a CLI command run from cron.
+	cli, err := New(path)
+	if err != nil {
+		t.Fatalf("failed to open database again: %v", err)
+	}
+	defer cli.Close()
+
+	var mode string
+	if err := server.QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("expected write-ahead logging, got %q (%v)", mode, err)
+	}
+
+	tx, err := server.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO config (key, value) VALUES ('a', '1')`); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := cli.Exec(`INSERT INTO config (key, value) VALUES ('b', '2')`)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected the second writer to wait for the first, got %v", err)
+	}
+
+	var n int
+	server.QueryRow(`SELECT COUNT(*) FROM config`).Scan(&n)
+	if n != 2 {
+		t.Errorf("expected both writes, got %d rows", n)
+	}
+}
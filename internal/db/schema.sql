@@ -7,9 +7,70 @@ CREATE TABLE IF NOT EXISTS jobs (
     url TEXT UNIQUE NOT NULL,
     location TEXT,
     discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    notified BOOLEAN DEFAULT FALSE
+    notified BOOLEAN DEFAULT FALSE,
+    score REAL DEFAULT 0,
+    score_breakdown TEXT,
+    season TEXT,
+    year INTEGER,
+    degree_level TEXT,
+    role_family TEXT,
+    employment_type TEXT,
+    classified BOOLEAN DEFAULT FALSE,
+    salary_currency TEXT,
+    salary_min REAL,
+    salary_max REAL,
+    salary_unit TEXT,
+    hourly_min REAL,
+    hourly_max REAL,
+    canonical_url TEXT,
+    posting_id TEXT,
+    title_key TEXT,
+    duplicate_of INTEGER REFERENCES jobs(id),
+    content_hash TEXT,
+    watched BOOLEAN DEFAULT FALSE,
+    snapshot_html TEXT,
+    snapshot_text TEXT,
+    snapshot_size INTEGER,
+    snapshot_at DATETIME,
+    source TEXT,
+    compensation_parsed BOOLEAN DEFAULT FALSE
 );
 
+-- Normalized locations parsed from each job's location string
+CREATE TABLE IF NOT EXISTS job_locations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    raw TEXT,
+    city TEXT,
+    region TEXT,
+    country TEXT,
+    remote BOOLEAN DEFAULT FALSE,
+    hybrid BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_locations_job ON job_locations(job_id);
+
+-- Information fetched from each job's detail page
+CREATE TABLE IF NOT EXISTS job_details (
+    job_id INTEGER PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    description TEXT,
+    requirements TEXT,
+    posted_at DATETIME,
+    deadline DATETIME,
+    salary TEXT,
+    fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Changes to postings seen on later scrapes
+CREATE TABLE IF NOT EXISTS job_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    changes TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_revisions_job ON job_revisions(job_id);
+
 CREATE TABLE IF NOT EXISTS notifications (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     job_id INTEGER REFERENCES jobs(id),
@@ -29,6 +90,12 @@ CREATE TABLE IF NOT EXISTS companies (
     career_url TEXT NOT NULL,
     search_term TEXT DEFAULT 'intern',
     enabled BOOLEAN DEFAULT TRUE,
+    schedule TEXT,
+    active_from TEXT,
+    active_until TEXT,
+    source_type TEXT,
+    selectors TEXT,
+    source_id TEXT,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -45,9 +112,37 @@ CREATE TABLE IF NOT EXISTS run_logs (
     error_message TEXT
 );
 
--- Insert default companies if not exists
-INSERT OR IGNORE INTO companies (name, career_url, search_term) VALUES 
-    ('Google', 'https://www.google.com/about/careers/applications/jobs/results?q=software+intern&location=United+States', 'intern'),
-    ('Amazon', 'https://www.amazon.jobs/en/search?base_query=software+intern&loc_query=United+States', 'intern'),
-    ('Uber', 'https://www.uber.com/us/en/careers/list/?query=intern%20software&location=USA', 'intern'),
-    ('DoorDash', 'https://careers.doordash.com/jobs/search?query=intern', 'intern');
+-- Outcome of each company's scrape in each run
+CREATE TABLE IF NOT EXISTS company_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id INTEGER REFERENCES run_logs(id),
+    company_id INTEGER,
+    company TEXT NOT NULL,
+    run_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    status TEXT NOT NULL,
+    http_status INTEGER,
+    bytes INTEGER DEFAULT 0,
+    pages INTEGER DEFAULT 0,
+    jobs_found INTEGER DEFAULT 0,
+    new_jobs INTEGER DEFAULT 0,
+    duration_ms INTEGER DEFAULT 0,
+    error_class TEXT,
+    error_message TEXT,
+    fingerprint TEXT,
+    challenge BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_runs_company ON company_runs(company_id, run_at);
+
+-- Scrape anomalies, open until a later scrape no longer shows them
+CREATE TABLE IF NOT EXISTS incidents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    company_id INTEGER,
+    company TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    detail TEXT,
+    opened_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    resolved_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_company ON incidents(company_id, resolved_at);
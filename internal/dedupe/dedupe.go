@@ -0,0 +1,251 @@
+package dedupe
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"intern-job-tracker/internal/model"
+)
+
+// trackingParams are query parameters that identify a visit rather than a
+// posting, and are dropped from canonical URLs.
+var trackingParams = map[string]bool{
+	"gh_src": true, "gh_source": true, "source": true, "src": true, "ref": true, "referrer": true,
+	"lever-source": true, "lever-origin": true, "lever-via": true,
+	"sessionid": true, "session_id": true, "jsessionid": true, "sid": true, "phpsessid": true,
+	"fbclid": true, "gclid": true, "msclkid": true, "mc_cid": true, "mc_eid": true,
+	"_ga": true, "_gl": true, "trk": true, "trackingid": true, "refid": true,
+}
+
+// localePrefix matches a leading locale path segment such as "/en", "/en-us"
+// or "/us/en".
+var localePrefix = regexp.MustCompile(`^/(?:[a-z]{2}(?:[-_][a-z]{2})?/)?[a-z]{2}(?:[-_][a-z]{2})?(/|$)`)
+
+// knownLocales limits locale stripping to real language codes so paths like
+// "/jobs" or "/us/careers" are left alone.
+var knownLocales = map[string]bool{
+	"en": true, "fr": true, "de": true, "es": true, "it": true, "pt": true, "nl": true, "ja": true,
+	"zh": true, "ko": true, "pl": true, "sv": true, "da": true, "fi": true, "no": true, "he": true,
+}
+
+// CanonicalURL normalizes a job URL so the same posting reached through
+// different links compares equal. It lowercases the host, drops "www.",
+// default ports, fragments, tracking and session parameters, locale prefixes
+// and trailing slashes, and sorts the remaining query parameters. The rest of
+// the path keeps its case, as job boards may tell postings apart by it.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(raw)
+	}
+
+	u.Scheme = "https"
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	path := stripLocale(u.EscapedPath())
+	path = strings.ReplaceAll(path, "//", "/")
+	path = strings.TrimRight(path, "/")
+	u.RawPath = ""
+	u.Path, _ = url.PathUnescape(path)
+
+	query := u.Query()
+	for key := range query {
+		k := strings.ToLower(key)
+		if trackingParams[k] || strings.HasPrefix(k, "utm_") {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode() // Encode sorts by key
+
+	return u.String()
+}
+
+// stripLocale removes leading locale segments in any case, e.g.
+// "/en-US/jobs/1" and "/us/en/jobs/1" both become "/jobs/1".
+func stripLocale(path string) string {
+	// Escaped paths are ASCII, so lowercasing keeps byte offsets.
+	m := localePrefix.FindString(strings.ToLower(path))
+	if m == "" {
+		return path
+	}
+	segments := strings.Split(strings.Trim(m, "/"), "/")
+	lang := segments[len(segments)-1]
+	if !knownLocales[lang[:2]] {
+		return path
+	}
+	return "/" + strings.TrimPrefix(path[len(m):], "/")
+}
+
+var (
+	// idParams name query parameters that carry a posting ID.
+	idParams = []string{"gh_jid", "jobid", "job_id", "jid", "reqid", "req_id", "requisitionid", "postingid", "id"}
+	// idSegment matches path segments that look like posting IDs: long
+	// numbers, requisition numbers such as "R12345" or "JR-1234", and UUIDs.
+	idSegment = regexp.MustCompile(`(?i)^(?:\d{5,}|j?r-?\d{4,}|req-?\d{4,}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+	// trailingID matches an ID at the end of a slug, as in Workday's
+	// "software-engineer-intern_R12345".
+	trailingID = regexp.MustCompile(`(?i)[_-]((?:j?r|req)-?\d{4,}|\d{5,})$`)
+)
+
+// PostingID extracts the employer's posting or requisition ID from a job URL.
+// Returns "" if the URL carries no recognizable ID.
+func PostingID(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	// Try the parameters in idParams order so a URL always gives the same
+	// ID. Keys are sorted to settle ones differing only in case.
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, p := range idParams {
+		for _, key := range keys {
+			if values := query[key]; strings.EqualFold(key, p) && len(values) > 0 && values[0] != "" {
+				return strings.ToLower(values[0])
+			}
+		}
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if idSegment.MatchString(seg) {
+			return normalizeID(seg)
+		}
+		if m := trailingID.FindStringSubmatch(seg); m != nil {
+			return normalizeID(m[1])
+		}
+	}
+	return ""
+}
+
+func normalizeID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+var nonAlnum = regexp.MustCompile(`[^\pL\pN]+`)
+
+// TitleKey normalizes a job title for comparison: lowercase words separated
+// by single spaces, without punctuation.
+func TitleKey(title string) string {
+	return strings.TrimSpace(nonAlnum.ReplaceAllString(strings.ToLower(title), " "))
+}
+
+// Similarity returns the Jaccard similarity of two title keys' word sets,
+// from 0 (no words in common) to 1 (the same words).
+func Similarity(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+func words(key string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(key) {
+		set[w] = true
+	}
+	return set
+}
+
+// SimilarityThreshold is the title similarity at which two postings from
+// the same company without conflicting posting IDs are considered the same.
+const SimilarityThreshold = 0.85
+
+// Candidate is a stored posting that a new posting may duplicate.
+type Candidate struct {
+	ID        int64
+	PostingID string
+	TitleKey  string
+}
+
+// FindDuplicate returns the ID of the candidate the posting duplicates, or 0.
+// Postings with the same posting ID are duplicates. Otherwise postings whose
+// titles are similar enough are duplicates, unless both carry posting IDs
+// that differ. Candidates are expected to be from the same company.
+func FindDuplicate(postingID, titleKey string, candidates []Candidate) int64 {
+	if postingID != "" {
+		for _, c := range candidates {
+			if c.PostingID == postingID {
+				return c.ID
+			}
+		}
+	}
+
+	type scored struct {
+		id    int64
+		score float64
+	}
+	var matches []scored
+	for _, c := range candidates {
+		if postingID != "" && c.PostingID != "" && c.PostingID != postingID {
+			continue
+		}
+		if s := Similarity(titleKey, c.TitleKey); s >= SimilarityThreshold {
+			matches = append(matches, scored{c.ID, s})
+		}
+	}
+	if len(matches) == 0 {
+		return 0
+	}
+	// Best match first; the oldest posting wins ties.
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].id < matches[j].id
+	})
+	return matches[0].id
+}
+
+// Store is the job storage needed to back-fill duplicate detection keys.
+type Store interface {
+	GetWithoutDedupeKeys() ([]*model.Job, error)
+	FindDuplicate(job *model.Job) (int64, error)
+	UpdateDedupeKeys(job *model.Job) error
+}
+
+// Backfill computes duplicate detection keys for every job stored before
+// duplicate detection existed, oldest first, linking each to an earlier job
+// it duplicates. Returns the number of duplicates found.
+func Backfill(store Store) (int, error) {
+	jobs, err := store.GetWithoutDedupeKeys()
+	if err != nil {
+		return 0, err
+	}
+	duplicates := 0
+	for _, job := range jobs {
+		if job.DuplicateOf, err = store.FindDuplicate(job); err != nil {
+			return duplicates, err
+		}
+		if err := store.UpdateDedupeKeys(job); err != nil {
+			return duplicates, err
+		}
+		if job.DuplicateOf != 0 {
+			duplicates++
+		}
+	}
+	return duplicates, nil
+}
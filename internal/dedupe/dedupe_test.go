@@ -0,0 +1,150 @@
+package dedupe
+
+import (
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{
+			"https://boards.greenhouse.io/acme/jobs/4012345?gh_src=abc&utm_source=linkedin",
+			"https://boards.greenhouse.io/acme/jobs/4012345",
+		},
+		{
+			"http://WWW.Example.com:443/en-us/careers/job/123/?utm_medium=email#apply",
+			"https://example.com/careers/job/123",
+		},
+		{
+			"https://www.uber.com/us/en/careers/list/130567/",
+			"https://uber.com/careers/list/130567",
+		},
+		{
+			"https://jobs.example.com/job?id=9&sessionid=xyz&lang=en",
+			"https://jobs.example.com/job?lang=en&id=9",
+		},
+	}
+
+	for _, tt := range tests {
+		if CanonicalURL(tt.a) != CanonicalURL(tt.b) {
+			t.Errorf("expected %q and %q to be canonically equal, got %q and %q",
+				tt.a, tt.b, CanonicalURL(tt.a), CanonicalURL(tt.b))
+		}
+	}
+
+	if got := CanonicalURL("https://example.com/us/careers"); got != "https://example.com/us/careers" {
+		t.Errorf("expected non-locale path to be kept, got %q", got)
+	}
+	if CanonicalURL("https://example.com/jobs?id=1") == CanonicalURL("https://example.com/jobs?id=2") {
+		t.Error("expected different postings to stay distinct")
+	}
+	if CanonicalURL("https://jobs.example.com/acme/AbC") == CanonicalURL("https://jobs.example.com/acme/aBc") {
+		t.Error("expected paths differing in case to stay distinct")
+	}
+	if got := CanonicalURL("https://Example.com/EN-us/Jobs/AbC/"); got != "https://example.com/Jobs/AbC" {
+		t.Errorf("expected only the host and locale to be normalized, got %q", got)
+	}
+}
+
+func TestPostingID(t *testing.T) {
+	tests := map[string]string{
+		"https://acme.com/careers?gh_jid=4012345":                                         "4012345",
+		"https://boards.greenhouse.io/acme/jobs/4012345":                                  "4012345",
+		"https://jobs.lever.co/acme/5b2c1a3e-1234-4abc-9def-0123456789ab":                 "5b2c1a3e12344abc9def0123456789ab",
+		"https://acme.wd5.myworkdayjobs.com/en-US/External/job/Seattle/SWE-Intern_R12345": "r12345",
+		"https://acme.com/jobs/JR-20931/software-intern":                                  "jr20931",
+		"https://acme.com/careers/software-intern":                                        "",
+	}
+	for raw, want := range tests {
+		if got := PostingID(raw); got != want {
+			t.Errorf("PostingID(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	// The more specific parameter wins, on every call.
+	for i := 0; i < 100; i++ {
+		if got := PostingID("https://acme.com/careers?id=abc&GH_JID=4567890&jobid=777"); got != "4567890" {
+			t.Fatalf("call %d: expected gh_jid to take priority, got %q", i, got)
+		}
+	}
+}
+
+func TestTitleKeyAndSimilarity(t *testing.T) {
+	a := TitleKey("Software Engineer Intern, Summer 2027")
+	b := TitleKey("Software Engineer Intern - Summer 2027")
+	if a != b {
+		t.Errorf("expected equal keys, got %q and %q", a, b)
+	}
+	if s := Similarity(a, b); s != 1 {
+		t.Errorf("expected similarity 1, got %v", s)
+	}
+	if s := Similarity(TitleKey("SWE Intern - Backend"), TitleKey("SWE Intern - Frontend")); s >= SimilarityThreshold {
+		t.Errorf("expected different specializations to differ, got %v", s)
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, PostingID: "4012345", TitleKey: "software engineer intern"},
+		{ID: 2, PostingID: "", TitleKey: "data science intern summer 2027"},
+		{ID: 3, PostingID: "999999", TitleKey: "hardware intern"},
+	}
+
+	tests := []struct {
+		name      string
+		postingID string
+		title     string
+		want      int64
+	}{
+		{"same posting ID", "4012345", "Software Engineer Intern (Greenhouse)", 1},
+		{"same title per location", "", "Data Science Intern, Summer 2027", 2},
+		{"similar title conflicting ID", "888888", "Hardware Intern", 0},
+		{"different role", "", "Product Manager Intern", 0},
+	}
+	for _, tt := range tests {
+		if got := FindDuplicate(tt.postingID, TitleKey(tt.title), candidates); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+type memoryStore struct {
+	jobs []*model.Job
+}
+
+func (m *memoryStore) GetWithoutDedupeKeys() ([]*model.Job, error) {
+	return m.jobs, nil
+}
+
+func (m *memoryStore) FindDuplicate(job *model.Job) (int64, error) {
+	var candidates []Candidate
+	for _, j := range m.jobs {
+		if j.ID < job.ID && j.DuplicateOf == 0 {
+			candidates = append(candidates, Candidate{ID: j.ID, PostingID: PostingID(j.URL), TitleKey: TitleKey(j.Title)})
+		}
+	}
+	return FindDuplicate(PostingID(job.URL), TitleKey(job.Title), candidates), nil
+}
+
+func (m *memoryStore) UpdateDedupeKeys(job *model.Job) error {
+	return nil
+}
+
+func TestBackfill(t *testing.T) {
+	store := &memoryStore{jobs: []*model.Job{
+		{ID: 1, Title: "SWE Intern", URL: "https://acme.com/jobs/12345"},
+		{ID: 2, Title: "SWE Intern (Remote)", URL: "https://acme.com/apply?jobid=12345"},
+		{ID: 3, Title: "Data Intern", URL: "https://acme.com/jobs/67890"},
+	}}
+
+	n, err := Backfill(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || store.jobs[1].DuplicateOf != 1 || store.jobs[2].DuplicateOf != 0 {
+		t.Errorf("expected job 2 linked to job 1, got %d duplicates: %+v", n, store.jobs)
+	}
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published by the scheduler.
+const (
+	RunStarted         = "run_started"
+	RunFinished        = "run_finished"
+	CompanyStarted     = "company_started"
+	CompanyFinished    = "company_finished"
+	JobFound           = "job_found"
+	NotificationSent   = "notification_sent"
+	NotificationFailed = "notification_failed"
+)
+
+// Event is something that happened during a run.
+type Event struct {
+	Type  string      `json:"type"`
+	RunID int64       `json:"run_id,omitempty"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// subscriberBuffer is how many events a subscriber can fall behind before
+// further events are dropped for it.
+const subscriberBuffer = 64
+
+// Bus delivers published events to every subscriber. Publishing never blocks:
+// a subscriber that is not keeping up misses events rather than holding up
+// the run.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+// NewBus creates an event bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan Event]struct{})}
+}
+
+// Publish sends an event to all current subscribers, setting its time if
+// unset.
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving events published from now on, and a
+// function that unsubscribes and closes the channel. Once the bus is closed,
+// the channel is returned closed.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		close(ch)
+	} else {
+		b.subs[ch] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close closes every subscriber's channel, ending their streams, and drops
+// events published afterwards.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,61 @@
+package events
+
+import "testing"
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	a, unsubscribeA := bus.Subscribe()
+	b, unsubscribeB := bus.Subscribe()
+	defer unsubscribeB()
+
+	bus.Publish(Event{Type: RunStarted, RunID: 1})
+
+	for _, ch := range []<-chan Event{a, b} {
+		e := <-ch
+		if e.Type != RunStarted || e.RunID != 1 || e.Time.IsZero() {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+
+	unsubscribeA()
+	unsubscribeA()
+	if _, ok := <-a; ok {
+		t.Error("expected channel to be closed after unsubscribing")
+	}
+	bus.Publish(Event{Type: RunFinished, RunID: 1})
+	if e := <-b; e.Type != RunFinished {
+		t.Errorf("expected remaining subscriber to get events, got %+v", e)
+	}
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	// Publishing more than the buffer holds must not block.
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.Publish(Event{Type: JobFound})
+	}
+	if len(ch) != subscriberBuffer {
+		t.Errorf("expected %d buffered events, got %d", subscriberBuffer, len(ch))
+	}
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := NewBus()
+	ch, unsubscribe := bus.Subscribe()
+
+	bus.Close()
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed with the bus")
+	}
+	unsubscribe()
+	bus.Publish(Event{Type: RunStarted})
+
+	late, unsubscribeLate := bus.Subscribe()
+	defer unsubscribeLate()
+	if _, ok := <-late; ok {
+		t.Error("expected subscribing to a closed bus to return a closed channel")
+	}
+}
@@ -0,0 +1,139 @@
+package heartbeat
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Store provides the time of the last successful run.
+type Store interface {
+	// GetLastSuccess returns when the last successful run happened, or nil
+	// if there has been none.
+	GetLastSuccess() (*time.Time, error)
+}
+
+// Notifier sends an alert message.
+type Notifier interface {
+	Send(recipient string, message string) error
+}
+
+// Monitor is a dead man's switch: it alerts when no successful run has been
+// recorded within a window, for example because the machine slept through
+// the scheduled time. It alerts once per missed window and again only after a
+// successful run has been recorded and then missed.
+type Monitor struct {
+	store    Store
+	notifier Notifier
+	window   time.Duration
+	started  time.Time
+
+	mu        sync.Mutex
+	recipient string
+	alerted   *time.Time // last success at the time of the last alert
+	paused    func() bool
+	wasPaused bool
+	resumed   time.Time // when checks were last seen to have resumed
+}
+
+// New creates a monitor alerting when no run succeeds within window.
+func New(store Store, notifier Notifier, recipient string, window time.Duration) *Monitor {
+	return &Monitor{
+		store:     store,
+		notifier:  notifier,
+		recipient: recipient,
+		window:    window,
+		started:   time.Now(),
+	}
+}
+
+// Check alerts if the last successful run is older than the window. Without
+// any successful run, the window counts from when the monitor was created.
+// It reports whether an alert was sent.
+func (m *Monitor) Check(now time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused != nil && m.paused() {
+		m.wasPaused = true
+		return false, nil
+	}
+	if m.wasPaused {
+		m.wasPaused = false
+		m.resumed = now
+	}
+
+	last, err := m.store.GetLastSuccess()
+	if err != nil {
+		return false, err
+	}
+
+	since := m.started
+	if last != nil {
+		since = *last
+	}
+	if m.resumed.After(since) {
+		since = m.resumed
+	}
+	if now.Sub(since) <= m.window {
+		return false, nil
+	}
+
+	if m.alerted != nil && m.alerted.Equal(since) {
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("💤 No successful job check in %s\n\n", formatDuration(now.Sub(since)))
+	if last != nil {
+		msg += fmt.Sprintf("Last success: %s", last.Format("2006-01-02 15:04"))
+	} else {
+		msg += "No run has succeeded yet."
+	}
+	if err := m.notifier.Send(m.recipient, msg); err != nil {
+		return false, err
+	}
+	m.alerted = &since
+	return true, nil
+}
+
+// SetRecipient changes who alerts are sent to.
+func (m *Monitor) SetRecipient(recipient string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recipient = recipient
+}
+
+// SetPausedFunc makes the monitor stay quiet while paused returns true, as
+// when scheduled checks are paused on purpose. After a pause, the window
+// counts from when the monitor first sees checks resumed.
+func (m *Monitor) SetPausedFunc(paused func() bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = paused
+}
+
+// Start checks every interval until stop is closed.
+func (m *Monitor) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if sent, err := m.Check(now); err != nil {
+				slog.Warn("heartbeat check failed", "error", err)
+			} else if sent {
+				slog.Warn("sent missed heartbeat alert")
+			}
+		}
+	}
+}
+
+// formatDuration formats a duration in whole hours, or minutes when shorter.
+func formatDuration(d time.Duration) string {
+	if d < time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return fmt.Sprintf("%dh", int(d.Hours()))
+}
@@ -0,0 +1,94 @@
+package heartbeat
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockStore struct {
+	last *time.Time
+}
+
+func (m *mockStore) GetLastSuccess() (*time.Time, error) {
+	return m.last, nil
+}
+
+type mockNotifier struct {
+	messages []string
+}
+
+func (m *mockNotifier) Send(recipient, message string) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func TestMonitor_Check(t *testing.T) {
+	lastRun := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	store := &mockStore{last: &lastRun}
+	notifier := &mockNotifier{}
+	m := New(store, notifier, "+1234567890", 26*time.Hour)
+
+	if sent, _ := m.Check(lastRun.Add(25 * time.Hour)); sent {
+		t.Error("expected no alert within the window")
+	}
+	if sent, _ := m.Check(lastRun.Add(30 * time.Hour)); !sent {
+		t.Fatal("expected an alert once the window passed")
+	}
+	if !strings.Contains(notifier.messages[0], "30h") || !strings.Contains(notifier.messages[0], "2026-10-01 09:00") {
+		t.Errorf("unexpected message %q", notifier.messages[0])
+	}
+	if sent, _ := m.Check(lastRun.Add(40 * time.Hour)); sent {
+		t.Error("expected one alert per missed window")
+	}
+
+	// A later success that is missed again alerts again.
+	next := lastRun.Add(48 * time.Hour)
+	store.last = &next
+	if sent, _ := m.Check(next.Add(27 * time.Hour)); !sent {
+		t.Error("expected a new alert after the next success was missed")
+	}
+	if len(notifier.messages) != 2 {
+		t.Errorf("expected 2 alerts, got %d", len(notifier.messages))
+	}
+}
+
+func TestMonitor_CheckNoRuns(t *testing.T) {
+	notifier := &mockNotifier{}
+	m := New(&mockStore{}, notifier, "", time.Hour)
+
+	if sent, _ := m.Check(m.started.Add(30 * time.Minute)); sent {
+		t.Error("expected no alert within the window of starting")
+	}
+	if sent, _ := m.Check(m.started.Add(2 * time.Hour)); !sent {
+		t.Fatal("expected an alert when no run succeeded after starting")
+	}
+	if !strings.Contains(notifier.messages[0], "No run has succeeded yet") {
+		t.Errorf("unexpected message %q", notifier.messages[0])
+	}
+}
+
+func TestMonitor_CheckPaused(t *testing.T) {
+	lastRun := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	notifier := &mockNotifier{}
+	m := New(&mockStore{last: &lastRun}, notifier, "+1234567890", 26*time.Hour)
+	paused := true
+	m.SetPausedFunc(func() bool { return paused })
+
+	if sent, _ := m.Check(lastRun.Add(72 * time.Hour)); sent {
+		t.Error("expected no alert while paused")
+	}
+
+	// After resuming, the window counts from the resume.
+	paused = false
+	resumed := lastRun.Add(96 * time.Hour)
+	if sent, _ := m.Check(resumed); sent {
+		t.Error("expected no alert right after resuming")
+	}
+	if sent, _ := m.Check(resumed.Add(25 * time.Hour)); sent {
+		t.Error("expected no alert within the window after resuming")
+	}
+	if sent, _ := m.Check(resumed.Add(27 * time.Hour)); !sent {
+		t.Error("expected an alert once the window after resuming passed")
+	}
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+// fieldValue is the value of one tracked posting field.
+type fieldValue struct {
+	name  string
+	value string
+}
+
+// values returns the job's tracked fields in the order changes are reported.
+func values(job *model.Job) []fieldValue {
+	d := job.Detail
+	if d == nil {
+		d = &model.JobDetail{}
+	}
+	return []fieldValue{
+		{"title", job.Title},
+		{"location", job.Location},
+		{"deadline", formatDate(d.Deadline)},
+		{"posted_at", formatDate(d.PostedAt)},
+		{"salary", strings.TrimSpace(d.Salary)},
+		{"description", strings.TrimSpace(d.Description)},
+		{"requirements", strings.TrimSpace(d.Requirements)},
+	}
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// Hash returns a hash of the job's tracked fields. Two versions of a posting
+// have the same hash exactly when Diff finds no changes between them.
+func Hash(job *model.Job) string {
+	h := sha256.New()
+	for _, f := range values(job) {
+		h.Write([]byte(f.name))
+		h.Write([]byte{0})
+		h.Write([]byte(f.value))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Diff returns the tracked fields that differ between two versions of a job.
+func Diff(old, new *model.Job) []model.FieldChange {
+	var changes []model.FieldChange
+	before, after := values(old), values(new)
+	for i := range before {
+		if before[i].value != after[i].value {
+			changes = append(changes, model.FieldChange{Field: before[i].name, Old: before[i].value, New: after[i].value})
+		}
+	}
+	return changes
+}
+
+// Store is the job storage needed to back-fill content hashes.
+type Store interface {
+	GetWithoutContentHash() ([]*model.Job, error)
+	UpdateContentHash(id int64, hash string) error
+}
+
+// Backfill stores the content hash of every job saved before change tracking
+// existed, and returns the number of jobs updated.
+func Backfill(store Store) (int, error) {
+	jobs, err := store.GetWithoutContentHash()
+	if err != nil {
+		return 0, err
+	}
+	for i, job := range jobs {
+		if err := store.UpdateContentHash(job.ID, Hash(job)); err != nil {
+			return i, err
+		}
+	}
+	return len(jobs), nil
+}
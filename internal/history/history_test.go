@@ -0,0 +1,52 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestDiff(t *testing.T) {
+	deadline := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	moved := deadline.AddDate(0, 0, 14)
+
+	old := &model.Job{
+		Title: "SWE Intern", Location: "Seattle, WA",
+		Detail: &model.JobDetail{Deadline: &deadline, Description: "Build things."},
+	}
+	updated := &model.Job{
+		Title: "SWE Intern", Location: "Seattle, WA; New York, NY",
+		Detail: &model.JobDetail{Deadline: &moved, Description: "Build things."},
+	}
+
+	changes := Diff(old, updated)
+	want := []model.FieldChange{
+		{Field: "location", Old: "Seattle, WA", New: "Seattle, WA; New York, NY"},
+		{Field: "deadline", Old: "2026-11-01", New: "2026-11-15"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+
+	if Hash(old) == Hash(updated) {
+		t.Error("expected different hashes for changed postings")
+	}
+}
+
+func TestHash_IgnoresUntrackedFields(t *testing.T) {
+	a := &model.Job{Title: "SWE Intern", Score: 3, Detail: &model.JobDetail{FetchedAt: time.Now()}}
+	b := &model.Job{Title: "SWE Intern", Score: 7}
+
+	if Hash(a) != Hash(b) {
+		t.Error("expected score and fetch time not to affect the hash")
+	}
+	if len(Diff(a, b)) != 0 {
+		t.Errorf("expected no changes, got %+v", Diff(a, b))
+	}
+}
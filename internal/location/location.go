@@ -0,0 +1,298 @@
+package location
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"intern-job-tracker/internal/model"
+)
+
+//go:embed data/*.csv
+var data embed.FS
+
+type place struct {
+	city    string
+	region  string
+	country string
+}
+
+// Normalizer splits free-form location strings and resolves each part to a
+// city, region and country using an offline gazetteer.
+type Normalizer struct {
+	countries map[string]string  // lowercase name, alias or code -> country code
+	regions   map[string][]place // lowercase name or code -> candidate regions
+	cities    map[string]place   // lowercase name or alias -> city
+}
+
+// New creates a Normalizer backed by the built-in gazetteer.
+func New() *Normalizer {
+	n := &Normalizer{
+		countries: make(map[string]string),
+		regions:   make(map[string][]place),
+		cities:    make(map[string]place),
+	}
+
+	for _, rec := range mustReadCSV("data/countries.csv") {
+		code, name, aliases := rec[0], rec[1], rec[2]
+		for _, key := range append([]string{code, name}, splitAliases(aliases)...) {
+			n.countries[normalizeKey(key)] = code
+		}
+	}
+	for _, rec := range mustReadCSV("data/regions.csv") {
+		p := place{country: rec[0], region: rec[1]}
+		for _, key := range []string{rec[1], rec[2]} {
+			k := normalizeKey(key)
+			n.regions[k] = append(n.regions[k], p)
+		}
+	}
+	for _, rec := range mustReadCSV("data/cities.csv") {
+		p := place{city: rec[0], region: rec[1], country: rec[2]}
+		for _, key := range append([]string{rec[0]}, splitAliases(rec[3])...) {
+			n.cities[normalizeKey(key)] = p
+		}
+	}
+	return n
+}
+
+func mustReadCSV(name string) [][]string {
+	f, err := data.Open(name)
+	if err != nil {
+		panic(fmt.Sprintf("location: missing gazetteer %s: %v", name, err))
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("location: invalid gazetteer %s: %v", name, err))
+	}
+	return records[1:] // skip header
+}
+
+func splitAliases(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "|")
+}
+
+func normalizeKey(s string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(s), "."))
+}
+
+var (
+	// partSeparator splits a string listing several locations.
+	partSeparator = regexp.MustCompile(`\s*(?:;|\||\n|•|/| or | and )\s*`)
+	remotePattern = regexp.MustCompile(`(?i)\b(remote|anywhere|virtual|work from home|wfh|distributed)\b`)
+	hybridPattern = regexp.MustCompile(`(?i)\bhybrid\b`)
+	// vaguePattern matches placeholders that name no actual place.
+	vaguePattern = regexp.MustCompile(`^(multiple|various|several|many) locations?$`)
+	// noisePattern removes punctuation left behind after stripping keywords.
+	noisePattern = regexp.MustCompile(`[()\[\]]|^\s*[-–:]+\s*|\s*[-–:]+\s*$`)
+)
+
+// Normalize parses a location string such as "Mountain View, CA, USA; New
+// York, NY" or "Remote - US" into its individual locations.
+func (n *Normalizer) Normalize(raw string) []model.Location {
+	var locations []model.Location
+	for _, part := range partSeparator.Split(raw, -1) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		locations = append(locations, n.parsePart(part)...)
+	}
+	return locations
+}
+
+// Enrich normalizes the job's location and stores the result on it.
+func (n *Normalizer) Enrich(job *model.Job) {
+	job.Locations = n.Normalize(job.Location)
+}
+
+// parsePart parses one location segment. A segment may still hold several
+// comma-separated locations, as in "Seattle, WA, New York, NY".
+func (n *Normalizer) parsePart(part string) []model.Location {
+	remote := remotePattern.MatchString(part)
+	hybrid := hybridPattern.MatchString(part)
+	cleaned := remotePattern.ReplaceAllString(part, "")
+	cleaned = hybridPattern.ReplaceAllString(cleaned, "")
+
+	var tokens []string
+	for _, t := range strings.Split(cleaned, ",") {
+		t = strings.TrimSpace(noisePattern.ReplaceAllString(strings.TrimSpace(t), ""))
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+
+	if len(tokens) == 0 {
+		if remote || hybrid {
+			return []model.Location{{Raw: part, Remote: remote, Hybrid: hybrid}}
+		}
+		return nil
+	}
+
+	// Group tokens: each group starts with a place and absorbs following
+	// tokens that qualify it (a region or country).
+	var groups [][]string
+	for _, t := range tokens {
+		if len(groups) > 0 && n.qualifies(t) {
+			groups[len(groups)-1] = append(groups[len(groups)-1], t)
+			continue
+		}
+		groups = append(groups, []string{t})
+	}
+
+	locations := make([]model.Location, 0, len(groups))
+	for _, g := range groups {
+		loc := n.resolve(g)
+		loc.Raw = strings.Join(g, ", ")
+		if len(groups) == 1 {
+			loc.Raw = part
+		}
+		loc.Remote = remote
+		loc.Hybrid = hybrid
+		locations = append(locations, loc)
+	}
+	return locations
+}
+
+// qualifies reports whether a token names a region or country and so
+// qualifies the place before it rather than starting a new location.
+func (n *Normalizer) qualifies(token string) bool {
+	key := normalizeKey(token)
+	if _, ok := n.countries[key]; ok {
+		return true
+	}
+	if _, ok := n.regions[key]; ok {
+		// A full region name that is also a city ("New York") starts a new
+		// location unless written as a code.
+		if _, isCity := n.cities[key]; isCity && len(key) > 2 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// resolve maps a group of tokens such as ["Mountain View", "CA", "USA"] to a
+// location.
+func (n *Normalizer) resolve(tokens []string) model.Location {
+	var loc model.Location
+	first := normalizeKey(tokens[0])
+	if vaguePattern.MatchString(first) {
+		return loc
+	}
+
+	city, known := n.cities[first]
+	for _, q := range tokens[1:] {
+		key := normalizeKey(q)
+		code, isCountry := n.countries[key]
+		region, isRegion := n.lookupRegion(key, loc.Country)
+		// "Toronto, CA" means Canada, not California.
+		if known && isCountry && code == city.country && (!isRegion || region.region != city.region) {
+			isRegion = false
+		}
+		switch {
+		case isRegion && loc.Region == "":
+			loc.Region, loc.Country = region.region, region.country
+		case isCountry:
+			loc.Country = code
+		}
+	}
+
+	switch {
+	case known && n.isCity(first, loc):
+		loc.City = city.city
+		if loc.Region == "" {
+			loc.Region = city.region
+		}
+		if loc.Country == "" {
+			loc.Country = city.country
+		}
+	case len(tokens) == 1 && n.isCountry(first):
+		loc.Country = n.countries[first]
+	case len(tokens) == 1 && n.isRegion(first):
+		p, _ := n.lookupRegion(first, "")
+		loc.Region, loc.Country = p.region, p.country
+	default:
+		// Unknown city: keep the name as written and trust the qualifiers.
+		loc.City = tokens[0]
+	}
+	return loc
+}
+
+func (n *Normalizer) isCity(key string, qualified model.Location) bool {
+	city, ok := n.cities[key]
+	if !ok {
+		return false
+	}
+	// "Cambridge, UK" is not the gazetteer's Cambridge, MA.
+	if qualified.Country != "" && qualified.Country != city.country {
+		return false
+	}
+	if qualified.Region != "" && city.region != "" && qualified.Region != city.region {
+		return false
+	}
+	return true
+}
+
+func (n *Normalizer) isCountry(key string) bool {
+	_, ok := n.countries[key]
+	return ok
+}
+
+func (n *Normalizer) isRegion(key string) bool {
+	_, ok := n.regions[key]
+	return ok
+}
+
+// lookupRegion finds a region by name or code, preferring one in the given
+// country and then the United States when the code is ambiguous.
+func (n *Normalizer) lookupRegion(key, country string) (place, bool) {
+	candidates := n.regions[key]
+	if len(candidates) == 0 {
+		return place{}, false
+	}
+	for _, preferred := range []string{country, "US"} {
+		for _, p := range candidates {
+			if p.country == preferred {
+				return p, true
+			}
+		}
+	}
+	if country != "" {
+		return place{}, false
+	}
+	return candidates[0], true
+}
+
+// Store is the job storage needed to back-fill normalized locations.
+type Store interface {
+	GetWithoutLocations() ([]*model.Job, error)
+	SaveLocations(jobID int64, locations []model.Location) error
+}
+
+// Backfill normalizes the location of every stored job that has a location
+// string but no normalized locations, and returns the number of jobs updated.
+func (n *Normalizer) Backfill(store Store) (int, error) {
+	jobs, err := store.GetWithoutLocations()
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, job := range jobs {
+		locations := n.Normalize(job.Location)
+		if len(locations) == 0 {
+			continue
+		}
+		if err := store.SaveLocations(job.ID, locations); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
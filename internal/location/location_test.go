@@ -0,0 +1,127 @@
+package location
+
+import (
+	"reflect"
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	n := New()
+
+	tests := []struct {
+		raw  string
+		want []model.Location
+	}{
+		{
+			raw: "Mountain View, CA, USA; New York, NY",
+			want: []model.Location{
+				{Raw: "Mountain View, CA, USA", City: "Mountain View", Region: "CA", Country: "US"},
+				{Raw: "New York, NY", City: "New York", Region: "NY", Country: "US"},
+			},
+		},
+		{
+			raw: "Seattle, WA, New York, NY",
+			want: []model.Location{
+				{Raw: "Seattle, WA", City: "Seattle", Region: "WA", Country: "US"},
+				{Raw: "New York, NY", City: "New York", Region: "NY", Country: "US"},
+			},
+		},
+		{
+			raw:  "Remote - US",
+			want: []model.Location{{Raw: "Remote - US", Country: "US", Remote: true}},
+		},
+		{
+			raw:  "Remote",
+			want: []model.Location{{Raw: "Remote", Remote: true}},
+		},
+		{
+			raw:  "Hybrid - San Francisco, CA",
+			want: []model.Location{{Raw: "Hybrid - San Francisco, CA", City: "San Francisco", Region: "CA", Country: "US", Hybrid: true}},
+		},
+		{
+			raw:  "Toronto, CA",
+			want: []model.Location{{Raw: "Toronto, CA", City: "Toronto", Region: "ON", Country: "CA"}},
+		},
+		{
+			raw:  "Indianapolis, IN",
+			want: []model.Location{{Raw: "Indianapolis, IN", City: "Indianapolis", Region: "IN", Country: "US"}},
+		},
+		{
+			raw:  "Bengaluru, India",
+			want: []model.Location{{Raw: "Bengaluru, India", City: "Bangalore", Country: "IN"}},
+		},
+		{
+			raw:  "Cambridge, UK",
+			want: []model.Location{{Raw: "Cambridge, UK", City: "Cambridge", Country: "GB"}},
+		},
+		{
+			raw:  "Seattle",
+			want: []model.Location{{Raw: "Seattle", City: "Seattle", Region: "WA", Country: "US"}},
+		},
+		{
+			raw:  "California",
+			want: []model.Location{{Raw: "California", Region: "CA", Country: "US"}},
+		},
+		{
+			raw:  "Multiple Locations",
+			want: []model.Location{{Raw: "Multiple Locations"}},
+		},
+		{
+			raw:  "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := n.Normalize(tt.raw)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Normalize(%q) =\n  %+v\nwant\n  %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizer_Enrich(t *testing.T) {
+	job := &model.Job{Location: "Austin, TX or Remote"}
+	New().Enrich(job)
+
+	if len(job.Locations) != 2 {
+		t.Fatalf("expected 2 locations, got %+v", job.Locations)
+	}
+	if job.Locations[0].Region != "TX" || !job.Locations[1].Remote {
+		t.Errorf("unexpected locations: %+v", job.Locations)
+	}
+}
+
+type mockStore struct {
+	jobs  []*model.Job
+	saved map[int64][]model.Location
+}
+
+func (m *mockStore) GetWithoutLocations() ([]*model.Job, error) {
+	return m.jobs, nil
+}
+
+func (m *mockStore) SaveLocations(jobID int64, locations []model.Location) error {
+	m.saved[jobID] = locations
+	return nil
+}
+
+func TestNormalizer_Backfill(t *testing.T) {
+	store := &mockStore{
+		jobs: []*model.Job{
+			{ID: 1, Location: "Seattle, WA"},
+			{ID: 2, Location: "  "},
+		},
+		saved: make(map[int64][]model.Location),
+	}
+
+	n, err := New().Backfill(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(store.saved[1]) != 1 {
+		t.Errorf("expected 1 job back-filled, got %d (%+v)", n, store.saved)
+	}
+}
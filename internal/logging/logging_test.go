@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", "warn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger.Info("ignored")
+	logger.Warn("scrape failed", "run_id", 7, "company", "Acme")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected records below warn to be dropped, got %q", buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("expected JSON, got %q", lines[0])
+	}
+	if record["msg"] != "scrape failed" || record["level"] != "WARN" || record["run_id"] != 7.0 || record["company"] != "Acme" {
+		t.Errorf("unexpected record %v", record)
+	}
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "text", "debug")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger.Debug("fetching", "url", "https://example.com")
+	if !strings.Contains(buf.String(), `level=DEBUG msg=fetching url=https://example.com`) {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Error("expected error for an unknown format")
+	}
+	if _, err := New(&bytes.Buffer{}, "text", "loud"); err == nil {
+		t.Error("expected error for an unknown level")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("expected the default logger for a context without one")
+	}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if FromContext(WithLogger(context.Background(), logger)) != logger {
+		t.Error("expected the context's logger")
+	}
+}
@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the metrics the scheduler, scraper and notifier record.
+var Registry = prometheus.NewRegistry()
+
+// Default creates metrics registered with Registry.
+var Default = promauto.With(Registry)
+
+// Handler serves the registry's metrics for Prometheus to scrape.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
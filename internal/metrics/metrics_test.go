@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHandler(t *testing.T) {
+	runs := Default.NewCounterVec(prometheus.CounterOpts{
+		Name: "test_runs_total",
+		Help: "Runs by status.",
+	}, []string{"status"})
+	defer Registry.Unregister(runs)
+	runs.WithLabelValues("success").Inc()
+	runs.WithLabelValues(`we"ird`).Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	for _, want := range []string{
+		"# TYPE test_runs_total counter\n",
+		`test_runs_total{status="success"} 1` + "\n",
+		`test_runs_total{status="we\"ird"} 1` + "\n",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("expected %q in output, got %q", want, w.Body.String())
+		}
+	}
+}
+
+func TestDefault_DuplicateName(t *testing.T) {
+	c := Default.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "A counter."})
+	defer Registry.Unregister(c)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a duplicate name to panic")
+		}
+	}()
+	Default.NewGauge(prometheus.GaugeOpts{Name: "test_total", Help: "A gauge."})
+}
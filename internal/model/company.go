@@ -4,12 +4,36 @@ import "time"
 
 // Company represents a company to track for job listings.
 type Company struct {
-	ID         int64     `json:"id"`
-	Name       string    `json:"name"`
-	CareerURL  string    `json:"career_url"`
-	SearchTerm string    `json:"search_term"`
-	Enabled    bool      `json:"enabled"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	CareerURL  string `json:"career_url"`
+	SearchTerm string `json:"search_term"`
+	Enabled    bool   `json:"enabled"`
+	// Schedule is how often the company is checked: a cron expression, or an
+	// interval such as "15m". Empty means the global schedule.
+	Schedule string `json:"schedule,omitempty"`
+	// ActiveFrom and ActiveUntil limit checks to a date range, as inclusive
+	// YYYY-MM-DD dates. Empty means unbounded.
+	ActiveFrom  string `json:"active_from,omitempty"`
+	ActiveUntil string `json:"active_until,omitempty"`
+	// SourceType is how jobs are read, such as "html" for links on the
+	// career page or "greenhouse" for a Greenhouse board. Empty means
+	// "html".
+	SourceType string `json:"source_type,omitempty"`
+	// SourceID identifies the company's board on its platform, such as a
+	// Greenhouse board token or a Workday "host/tenant/site".
+	SourceID string `json:"source_id,omitempty"`
+	// Selectors narrow the career page to its job listings, written as
+	// key=selector pairs such as "job=li.opening,title=h3". Empty means
+	// every link whose text contains the search term.
+	Selectors string    `json:"selectors,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActiveOn reports whether the company is checked on the day of t.
+func (c *Company) ActiveOn(t time.Time) bool {
+	day := t.Format("2006-01-02")
+	return (c.ActiveFrom == "" || day >= c.ActiveFrom) && (c.ActiveUntil == "" || day <= c.ActiveUntil)
 }
 
 // RunLog represents a record of a job check execution.
@@ -24,3 +48,94 @@ type RunLog struct {
 	Status            string    `json:"status"`
 	ErrorMessage      string    `json:"error_message,omitempty"`
 }
+
+// Run states.
+const (
+	RunQueued      = "queued"
+	RunRunning     = "running"
+	RunDone        = "done"
+	RunFailed      = "failed"
+	RunCancelled   = "cancelled"
+	RunInterrupted = "interrupted" // by shutdown
+)
+
+// Run tracks a job check from the moment it is requested until it finishes.
+// Once finished, RunLogID refers to the run log it produced.
+type Run struct {
+	ID             int64      `json:"id"`
+	State          string     `json:"state"`
+	Trigger        string     `json:"trigger"` // manual or scheduled
+	QueuedAt       time.Time  `json:"queued_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	CompaniesTotal int        `json:"companies_total"`
+	CompaniesDone  int        `json:"companies_done"`
+	Company        string     `json:"company,omitempty"` // company being checked
+	JobsFound      int        `json:"jobs_found"`
+	NewJobs        int        `json:"new_jobs"`
+	RunLogID       int64      `json:"run_log_id,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// Scheduler states.
+const (
+	SchedulerRunning = "running"
+	SchedulerPaused  = "paused"
+	SchedulerStopped = "stopped"
+)
+
+// SchedulerStatus describes the global schedule and the active run.
+type SchedulerStatus struct {
+	State       string     `json:"state"`
+	Schedule    string     `json:"schedule"`
+	Timezone    string     `json:"timezone"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	PreviousRun *time.Time `json:"previous_run,omitempty"` // when the schedule last fired
+	ActiveRun   *Run       `json:"active_run,omitempty"`
+}
+
+// CompanyRun records how one company's scrape went during a run.
+type CompanyRun struct {
+	ID           int64     `json:"id"`
+	RunID        int64     `json:"run_id"`
+	CompanyID    int64     `json:"company_id"`
+	Company      string    `json:"company"`
+	RunAt        time.Time `json:"run_at"`
+	Status       string    `json:"status"` // success or error
+	HTTPStatus   int       `json:"http_status,omitempty"`
+	Bytes        int64     `json:"bytes"`
+	Pages        int       `json:"pages"`
+	JobsFound    int       `json:"jobs_found"`
+	NewJobs      int       `json:"new_jobs"`
+	DurationMs   int64     `json:"duration_ms"`
+	ErrorClass   string    `json:"error_class,omitempty"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	Fingerprint  string    `json:"fingerprint,omitempty"` // structure of the career page
+	Challenge    bool      `json:"challenge,omitempty"`   // the page was a bot check
+}
+
+// Incident is an anomaly in a company's scrapes that needs attention, such as
+// a career page that suddenly lists no jobs. It stays open until a scrape no
+// longer shows the anomaly.
+type Incident struct {
+	ID         int64      `json:"id"`
+	CompanyID  int64      `json:"company_id"`
+	Company    string     `json:"company"`
+	Reason     string     `json:"reason"` // empty, drop, fingerprint or challenge
+	Detail     string     `json:"detail"`
+	OpenedAt   time.Time  `json:"opened_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// CompanyHealth summarizes a company's recent scrapes.
+type CompanyHealth struct {
+	CompanyID    int64         `json:"company_id"`
+	Company      string        `json:"company"`
+	Runs         int           `json:"runs"`
+	SuccessRate  float64       `json:"success_rate"`
+	LastRun      *time.Time    `json:"last_run,omitempty"`
+	LastSuccess  *time.Time    `json:"last_success,omitempty"`
+	AvgJobsFound float64       `json:"avg_jobs_found"`
+	Flags        []string      `json:"flags"` // "failing" and/or "empty"
+	Trend        []*CompanyRun `json:"trend"` // oldest first
+}
@@ -1,14 +1,120 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Job represents an intern job listing from a company career page.
 type Job struct {
-	ID           int64     `json:"id"`
-	Company      string    `json:"company"`
-	Title        string    `json:"title"`
-	URL          string    `json:"url"`
-	Location     string    `json:"location,omitempty"`
-	DiscoveredAt time.Time `json:"discovered_at"`
-	Notified     bool      `json:"notified"`
+	ID       int64  `json:"id"`
+	Company  string `json:"company"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Location string `json:"location,omitempty"`
+	// Source names where the job was found when it was not the company's
+	// own career page, such as the curated list it was imported from.
+	Source         string           `json:"source,omitempty"`
+	DiscoveredAt   time.Time        `json:"discovered_at"`
+	Notified       bool             `json:"notified"`
+	Score          float64          `json:"score"`
+	ScoreBreakdown []ScoreComponent `json:"score_breakdown,omitempty"`
+	Locations      []Location       `json:"locations,omitempty"`
+	Detail         *JobDetail       `json:"detail,omitempty"`
+	Compensation   *Compensation    `json:"compensation,omitempty"`
+	CanonicalURL   string           `json:"canonical_url,omitempty"`
+	PostingID      string           `json:"posting_id,omitempty"`
+	DuplicateOf    int64            `json:"duplicate_of,omitempty"`
+	Duplicates     int              `json:"duplicates,omitempty"`
+	ContentHash    string           `json:"content_hash,omitempty"`
+	Watched        bool             `json:"watched"`
+	Snapshot       *Snapshot        `json:"snapshot,omitempty"`
+	Classification
+}
+
+// Classification holds structured information extracted from a job title.
+// Empty fields mean the title did not say.
+type Classification struct {
+	Season         string `json:"season,omitempty"`
+	Year           int    `json:"year,omitempty"`
+	DegreeLevel    string `json:"degree_level,omitempty"`
+	RoleFamily     string `json:"role_family,omitempty"`
+	EmploymentType string `json:"employment_type,omitempty"`
+}
+
+// ScoreComponent explains one factor's contribution to a job's relevance score.
+type ScoreComponent struct {
+	Factor string  `json:"factor"`
+	Points float64 `json:"points"`
+	Reason string  `json:"reason"`
+}
+
+// Location is one normalized place a job is offered in.
+type Location struct {
+	Raw     string `json:"raw"`
+	City    string `json:"city,omitempty"`
+	Region  string `json:"region,omitempty"`  // state or province code, e.g. "CA"
+	Country string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code, e.g. "US"
+	Remote  bool   `json:"remote"`
+	Hybrid  bool   `json:"hybrid"`
+}
+
+// Compensation is a job's advertised pay range.
+type Compensation struct {
+	Currency  string  `json:"currency,omitempty"` // ISO 4217 code, e.g. "USD"
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	Unit      string  `json:"unit"` // hour, day, week, month or year
+	HourlyMin float64 `json:"hourly_min"`
+	HourlyMax float64 `json:"hourly_max"`
+}
+
+// JobDetail holds information extracted from a job's detail page.
+type JobDetail struct {
+	Description  string     `json:"description,omitempty"`
+	Requirements string     `json:"requirements,omitempty"`
+	PostedAt     *time.Time `json:"posted_at,omitempty"`
+	Deadline     *time.Time `json:"deadline,omitempty"`
+	Salary       string     `json:"salary,omitempty"`
+	FetchedAt    time.Time  `json:"fetched_at"`
+	// Page is the raw HTML of the detail page. It is only set on freshly
+	// fetched details and is not stored with them.
+	Page []byte `json:"-"`
+}
+
+// Excerpt returns the start of the description, cut at a word boundary so it
+// is at most n characters long.
+func (d *JobDetail) Excerpt(n int) string {
+	text := strings.Join(strings.Fields(d.Description), " ")
+	if len(text) <= n {
+		return text
+	}
+	cut := strings.LastIndex(text[:n], " ")
+	if cut <= 0 {
+		cut = n
+	}
+	return strings.TrimRight(text[:cut], ".,;:") + "…"
+}
+
+// Snapshot references the archived copy of a job's detail page.
+type Snapshot struct {
+	HTMLHash string    `json:"html_hash"`
+	TextHash string    `json:"text_hash"`
+	Size     int64     `json:"size"` // compressed bytes on disk
+	TakenAt  time.Time `json:"taken_at"`
+}
+
+// JobRevision records a change to a posting seen on a later scrape.
+type JobRevision struct {
+	ID        int64         `json:"id"`
+	JobID     int64         `json:"job_id"`
+	ChangedAt time.Time     `json:"changed_at"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// FieldChange is one field's old and new value in a JobRevision.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
 }
@@ -2,6 +2,7 @@ package notifier
 
 import (
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"strings"
 
@@ -49,7 +50,14 @@ tell application "Messages"
 	send "%s" to targetBuddy
 end tell`, escapedRecipient, escapedMessage)
 
-	return n.executor.Execute("osascript", "-e", script)
+	if err := n.executor.Execute("osascript", "-e", script); err != nil {
+		notificationsTotal.WithLabelValues("imessage", "failed").Inc()
+		slog.Debug("imessage failed", "recipient", recipient, "error", err)
+		return err
+	}
+	notificationsTotal.WithLabelValues("imessage", "sent").Inc()
+	slog.Debug("imessage sent", "recipient", recipient, "length", len(message))
+	return nil
 }
 
 // NotifyJob sends a formatted job notification.
@@ -58,6 +66,9 @@ func (n *IMessageNotifier) NotifyJob(recipient string, job *model.Job) error {
 	return n.Send(recipient, message)
 }
 
+// excerptLength is the longest description excerpt included in a job message.
+const excerptLength = 200
+
 // FormatJobMessage formats a job into a notification message.
 func FormatJobMessage(job *model.Job) string {
 	var sb strings.Builder
@@ -67,6 +78,14 @@ func FormatJobMessage(job *model.Job) string {
 	if job.Location != "" {
 		sb.WriteString(fmt.Sprintf("Location: %s\n", job.Location))
 	}
+	if d := job.Detail; d != nil {
+		if d.Deadline != nil {
+			sb.WriteString(fmt.Sprintf("Deadline: %s\n", d.Deadline.Format("Jan 2, 2006")))
+		}
+		if excerpt := d.Excerpt(excerptLength); excerpt != "" {
+			sb.WriteString(fmt.Sprintf("\n%s\n\n", excerpt))
+		}
+	}
 	sb.WriteString(fmt.Sprintf("Apply: %s\n", job.URL))
 	return sb.String()
 }
@@ -3,6 +3,9 @@ package notifier
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"intern-job-tracker/internal/model"
 )
@@ -117,3 +120,43 @@ func TestIMessageNotifier_SendError(t *testing.T) {
 		t.Error("expected error when command fails")
 	}
 }
+
+func TestIMessageNotifier_Metrics(t *testing.T) {
+	sent := testutil.ToFloat64(notificationsTotal.WithLabelValues("imessage", "sent"))
+	failed := testutil.ToFloat64(notificationsTotal.WithLabelValues("imessage", "failed"))
+
+	NewIMessageNotifier(&MockCommandExecutor{}).Send("+1234567890", "Test")
+	NewIMessageNotifier(&MockCommandExecutor{ShouldFail: true}).Send("+1234567890", "Test")
+
+	if got := testutil.ToFloat64(notificationsTotal.WithLabelValues("imessage", "sent")) - sent; got != 1 {
+		t.Errorf("expected 1 sent notification counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(notificationsTotal.WithLabelValues("imessage", "failed")) - failed; got != 1 {
+		t.Errorf("expected 1 failed notification counted, got %v", got)
+	}
+}
+
+func TestIMessageNotifier_FormatMessage_Detail(t *testing.T) {
+	deadline := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+	job := &model.Job{
+		Company: "Google",
+		Title:   "SWE Intern",
+		URL:     "https://google.com/jobs/1",
+		Detail: &model.JobDetail{
+			Description: strings.Repeat("Build large scale systems. ", 20),
+			Deadline:    &deadline,
+		},
+	}
+
+	msg := FormatJobMessage(job)
+
+	if !strings.Contains(msg, "Deadline: Nov 15, 2026") {
+		t.Error("expected deadline in message")
+	}
+	if !strings.Contains(msg, "Build large scale systems") || !strings.Contains(msg, "…") {
+		t.Error("expected truncated description excerpt in message")
+	}
+	if strings.Count(msg, "Build") > 10 {
+		t.Error("expected excerpt to be shortened")
+	}
+}
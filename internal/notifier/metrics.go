@@ -0,0 +1,12 @@
+package notifier
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"intern-job-tracker/internal/metrics"
+)
+
+var notificationsTotal = metrics.Default.NewCounterVec(prometheus.CounterOpts{
+	Name: "intern_tracker_notifications_total",
+	Help: "Notifications by channel and outcome (sent or failed).",
+}, []string{"channel", "outcome"})
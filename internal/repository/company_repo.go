@@ -7,6 +7,10 @@ import (
 	"intern-job-tracker/internal/model"
 )
 
+// companyColumns are the columns scanned by scanCompany.
+const companyColumns = `id, name, career_url, search_term, enabled, COALESCE(schedule, ''), COALESCE(active_from, ''),
+	COALESCE(active_until, ''), COALESCE(source_type, ''), COALESCE(source_id, ''), COALESCE(selectors, ''), created_at`
+
 // CompanyRepository handles database operations for companies.
 type CompanyRepository struct {
 	db *sql.DB
@@ -19,7 +23,7 @@ func NewCompanyRepository(db *sql.DB) *CompanyRepository {
 
 // GetAll returns all companies.
 func (r *CompanyRepository) GetAll() ([]*model.Company, error) {
-	rows, err := r.db.Query(`SELECT id, name, career_url, search_term, enabled, created_at FROM companies ORDER BY name`)
+	rows, err := r.db.Query(`SELECT ` + companyColumns + ` FROM companies ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
@@ -27,8 +31,7 @@ func (r *CompanyRepository) GetAll() ([]*model.Company, error) {
 
 	var companies []*model.Company
 	for rows.Next() {
-		c := &model.Company{}
-		err := rows.Scan(&c.ID, &c.Name, &c.CareerURL, &c.SearchTerm, &c.Enabled, &c.CreatedAt)
+		c, err := scanCompany(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -39,7 +42,7 @@ func (r *CompanyRepository) GetAll() ([]*model.Company, error) {
 
 // GetEnabled returns only enabled companies.
 func (r *CompanyRepository) GetEnabled() ([]*model.Company, error) {
-	rows, err := r.db.Query(`SELECT id, name, career_url, search_term, enabled, created_at FROM companies WHERE enabled = TRUE ORDER BY name`)
+	rows, err := r.db.Query(`SELECT ` + companyColumns + ` FROM companies WHERE enabled = TRUE ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
@@ -47,8 +50,7 @@ func (r *CompanyRepository) GetEnabled() ([]*model.Company, error) {
 
 	var companies []*model.Company
 	for rows.Next() {
-		c := &model.Company{}
-		err := rows.Scan(&c.ID, &c.Name, &c.CareerURL, &c.SearchTerm, &c.Enabled, &c.CreatedAt)
+		c, err := scanCompany(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -60,8 +62,10 @@ func (r *CompanyRepository) GetEnabled() ([]*model.Company, error) {
 // Create adds a new company.
 func (r *CompanyRepository) Create(c *model.Company) error {
 	result, err := r.db.Exec(
-		`INSERT INTO companies (name, career_url, search_term, enabled) VALUES (?, ?, ?, ?)`,
-		c.Name, c.CareerURL, c.SearchTerm, c.Enabled,
+		`INSERT INTO companies (name, career_url, search_term, enabled, schedule, active_from, active_until, source_type, source_id, selectors)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Name, c.CareerURL, c.SearchTerm, c.Enabled, nullString(c.Schedule), nullString(c.ActiveFrom), nullString(c.ActiveUntil),
+		nullString(c.SourceType), nullString(c.SourceID), nullString(c.Selectors),
 	)
 	if err != nil {
 		return err
@@ -75,8 +79,10 @@ func (r *CompanyRepository) Create(c *model.Company) error {
 // Update modifies an existing company.
 func (r *CompanyRepository) Update(c *model.Company) error {
 	_, err := r.db.Exec(
-		`UPDATE companies SET name = ?, career_url = ?, search_term = ?, enabled = ? WHERE id = ?`,
-		c.Name, c.CareerURL, c.SearchTerm, c.Enabled, c.ID,
+		`UPDATE companies SET name = ?, career_url = ?, search_term = ?, enabled = ?, schedule = ?, active_from = ?, active_until = ?,
+		 source_type = ?, source_id = ?, selectors = ? WHERE id = ?`,
+		c.Name, c.CareerURL, c.SearchTerm, c.Enabled, nullString(c.Schedule), nullString(c.ActiveFrom), nullString(c.ActiveUntil),
+		nullString(c.SourceType), nullString(c.SourceID), nullString(c.Selectors), c.ID,
 	)
 	return err
 }
@@ -89,11 +95,7 @@ func (r *CompanyRepository) Delete(id int64) error {
 
 // GetByID retrieves a company by ID.
 func (r *CompanyRepository) GetByID(id int64) (*model.Company, error) {
-	c := &model.Company{}
-	err := r.db.QueryRow(
-		`SELECT id, name, career_url, search_term, enabled, created_at FROM companies WHERE id = ?`,
-		id,
-	).Scan(&c.ID, &c.Name, &c.CareerURL, &c.SearchTerm, &c.Enabled, &c.CreatedAt)
+	c, err := scanCompany(r.db.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = ?`, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -102,3 +104,13 @@ func (r *CompanyRepository) GetByID(id int64) (*model.Company, error) {
 	}
 	return c, nil
 }
+
+func scanCompany(row rowScanner) (*model.Company, error) {
+	c := &model.Company{}
+	err := row.Scan(&c.ID, &c.Name, &c.CareerURL, &c.SearchTerm, &c.Enabled, &c.Schedule, &c.ActiveFrom, &c.ActiveUntil,
+		&c.SourceType, &c.SourceID, &c.Selectors, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
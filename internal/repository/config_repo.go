@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// ConfigRepository handles database operations for the key-value config table.
+type ConfigRepository struct {
+	db *sql.DB
+}
+
+// NewConfigRepository creates a new ConfigRepository.
+func NewConfigRepository(db *sql.DB) *ConfigRepository {
+	return &ConfigRepository{db: db}
+}
+
+// Get returns the value stored under key, and whether it was set.
+func (r *ConfigRepository) Get(key string) (string, bool, error) {
+	var value sql.NullString
+	err := r.db.QueryRow(`SELECT value FROM config WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value.String, true, nil
+}
+
+// GetAll returns every stored key and value.
+func (r *ConfigRepository) GetAll() (map[string]string, error) {
+	rows, err := r.db.Query(`SELECT key, COALESCE(value, '') FROM config`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, rows.Err()
+}
+
+// Set stores a value under key, replacing any previous value.
+func (r *ConfigRepository) Set(key, value string) error {
+	return r.SetMany(map[string]string{key: value})
+}
+
+// SetMany stores several values in one transaction.
+func (r *ConfigRepository) SetMany(values map[string]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for key, value := range values {
+		_, err := tx.Exec(
+			`INSERT INTO config (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+			key, value,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,34 @@
+package repository
+
+import "testing"
+
+func TestConfigRepository_GetSet(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewConfigRepository(database)
+
+	if _, ok, err := repo.Get("schedule"); err != nil || ok {
+		t.Fatalf("expected unset key, got ok=%v err=%v", ok, err)
+	}
+
+	if err := repo.Set("schedule", "0 9 * * *"); err != nil {
+		t.Fatalf("failed to set: %v", err)
+	}
+	if err := repo.SetMany(map[string]string{"schedule": "0 8 * * 1-5", "recipient": "+1234567890"}); err != nil {
+		t.Fatalf("failed to set many: %v", err)
+	}
+
+	value, ok, err := repo.Get("schedule")
+	if err != nil || !ok || value != "0 8 * * 1-5" {
+		t.Errorf("expected updated schedule, got %q ok=%v err=%v", value, ok, err)
+	}
+
+	all, err := repo.GetAll()
+	if err != nil {
+		t.Fatalf("failed to get all: %v", err)
+	}
+	if len(all) != 2 || all["recipient"] != "+1234567890" {
+		t.Errorf("unexpected values %v", all)
+	}
+}
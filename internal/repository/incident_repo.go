@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+
+	"intern-job-tracker/internal/model"
+)
+
+// IncidentRepository handles database operations for scrape incidents.
+type IncidentRepository struct {
+	db *sql.DB
+}
+
+// NewIncidentRepository creates a new IncidentRepository.
+func NewIncidentRepository(db *sql.DB) *IncidentRepository {
+	return &IncidentRepository{db: db}
+}
+
+// Open records a new incident.
+func (r *IncidentRepository) Open(incident *model.Incident) error {
+	result, err := r.db.Exec(
+		`INSERT INTO incidents (company_id, company, reason, detail) VALUES (?, ?, ?, ?)`,
+		incident.CompanyID, incident.Company, incident.Reason, incident.Detail,
+	)
+	if err != nil {
+		return err
+	}
+	incident.ID, _ = result.LastInsertId()
+	return r.db.QueryRow(`SELECT opened_at FROM incidents WHERE id = ?`, incident.ID).Scan(&incident.OpenedAt)
+}
+
+// Resolve closes an open incident.
+func (r *IncidentRepository) Resolve(id int64) error {
+	_, err := r.db.Exec(`UPDATE incidents SET resolved_at = CURRENT_TIMESTAMP WHERE id = ? AND resolved_at IS NULL`, id)
+	return err
+}
+
+// GetOpen returns a company's open incidents.
+func (r *IncidentRepository) GetOpen(companyID int64) ([]*model.Incident, error) {
+	return r.query(
+		`SELECT id, COALESCE(company_id, 0), company, reason, COALESCE(detail, ''), opened_at, resolved_at
+		 FROM incidents WHERE company_id = ? AND resolved_at IS NULL ORDER BY opened_at`,
+		companyID,
+	)
+}
+
+// List returns the most recent incidents, newest first, optionally only the
+// open ones.
+func (r *IncidentRepository) List(openOnly bool, limit int) ([]*model.Incident, error) {
+	query := `SELECT id, COALESCE(company_id, 0), company, reason, COALESCE(detail, ''), opened_at, resolved_at FROM incidents`
+	if openOnly {
+		query += ` WHERE resolved_at IS NULL`
+	}
+	return r.query(query+` ORDER BY opened_at DESC, id DESC LIMIT ?`, limit)
+}
+
+func (r *IncidentRepository) query(query string, args ...interface{}) ([]*model.Incident, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []*model.Incident
+	for rows.Next() {
+		i := &model.Incident{}
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&i.ID, &i.CompanyID, &i.Company, &i.Reason, &i.Detail, &i.OpenedAt, &resolvedAt); err != nil {
+			return nil, err
+		}
+		if resolvedAt.Valid {
+			i.ResolvedAt = &resolvedAt.Time
+		}
+		incidents = append(incidents, i)
+	}
+	return incidents, rows.Err()
+}
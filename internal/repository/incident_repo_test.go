@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestIncidentRepository_OpenResolve(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewIncidentRepository(database)
+
+	incident := &model.Incident{CompanyID: 1, Company: "Google", Reason: "empty", Detail: "found no jobs, usually 8"}
+	if err := repo.Open(incident); err != nil {
+		t.Fatalf("failed to open incident: %v", err)
+	}
+	if incident.ID == 0 || incident.OpenedAt.IsZero() {
+		t.Errorf("expected ID and opened time to be set, got %+v", incident)
+	}
+	repo.Open(&model.Incident{CompanyID: 2, Company: "Uber", Reason: "challenge"})
+
+	open, err := repo.GetOpen(1)
+	if err != nil {
+		t.Fatalf("failed to get open incidents: %v", err)
+	}
+	if len(open) != 1 || open[0].Reason != "empty" || open[0].Detail != "found no jobs, usually 8" {
+		t.Errorf("unexpected open incidents %+v", open)
+	}
+
+	if err := repo.Resolve(incident.ID); err != nil {
+		t.Fatalf("failed to resolve incident: %v", err)
+	}
+	if open, _ := repo.GetOpen(1); len(open) != 0 {
+		t.Errorf("expected no open incidents after resolving, got %+v", open)
+	}
+
+	all, _ := repo.List(false, 10)
+	if len(all) != 2 || all[1].ResolvedAt == nil {
+		t.Errorf("expected both incidents with the first resolved, got %+v", all)
+	}
+	if open, _ := repo.List(true, 10); len(open) != 1 || open[0].Company != "Uber" {
+		t.Errorf("expected only the Uber incident open, got %+v", open)
+	}
+}
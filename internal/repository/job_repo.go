@@ -2,11 +2,55 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
+	"math"
+	"strings"
 	"time"
 
+	"intern-job-tracker/internal/dedupe"
+	"intern-job-tracker/internal/history"
 	"intern-job-tracker/internal/model"
 )
 
+// jobColumns is the column list shared by every query that returns jobs.
+const jobColumns = `id, company, title, url, location, discovered_at, notified, score, score_breakdown,
+	season, year, degree_level, role_family, employment_type,
+	salary_currency, salary_min, salary_max, salary_unit, hourly_min, hourly_max,
+	canonical_url, posting_id, duplicate_of, content_hash, watched,
+	snapshot_html, snapshot_text, snapshot_size, snapshot_at, source`
+
+// JobFilter narrows the jobs returned by List. Zero-valued fields match all jobs.
+type JobFilter struct {
+	Company        string
+	Source         string
+	Season         string
+	Year           int
+	DegreeLevel    string
+	RoleFamily     string
+	EmploymentType string
+	Region         string  // state or province code of any of the job's locations
+	Country        string  // country code of any of the job's locations
+	Remote         bool    // only jobs with a remote location
+	MinHourly      float64 // jobs whose hourly pay can reach at least this
+	MaxHourly      float64 // jobs whose hourly pay starts at or below this
+	// IncludeDuplicates also returns jobs linked to another job as duplicates.
+	// By default only canonical jobs are returned, with their duplicate counts.
+	IncludeDuplicates bool
+}
+
+// CompanyCompensation summarizes the hourly pay advertised by a company.
+type CompanyCompensation struct {
+	Company   string  `json:"company"`
+	Currency  string  `json:"currency"`
+	Jobs      int     `json:"jobs"`
+	MinHourly float64 `json:"min_hourly"`
+	MaxHourly float64 `json:"max_hourly"`
+	AvgHourly float64 `json:"avg_hourly"`
+}
+
+// facetColumns are the classification columns reported by Facets.
+var facetColumns = []string{"season", "year", "degree_level", "role_family", "employment_type"}
+
 // JobRepository handles database operations for jobs.
 type JobRepository struct {
 	db *sql.DB
@@ -17,11 +61,42 @@ func NewJobRepository(db *sql.DB) *JobRepository {
 	return &JobRepository{db: db}
 }
 
-// Create inserts a new job into the database.
+// Create inserts a new job, its normalized locations and its details into the
+// database. The job's canonical URL and posting ID are derived from its URL,
+// and its content hash from its tracked fields, if not set.
 func (r *JobRepository) Create(job *model.Job) error {
-	result, err := r.db.Exec(
-		`INSERT INTO jobs (company, title, url, location, notified) VALUES (?, ?, ?, ?, ?)`,
-		job.Company, job.Title, job.URL, job.Location, false,
+	breakdown, err := encodeBreakdown(job.ScoreBreakdown)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	setDedupeKeys(job)
+	if job.ContentHash == "" {
+		job.ContentHash = history.Hash(job)
+	}
+	c := job.Classification
+	args := []any{job.Company, job.Title, job.URL, job.Location, false, job.Score, breakdown,
+		nullString(c.Season), nullInt(c.Year), nullString(c.DegreeLevel), nullString(c.RoleFamily), nullString(c.EmploymentType),
+		c != model.Classification{}}
+	args = append(args, compensationValues(job.Compensation)...)
+	args = append(args, job.Compensation != nil, job.CanonicalURL, nullString(job.PostingID), dedupe.TitleKey(job.Title), nullInt64(job.DuplicateOf),
+		job.ContentHash, job.Watched)
+	args = append(args, snapshotValues(job.Snapshot)...)
+	args = append(args, nullString(job.Source))
+	result, err := tx.Exec(
+		`INSERT INTO jobs (company, title, url, location, notified, score, score_breakdown,
+			season, year, degree_level, role_family, employment_type, classified,
+			salary_currency, salary_min, salary_max, salary_unit, hourly_min, hourly_max, compensation_parsed,
+			canonical_url, posting_id, title_key, duplicate_of, content_hash, watched,
+			snapshot_html, snapshot_text, snapshot_size, snapshot_at, source)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		args...,
 	)
 	if err != nil {
 		return err
@@ -31,6 +106,17 @@ func (r *JobRepository) Create(job *model.Job) error {
 	if err != nil {
 		return err
 	}
+	if err := insertLocations(tx, id, job.Locations); err != nil {
+		return err
+	}
+	if job.Detail != nil {
+		if err := saveDetail(tx, id, job.Detail); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
 	job.ID = id
 	job.DiscoveredAt = time.Now()
@@ -38,27 +124,168 @@ func (r *JobRepository) Create(job *model.Job) error {
 	return nil
 }
 
-// GetByURL retrieves a job by its URL. Returns nil if not found.
+// GetByURL retrieves a job with its locations and details by its URL or by a
+// URL with the same canonical form. Returns nil if not found.
 func (r *JobRepository) GetByURL(url string) (*model.Job, error) {
-	job := &model.Job{}
-	err := r.db.QueryRow(
-		`SELECT id, company, title, url, location, discovered_at, notified FROM jobs WHERE url = ?`,
-		url,
-	).Scan(&job.ID, &job.Company, &job.Title, &job.URL, &job.Location, &job.DiscoveredAt, &job.Notified)
-
+	job, err := scanJob(r.db.QueryRow(
+		`SELECT `+jobColumns+` FROM jobs WHERE url = ? OR canonical_url = ? ORDER BY id LIMIT 1`,
+		url, dedupe.CanonicalURL(url),
+	))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if err := r.attachLocations([]*model.Job{job}); err != nil {
+		return nil, err
+	}
+	if job.Detail, err = r.GetDetail(job.ID); err != nil {
+		return nil, err
+	}
 	return job, nil
 }
 
-// GetUnnotified returns all jobs that haven't been notified yet.
+// SaveRevision stores a new version of an existing job, replacing its
+// locations and details, and records the changes from the previous version.
+func (r *JobRepository) SaveRevision(job *model.Job, changes []model.FieldChange) error {
+	breakdown, err := encodeBreakdown(job.ScoreBreakdown)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	job.ContentHash = history.Hash(job)
+	c := job.Classification
+	args := []any{job.Title, job.Location, job.Score, breakdown,
+		nullString(c.Season), nullInt(c.Year), nullString(c.DegreeLevel), nullString(c.RoleFamily), nullString(c.EmploymentType),
+		c != model.Classification{}}
+	args = append(args, compensationValues(job.Compensation)...)
+	args = append(args, job.Compensation != nil, dedupe.TitleKey(job.Title), job.ContentHash, job.ID)
+	_, err = tx.Exec(
+		`UPDATE jobs SET title = ?, location = ?, score = ?, score_breakdown = ?,
+			season = ?, year = ?, degree_level = ?, role_family = ?, employment_type = ?, classified = ?,
+			salary_currency = ?, salary_min = ?, salary_max = ?, salary_unit = ?, hourly_min = ?, hourly_max = ?,
+			compensation_parsed = ?, title_key = ?, content_hash = ?
+		 WHERE id = ?`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM job_locations WHERE job_id = ?`, job.ID); err != nil {
+		return err
+	}
+	if err := insertLocations(tx, job.ID, job.Locations); err != nil {
+		return err
+	}
+	if job.Detail != nil {
+		if err := saveDetail(tx, job.ID, job.Detail); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO job_revisions (job_id, changes) VALUES (?, ?)`, job.ID, string(encoded)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetRevisions returns the recorded changes to a job, newest first.
+func (r *JobRepository) GetRevisions(jobID int64) ([]*model.JobRevision, error) {
+	rows, err := r.db.Query(
+		`SELECT id, job_id, changed_at, changes FROM job_revisions WHERE job_id = ? ORDER BY changed_at DESC, id DESC`,
+		jobID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*model.JobRevision{}
+	for rows.Next() {
+		rev := &model.JobRevision{}
+		var changes string
+		if err := rows.Scan(&rev.ID, &rev.JobID, &rev.ChangedAt, &changes); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &rev.Changes); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// SetWatched marks whether changes to a job should be notified.
+func (r *JobRepository) SetWatched(id int64, watched bool) error {
+	_, err := r.db.Exec(`UPDATE jobs SET watched = ? WHERE id = ?`, watched, id)
+	return err
+}
+
+// GetWithoutContentHash returns jobs stored before change tracking, with
+// their details loaded.
+func (r *JobRepository) GetWithoutContentHash() ([]*model.Job, error) {
+	rows, err := r.db.Query(`SELECT ` + jobColumns + ` FROM jobs WHERE content_hash IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := scanJobs(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if job.Detail, err = r.GetDetail(job.ID); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// GetWithSnapshots returns jobs with an archived snapshot, oldest snapshot
+// first.
+func (r *JobRepository) GetWithSnapshots() ([]*model.Job, error) {
+	rows, err := r.db.Query(
+		`SELECT ` + jobColumns + ` FROM jobs WHERE snapshot_html IS NOT NULL ORDER BY snapshot_at, id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+// ClearSnapshot removes the reference to a job's archived snapshot.
+func (r *JobRepository) ClearSnapshot(id int64) error {
+	_, err := r.db.Exec(
+		`UPDATE jobs SET snapshot_html = NULL, snapshot_text = NULL, snapshot_size = NULL, snapshot_at = NULL WHERE id = ?`,
+		id,
+	)
+	return err
+}
+
+// UpdateContentHash stores the content hash of a job.
+func (r *JobRepository) UpdateContentHash(id int64, hash string) error {
+	_, err := r.db.Exec(`UPDATE jobs SET content_hash = ? WHERE id = ?`, hash, id)
+	return err
+}
+
+// GetUnnotified returns all jobs that haven't been notified yet, excluding
+// duplicates of other jobs.
 func (r *JobRepository) GetUnnotified() ([]*model.Job, error) {
 	rows, err := r.db.Query(
-		`SELECT id, company, title, url, location, discovered_at, notified FROM jobs WHERE notified = FALSE`,
+		`SELECT ` + jobColumns + ` FROM jobs WHERE notified = FALSE AND duplicate_of IS NULL`,
 	)
 	if err != nil {
 		return nil, err
@@ -74,10 +301,20 @@ func (r *JobRepository) MarkNotified(id int64) error {
 	return err
 }
 
-// GetAll returns all jobs.
+// UpdateScore stores a recomputed relevance score for a job.
+func (r *JobRepository) UpdateScore(id int64, score float64, breakdown []model.ScoreComponent) error {
+	encoded, err := encodeBreakdown(breakdown)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`UPDATE jobs SET score = ?, score_breakdown = ? WHERE id = ?`, score, encoded, id)
+	return err
+}
+
+// GetAll returns all jobs, most recently discovered first.
 func (r *JobRepository) GetAll() ([]*model.Job, error) {
 	rows, err := r.db.Query(
-		`SELECT id, company, title, url, location, discovered_at, notified FROM jobs ORDER BY discovered_at DESC`,
+		`SELECT ` + jobColumns + ` FROM jobs ORDER BY discovered_at DESC`,
 	)
 	if err != nil {
 		return nil, err
@@ -87,28 +324,553 @@ func (r *JobRepository) GetAll() ([]*model.Job, error) {
 	return scanJobs(rows)
 }
 
-// GetByID retrieves a job by its ID.
+// List returns the jobs matching the filter ordered by relevance score,
+// highest first. Jobs with equal scores are ordered by discovery time, newest
+// first.
+func (r *JobRepository) List(filter JobFilter) ([]*model.Job, error) {
+	var where []string
+	var args []any
+	addEq := func(column string, value any) {
+		where = append(where, column+" = ?")
+		args = append(args, value)
+	}
+	if filter.Company != "" {
+		addEq("company", filter.Company)
+	}
+	if filter.Source != "" {
+		addEq("source", filter.Source)
+	}
+	if filter.Season != "" {
+		addEq("season", filter.Season)
+	}
+	if filter.Year != 0 {
+		addEq("year", filter.Year)
+	}
+	if filter.DegreeLevel != "" {
+		addEq("degree_level", filter.DegreeLevel)
+	}
+	if filter.RoleFamily != "" {
+		addEq("role_family", filter.RoleFamily)
+	}
+	if filter.EmploymentType != "" {
+		addEq("employment_type", filter.EmploymentType)
+	}
+	if filter.Region != "" {
+		where = append(where, `EXISTS (SELECT 1 FROM job_locations l WHERE l.job_id = jobs.id AND l.region = ?)`)
+		args = append(args, filter.Region)
+	}
+	if filter.Country != "" {
+		where = append(where, `EXISTS (SELECT 1 FROM job_locations l WHERE l.job_id = jobs.id AND l.country = ?)`)
+		args = append(args, filter.Country)
+	}
+	if filter.Remote {
+		where = append(where, `EXISTS (SELECT 1 FROM job_locations l WHERE l.job_id = jobs.id AND l.remote = TRUE)`)
+	}
+	if filter.MinHourly > 0 {
+		where = append(where, "hourly_max >= ?")
+		args = append(args, filter.MinHourly)
+	}
+	if filter.MaxHourly > 0 {
+		where = append(where, "hourly_min <= ?")
+		args = append(args, filter.MaxHourly)
+	}
+	if !filter.IncludeDuplicates {
+		where = append(where, "duplicate_of IS NULL")
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY score DESC, discovered_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs, err := scanJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attachLocations(jobs); err != nil {
+		return nil, err
+	}
+	if err := r.attachDuplicateCounts(jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindDuplicate returns the ID of an earlier canonical job from the same
+// company that the job duplicates, or 0 if it is not a duplicate.
+func (r *JobRepository) FindDuplicate(job *model.Job) (int64, error) {
+	setDedupeKeys(job)
+	rows, err := r.db.Query(
+		`SELECT id, COALESCE(posting_id, ''), title_key FROM jobs
+		 WHERE company = ? AND id != ? AND duplicate_of IS NULL AND title_key IS NOT NULL`,
+		job.Company, job.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var candidates []dedupe.Candidate
+	for rows.Next() {
+		var c dedupe.Candidate
+		if err := rows.Scan(&c.ID, &c.PostingID, &c.TitleKey); err != nil {
+			return 0, err
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return dedupe.FindDuplicate(job.PostingID, dedupe.TitleKey(job.Title), candidates), nil
+}
+
+// GetWithoutDedupeKeys returns jobs stored before duplicate detection, oldest
+// first.
+func (r *JobRepository) GetWithoutDedupeKeys() ([]*model.Job, error) {
+	rows, err := r.db.Query(
+		`SELECT ` + jobColumns + ` FROM jobs WHERE title_key IS NULL ORDER BY id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+// UpdateDedupeKeys stores a job's canonical URL, posting ID and title key,
+// and links it to the job it duplicates if DuplicateOf is set.
+func (r *JobRepository) UpdateDedupeKeys(job *model.Job) error {
+	setDedupeKeys(job)
+	_, err := r.db.Exec(
+		`UPDATE jobs SET canonical_url = ?, posting_id = ?, title_key = ?, duplicate_of = ? WHERE id = ?`,
+		job.CanonicalURL, nullString(job.PostingID), dedupe.TitleKey(job.Title), nullInt64(job.DuplicateOf), job.ID,
+	)
+	return err
+}
+
+// GetUnclassified returns jobs whose titles have not been classified yet.
+func (r *JobRepository) GetUnclassified() ([]*model.Job, error) {
+	rows, err := r.db.Query(
+		`SELECT ` + jobColumns + ` FROM jobs WHERE classified = FALSE OR classified IS NULL`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+// UpdateClassification stores the classification extracted from a job's title.
+func (r *JobRepository) UpdateClassification(id int64, c model.Classification) error {
+	_, err := r.db.Exec(
+		`UPDATE jobs SET season = ?, year = ?, degree_level = ?, role_family = ?, employment_type = ?, classified = TRUE WHERE id = ?`,
+		nullString(c.Season), nullInt(c.Year), nullString(c.DegreeLevel), nullString(c.RoleFamily), nullString(c.EmploymentType), id,
+	)
+	return err
+}
+
+// GetWithoutCompensation returns jobs with fetched details whose
+// compensation has not been parsed yet, with their details loaded.
+func (r *JobRepository) GetWithoutCompensation() ([]*model.Job, error) {
+	rows, err := r.db.Query(
+		`SELECT ` + jobColumns + ` FROM jobs
+		 WHERE (compensation_parsed = FALSE OR compensation_parsed IS NULL) AND salary_unit IS NULL
+		   AND EXISTS (SELECT 1 FROM job_details d WHERE d.job_id = jobs.id)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := scanJobs(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if job.Detail, err = r.GetDetail(job.ID); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// UpdateCompensation stores the compensation parsed for a job, which is nil
+// if the job states none.
+func (r *JobRepository) UpdateCompensation(id int64, c *model.Compensation) error {
+	_, err := r.db.Exec(
+		`UPDATE jobs SET salary_currency = ?, salary_min = ?, salary_max = ?, salary_unit = ?, hourly_min = ?, hourly_max = ?,
+			compensation_parsed = TRUE
+		 WHERE id = ?`,
+		append(compensationValues(c), id)...,
+	)
+	return err
+}
+
+// CompensationByCompany summarizes the hourly pay of jobs with known
+// compensation, grouped by company and currency.
+func (r *JobRepository) CompensationByCompany() ([]*CompanyCompensation, error) {
+	rows, err := r.db.Query(
+		`SELECT company, COALESCE(salary_currency, ''), COUNT(*), MIN(hourly_min), MAX(hourly_max), AVG((hourly_min + hourly_max) / 2)
+		 FROM jobs WHERE salary_unit IS NOT NULL
+		 GROUP BY company, salary_currency ORDER BY company`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*CompanyCompensation
+	for rows.Next() {
+		c := &CompanyCompensation{}
+		if err := rows.Scan(&c.Company, &c.Currency, &c.Jobs, &c.MinHourly, &c.MaxHourly, &c.AvgHourly); err != nil {
+			return nil, err
+		}
+		c.AvgHourly = math.Round(c.AvgHourly*100) / 100
+		summaries = append(summaries, c)
+	}
+	return summaries, rows.Err()
+}
+
+// Facets returns job counts for each value of every classification column,
+// keyed by column name. Unclassified values are omitted.
+func (r *JobRepository) Facets() (map[string]map[string]int, error) {
+	facets := make(map[string]map[string]int)
+	for _, column := range facetColumns {
+		rows, err := r.db.Query(
+			`SELECT ` + column + `, COUNT(*) FROM jobs WHERE ` + column + ` IS NOT NULL GROUP BY ` + column,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		counts := make(map[string]int)
+		for rows.Next() {
+			var value string
+			var count int
+			if err := rows.Scan(&value, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			counts[value] = count
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		facets[column] = counts
+	}
+	return facets, nil
+}
+
+// GetByID retrieves a job with its locations and details by its ID.
 func (r *JobRepository) GetByID(id int64) (*model.Job, error) {
-	job := &model.Job{}
-	err := r.db.QueryRow(
-		`SELECT id, company, title, url, location, discovered_at, notified FROM jobs WHERE id = ?`,
-		id,
-	).Scan(&job.ID, &job.Company, &job.Title, &job.URL, &job.Location, &job.DiscoveredAt, &job.Notified)
+	job, err := scanJob(r.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attachLocations([]*model.Job{job}); err != nil {
+		return nil, err
+	}
+	if job.Detail, err = r.GetDetail(id); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
 
+// GetDetail retrieves the detail page information of a job. Returns nil if
+// the detail page has not been fetched.
+func (r *JobRepository) GetDetail(jobID int64) (*model.JobDetail, error) {
+	d := &model.JobDetail{}
+	var description, requirements, salary sql.NullString
+	var postedAt, deadline sql.NullTime
+	err := r.db.QueryRow(
+		`SELECT description, requirements, posted_at, deadline, salary, fetched_at FROM job_details WHERE job_id = ?`,
+		jobID,
+	).Scan(&description, &requirements, &postedAt, &deadline, &salary, &d.FetchedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	d.Description, d.Requirements, d.Salary = description.String, requirements.String, salary.String
+	if postedAt.Valid {
+		d.PostedAt = &postedAt.Time
+	}
+	if deadline.Valid {
+		d.Deadline = &deadline.Time
+	}
+	return d, nil
+}
+
+// SaveDetail stores the detail page information of a job, replacing any
+// previously fetched details.
+func (r *JobRepository) SaveDetail(jobID int64, detail *model.JobDetail) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveDetail(tx, jobID, detail); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetWithoutLocations returns jobs that have a location string but no
+// normalized locations.
+func (r *JobRepository) GetWithoutLocations() ([]*model.Job, error) {
+	rows, err := r.db.Query(
+		`SELECT ` + jobColumns + ` FROM jobs
+		 WHERE COALESCE(location, '') != ''
+		   AND NOT EXISTS (SELECT 1 FROM job_locations l WHERE l.job_id = jobs.id)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+// SaveLocations replaces the normalized locations of a job.
+func (r *JobRepository) SaveLocations(jobID int64, locations []model.Location) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM job_locations WHERE job_id = ?`, jobID); err != nil {
+		return err
+	}
+	if err := insertLocations(tx, jobID, locations); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LocationCounts returns the number of jobs offered in each country, each
+// region (keyed "country-region", e.g. "US-CA") and remotely.
+func (r *JobRepository) LocationCounts() (map[string]map[string]int, error) {
+	queries := map[string]string{
+		"country": `SELECT country, COUNT(DISTINCT job_id) FROM job_locations WHERE country IS NOT NULL GROUP BY country`,
+		"region": `SELECT country || '-' || region, COUNT(DISTINCT job_id) FROM job_locations
+			WHERE country IS NOT NULL AND region IS NOT NULL GROUP BY country, region`,
+		"remote": `SELECT 'remote', COUNT(DISTINCT job_id) FROM job_locations WHERE remote = TRUE`,
+	}
+
+	counts := make(map[string]map[string]int)
+	for key, query := range queries {
+		rows, err := r.db.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]int)
+		for rows.Next() {
+			var value string
+			var count int
+			if err := rows.Scan(&value, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			values[value] = count
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		counts[key] = values
+	}
+	return counts, nil
+}
+
+// attachDuplicateCounts counts the duplicates linked to each of the given jobs.
+func (r *JobRepository) attachDuplicateCounts(jobs []*model.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Job, len(jobs))
+	placeholders := make([]string, len(jobs))
+	args := make([]any, len(jobs))
+	for i, job := range jobs {
+		byID[job.ID] = job
+		placeholders[i] = "?"
+		args[i] = job.ID
+	}
+
+	rows, err := r.db.Query(
+		`SELECT duplicate_of, COUNT(*) FROM jobs
+		 WHERE duplicate_of IN (`+strings.Join(placeholders, ", ")+`) GROUP BY duplicate_of`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jobID int64
+		var count int
+		if err := rows.Scan(&jobID, &count); err != nil {
+			return err
+		}
+		if job := byID[jobID]; job != nil {
+			job.Duplicates = count
+		}
+	}
+	return rows.Err()
+}
+
+// attachLocations loads the normalized locations of the given jobs.
+func (r *JobRepository) attachLocations(jobs []*model.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Job, len(jobs))
+	placeholders := make([]string, len(jobs))
+	args := make([]any, len(jobs))
+	for i, job := range jobs {
+		byID[job.ID] = job
+		placeholders[i] = "?"
+		args[i] = job.ID
+	}
+
+	rows, err := r.db.Query(
+		`SELECT job_id, raw, city, region, country, remote, hybrid FROM job_locations
+		 WHERE job_id IN (`+strings.Join(placeholders, ", ")+`) ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jobID int64
+		var raw, city, region, country sql.NullString
+		loc := model.Location{}
+		if err := rows.Scan(&jobID, &raw, &city, &region, &country, &loc.Remote, &loc.Hybrid); err != nil {
+			return err
+		}
+		loc.Raw, loc.City, loc.Region, loc.Country = raw.String, city.String, region.String, country.String
+		if job := byID[jobID]; job != nil {
+			job.Locations = append(job.Locations, loc)
+		}
+	}
+	return rows.Err()
+}
+
+func saveDetail(tx *sql.Tx, jobID int64, d *model.JobDetail) error {
+	fetchedAt := d.FetchedAt
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+	_, err := tx.Exec(
+		`INSERT OR REPLACE INTO job_details (job_id, description, requirements, posted_at, deadline, salary, fetched_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		jobID, nullString(d.Description), nullString(d.Requirements), nullTime(d.PostedAt), nullTime(d.Deadline),
+		nullString(d.Salary), fetchedAt,
+	)
+	return err
+}
+
+func insertLocations(tx *sql.Tx, jobID int64, locations []model.Location) error {
+	for _, loc := range locations {
+		_, err := tx.Exec(
+			`INSERT INTO job_locations (job_id, raw, city, region, country, remote, hybrid) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			jobID, loc.Raw, nullString(loc.City), nullString(loc.Region), nullString(loc.Country), loc.Remote, loc.Hybrid,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (*model.Job, error) {
+	job := &model.Job{}
+	var location, breakdown, season, degree, role, employment, currency, unit sql.NullString
+	var year sql.NullInt64
+	var salaryMin, salaryMax, hourlyMin, hourlyMax sql.NullFloat64
+	var canonicalURL, postingID, contentHash sql.NullString
+	var duplicateOf sql.NullInt64
+	var watched sql.NullBool
+	var snapshotHTML, snapshotText sql.NullString
+	var snapshotSize sql.NullInt64
+	var snapshotAt sql.NullTime
+	var source sql.NullString
+	err := row.Scan(&job.ID, &job.Company, &job.Title, &job.URL, &location, &job.DiscoveredAt, &job.Notified, &job.Score, &breakdown,
+		&season, &year, &degree, &role, &employment,
+		&currency, &salaryMin, &salaryMax, &unit, &hourlyMin, &hourlyMax,
+		&canonicalURL, &postingID, &duplicateOf, &contentHash, &watched,
+		&snapshotHTML, &snapshotText, &snapshotSize, &snapshotAt, &source)
+	if err != nil {
+		return nil, err
+	}
+	job.Source = source.String
+	job.CanonicalURL, job.PostingID, job.DuplicateOf = canonicalURL.String, postingID.String, duplicateOf.Int64
+	job.ContentHash, job.Watched = contentHash.String, watched.Bool
+	if snapshotHTML.Valid {
+		job.Snapshot = &model.Snapshot{
+			HTMLHash: snapshotHTML.String,
+			TextHash: snapshotText.String,
+			Size:     snapshotSize.Int64,
+			TakenAt:  snapshotAt.Time,
+		}
+	}
+	if unit.Valid {
+		job.Compensation = &model.Compensation{
+			Currency:  currency.String,
+			Min:       salaryMin.Float64,
+			Max:       salaryMax.Float64,
+			Unit:      unit.String,
+			HourlyMin: hourlyMin.Float64,
+			HourlyMax: hourlyMax.Float64,
+		}
+	}
+	job.Location = location.String
+	job.Classification = model.Classification{
+		Season:         season.String,
+		Year:           int(year.Int64),
+		DegreeLevel:    degree.String,
+		RoleFamily:     role.String,
+		EmploymentType: employment.String,
+	}
+	if breakdown.Valid && breakdown.String != "" {
+		if err := json.Unmarshal([]byte(breakdown.String), &job.ScoreBreakdown); err != nil {
+			return nil, err
+		}
+	}
 	return job, nil
 }
 
 func scanJobs(rows *sql.Rows) ([]*model.Job, error) {
 	var jobs []*model.Job
 	for rows.Next() {
-		job := &model.Job{}
-		err := rows.Scan(&job.ID, &job.Company, &job.Title, &job.URL, &job.Location, &job.DiscoveredAt, &job.Notified)
+		job, err := scanJob(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -116,3 +878,62 @@ func scanJobs(rows *sql.Rows) ([]*model.Job, error) {
 	}
 	return jobs, rows.Err()
 }
+
+func encodeBreakdown(breakdown []model.ScoreComponent) (sql.NullString, error) {
+	if len(breakdown) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// snapshotValues returns the snapshot column values, all NULL when the job has
+// no snapshot.
+func snapshotValues(s *model.Snapshot) []any {
+	if s == nil {
+		return []any{nil, nil, nil, nil}
+	}
+	return []any{s.HTMLHash, s.TextHash, s.Size, s.TakenAt}
+}
+
+// setDedupeKeys derives the job's canonical URL and posting ID from its URL
+// if they are not set.
+func setDedupeKeys(job *model.Job) {
+	if job.CanonicalURL == "" {
+		job.CanonicalURL = dedupe.CanonicalURL(job.URL)
+	}
+	if job.PostingID == "" {
+		job.PostingID = dedupe.PostingID(job.URL)
+	}
+}
+
+// compensationValues returns the salary column values for a compensation,
+// all NULL when it is unknown.
+func compensationValues(c *model.Compensation) []any {
+	if c == nil {
+		return []any{nil, nil, nil, nil, nil, nil}
+	}
+	return []any{nullString(c.Currency), c.Min, c.Max, c.Unit, c.HourlyMin, c.HourlyMax}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt(i int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(i), Valid: i != 0}
+}
+
+func nullInt64(i int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: i, Valid: i != 0}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"os"
 	"testing"
+	"time"
 
 	"intern-job-tracker/internal/db"
 	"intern-job-tracker/internal/model"
@@ -179,3 +180,371 @@ func TestJobRepository_GetAll(t *testing.T) {
 		t.Errorf("expected 3 jobs, got %d", len(all))
 	}
 }
+
+func TestJobRepository_ListRanked(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	low := &model.Job{Company: "Uber", Title: "Intern", URL: "https://uber.com/low", Score: 1}
+	high := &model.Job{
+		Company: "Google",
+		Title:   "SWE Intern",
+		URL:     "https://google.com/high",
+		Score:   9.5,
+		ScoreBreakdown: []model.ScoreComponent{
+			{Factor: "keyword", Points: 9.5, Reason: `title mentions "swe"`},
+		},
+	}
+	repo.Create(low)
+	repo.Create(high)
+
+	ranked, err := repo.List(JobFilter{})
+	if err != nil {
+		t.Fatalf("failed to get ranked jobs: %v", err)
+	}
+
+	if len(ranked) != 2 || ranked[0].URL != high.URL {
+		t.Fatalf("expected highest score first, got %+v", ranked)
+	}
+	if len(ranked[0].ScoreBreakdown) != 1 || ranked[0].ScoreBreakdown[0].Factor != "keyword" {
+		t.Errorf("expected breakdown to round-trip, got %+v", ranked[0].ScoreBreakdown)
+	}
+
+	if err := repo.UpdateScore(low.ID, 20, nil); err != nil {
+		t.Fatalf("failed to update score: %v", err)
+	}
+	ranked, _ = repo.List(JobFilter{})
+	if ranked[0].URL != low.URL {
+		t.Error("expected rescored job to rank first")
+	}
+}
+
+func TestJobRepository_Classification(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	classified := &model.Job{
+		Company: "Google", Title: "SWE Intern, Summer 2027", URL: "https://google.com/1",
+		Classification: model.Classification{Season: "summer", Year: 2027, RoleFamily: "SWE"},
+	}
+	legacy := &model.Job{Company: "Amazon", Title: "SDE Intern", URL: "https://amazon.com/1"}
+	repo.Create(classified)
+	repo.Create(legacy)
+
+	unclassified, err := repo.GetUnclassified()
+	if err != nil {
+		t.Fatalf("failed to get unclassified: %v", err)
+	}
+	if len(unclassified) != 1 || unclassified[0].ID != legacy.ID {
+		t.Fatalf("expected only the legacy job, got %+v", unclassified)
+	}
+
+	if err := repo.UpdateClassification(legacy.ID, model.Classification{RoleFamily: "SWE"}); err != nil {
+		t.Fatalf("failed to update classification: %v", err)
+	}
+	unclassified, _ = repo.GetUnclassified()
+	if len(unclassified) != 0 {
+		t.Errorf("expected no unclassified jobs, got %d", len(unclassified))
+	}
+
+	swe, err := repo.List(JobFilter{RoleFamily: "SWE", Year: 2027})
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(swe) != 1 || swe[0].Season != "summer" {
+		t.Errorf("expected the classified job, got %+v", swe)
+	}
+
+	facets, err := repo.Facets()
+	if err != nil {
+		t.Fatalf("failed to get facets: %v", err)
+	}
+	if facets["role_family"]["SWE"] != 2 {
+		t.Errorf("expected 2 SWE jobs, got %+v", facets["role_family"])
+	}
+}
+
+func TestJobRepository_Locations(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	job := &model.Job{
+		Company:  "Google",
+		Title:    "SWE Intern",
+		URL:      "https://google.com/1",
+		Location: "Mountain View, CA; New York, NY",
+		Locations: []model.Location{
+			{Raw: "Mountain View, CA", City: "Mountain View", Region: "CA", Country: "US"},
+			{Raw: "New York, NY", City: "New York", Region: "NY", Country: "US"},
+		},
+	}
+	legacy := &model.Job{Company: "Amazon", Title: "SDE Intern", URL: "https://amazon.com/1", Location: "Seattle, WA"}
+	repo.Create(job)
+	repo.Create(legacy)
+
+	found, err := repo.GetByID(job.ID)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if len(found.Locations) != 2 || found.Locations[1].City != "New York" {
+		t.Errorf("expected locations to round-trip, got %+v", found.Locations)
+	}
+
+	missing, err := repo.GetWithoutLocations()
+	if err != nil {
+		t.Fatalf("failed to get jobs without locations: %v", err)
+	}
+	if len(missing) != 1 || missing[0].ID != legacy.ID {
+		t.Fatalf("expected only the legacy job, got %+v", missing)
+	}
+
+	err = repo.SaveLocations(legacy.ID, []model.Location{{Raw: "Seattle, WA", City: "Seattle", Region: "WA", Country: "US"}})
+	if err != nil {
+		t.Fatalf("failed to save locations: %v", err)
+	}
+
+	inNY, _ := repo.List(JobFilter{Region: "NY"})
+	if len(inNY) != 1 || inNY[0].ID != job.ID {
+		t.Errorf("expected only the New York job, got %+v", inNY)
+	}
+
+	counts, err := repo.LocationCounts()
+	if err != nil {
+		t.Fatalf("failed to count locations: %v", err)
+	}
+	if counts["country"]["US"] != 2 || counts["region"]["US-WA"] != 1 {
+		t.Errorf("unexpected counts: %+v", counts)
+	}
+}
+
+func TestJobRepository_Detail(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	deadline := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+	job := &model.Job{
+		Company: "Google",
+		Title:   "SWE Intern",
+		URL:     "https://google.com/1",
+		Detail: &model.JobDetail{
+			Description: "Build things",
+			Deadline:    &deadline,
+			Salary:      "USD 40 - 50 per HOUR",
+		},
+	}
+	repo.Create(job)
+
+	found, err := repo.GetByID(job.ID)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if found.Detail == nil {
+		t.Fatal("expected detail to be loaded")
+	}
+	if found.Detail.Description != "Build things" || found.Detail.PostedAt != nil {
+		t.Errorf("unexpected detail: %+v", found.Detail)
+	}
+	if found.Detail.Deadline == nil || !found.Detail.Deadline.Equal(deadline) {
+		t.Errorf("expected deadline %v, got %v", deadline, found.Detail.Deadline)
+	}
+
+	if err := repo.SaveDetail(job.ID, &model.JobDetail{Description: "Updated"}); err != nil {
+		t.Fatalf("failed to save detail: %v", err)
+	}
+	detail, _ := repo.GetDetail(job.ID)
+	if detail.Description != "Updated" || detail.Salary != "" {
+		t.Errorf("expected detail to be replaced, got %+v", detail)
+	}
+
+	none, err := repo.GetDetail(9999)
+	if err != nil || none != nil {
+		t.Errorf("expected nil detail for unknown job, got %+v, %v", none, err)
+	}
+}
+
+func TestJobRepository_Compensation(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	job := &model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Detail: &model.JobDetail{Salary: "USD 50 per HOUR"},
+	}
+	repo.Create(job)
+
+	missing, err := repo.GetWithoutCompensation()
+	if err != nil {
+		t.Fatalf("failed to get jobs without compensation: %v", err)
+	}
+	if len(missing) != 1 || missing[0].Detail == nil || missing[0].Detail.Salary != "USD 50 per HOUR" {
+		t.Fatalf("expected the job with its detail, got %+v", missing)
+	}
+
+	pay := &model.Compensation{Currency: "USD", Min: 50, Max: 50, Unit: "hour", HourlyMin: 50, HourlyMax: 50}
+	if err := repo.UpdateCompensation(job.ID, pay); err != nil {
+		t.Fatalf("failed to update compensation: %v", err)
+	}
+
+	found, _ := repo.GetByID(job.ID)
+	if found.Compensation == nil || *found.Compensation != *pay {
+		t.Errorf("expected compensation to round-trip, got %+v", found.Compensation)
+	}
+
+	missing, _ = repo.GetWithoutCompensation()
+	if len(missing) != 0 {
+		t.Errorf("expected no jobs without compensation, got %d", len(missing))
+	}
+
+	unpaid := &model.Job{
+		Company: "Google", Title: "Data Intern", URL: "https://google.com/2",
+		Detail: &model.JobDetail{Description: "No pay listed"},
+	}
+	repo.Create(unpaid)
+	if err := repo.UpdateCompensation(unpaid.ID, nil); err != nil {
+		t.Fatalf("failed to record missing compensation: %v", err)
+	}
+	if missing, _ = repo.GetWithoutCompensation(); len(missing) != 0 {
+		t.Errorf("expected a job parsed without pay not to be parsed again, got %d", len(missing))
+	}
+
+	if jobs, _ := repo.List(JobFilter{MaxHourly: 40}); len(jobs) != 0 {
+		t.Errorf("expected no jobs paying at most 40/hour, got %d", len(jobs))
+	}
+}
+
+func TestJobRepository_Duplicates(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	original := &model.Job{Company: "Acme", Title: "Software Engineer Intern", URL: "https://boards.greenhouse.io/acme/jobs/4012345"}
+	if err := repo.Create(original); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if original.PostingID != "4012345" {
+		t.Errorf("expected posting ID from URL, got %q", original.PostingID)
+	}
+
+	found, err := repo.GetByURL("https://boards.greenhouse.io/acme/jobs/4012345/?gh_src=li&utm_source=x")
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if found == nil || found.ID != original.ID {
+		t.Fatalf("expected tracking URL to match the stored job, got %+v", found)
+	}
+
+	repost := &model.Job{Company: "Acme", Title: "Software Engineer Intern", URL: "https://acme.com/careers?gh_jid=4012345"}
+	dup, err := repo.FindDuplicate(repost)
+	if err != nil {
+		t.Fatalf("failed to find duplicate: %v", err)
+	}
+	if dup != original.ID {
+		t.Fatalf("expected duplicate of %d, got %d", original.ID, dup)
+	}
+	repost.DuplicateOf = dup
+	repo.Create(repost)
+
+	other := &model.Job{Company: "Other", Title: "Software Engineer Intern", URL: "https://other.com/jobs/1"}
+	if dup, _ := repo.FindDuplicate(other); dup != 0 {
+		t.Errorf("expected no duplicate across companies, got %d", dup)
+	}
+
+	jobs, _ := repo.List(JobFilter{})
+	if len(jobs) != 1 || jobs[0].ID != original.ID || jobs[0].Duplicates != 1 {
+		t.Errorf("expected the canonical job with one duplicate, got %+v", jobs)
+	}
+	if jobs, _ := repo.List(JobFilter{IncludeDuplicates: true}); len(jobs) != 2 {
+		t.Errorf("expected duplicates to be included, got %d", len(jobs))
+	}
+	if unnotified, _ := repo.GetUnnotified(); len(unnotified) != 1 {
+		t.Errorf("expected duplicates excluded from unnotified jobs, got %d", len(unnotified))
+	}
+}
+
+func TestJobRepository_Revisions(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	job := &model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1", Location: "Seattle, WA",
+		Detail: &model.JobDetail{Salary: "$40/hour"},
+	}
+	repo.Create(job)
+	if job.ContentHash == "" {
+		t.Fatal("expected content hash to be set on create")
+	}
+
+	found, err := repo.GetByURL(job.URL)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if found.Detail == nil || found.ContentHash != job.ContentHash {
+		t.Fatalf("expected job with details and content hash, got %+v", found)
+	}
+
+	found.Location = "Seattle, WA; Remote"
+	found.Locations = []model.Location{{Raw: "Seattle, WA", City: "Seattle", Region: "WA", Country: "US"}, {Raw: "Remote", Remote: true}}
+	changes := []model.FieldChange{{Field: "location", Old: "Seattle, WA", New: found.Location}}
+	if err := repo.SaveRevision(found, changes); err != nil {
+		t.Fatalf("failed to save revision: %v", err)
+	}
+
+	updated, _ := repo.GetByID(job.ID)
+	if updated.Location != "Seattle, WA; Remote" || len(updated.Locations) != 2 {
+		t.Errorf("expected updated location, got %q with %d locations", updated.Location, len(updated.Locations))
+	}
+	if updated.ContentHash == job.ContentHash {
+		t.Error("expected content hash to change")
+	}
+
+	revisions, err := repo.GetRevisions(job.ID)
+	if err != nil {
+		t.Fatalf("failed to get revisions: %v", err)
+	}
+	if len(revisions) != 1 || len(revisions[0].Changes) != 1 || revisions[0].Changes[0] != changes[0] {
+		t.Errorf("expected the location change, got %+v", revisions)
+	}
+}
+
+func TestJobRepository_Snapshots(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobRepository(database)
+
+	taken := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	job := &model.Job{
+		Company: "Google", Title: "SWE Intern", URL: "https://google.com/1",
+		Snapshot: &model.Snapshot{HTMLHash: "aa", TextHash: "bb", Size: 42, TakenAt: taken},
+	}
+	repo.Create(job)
+	repo.Create(&model.Job{Company: "Google", Title: "Data Intern", URL: "https://google.com/2"})
+
+	jobs, err := repo.GetWithSnapshots()
+	if err != nil {
+		t.Fatalf("failed to get jobs with snapshots: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Snapshot == nil || jobs[0].Snapshot.Size != 42 || !jobs[0].Snapshot.TakenAt.Equal(taken) {
+		t.Fatalf("expected the snapshot to round-trip, got %+v", jobs)
+	}
+
+	if err := repo.ClearSnapshot(job.ID); err != nil {
+		t.Fatalf("failed to clear snapshot: %v", err)
+	}
+	if found, _ := repo.GetByID(job.ID); found.Snapshot != nil {
+		t.Errorf("expected snapshot to be cleared, got %+v", found.Snapshot)
+	}
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"math"
+	"time"
 
 	"intern-job-tracker/internal/model"
 )
@@ -92,3 +94,129 @@ func (r *RunLogRepository) GetStats() (map[string]interface{}, error) {
 
 	return stats, nil
 }
+
+// GetLastSuccess returns when the most recent successful run happened, or nil
+// if no run has succeeded.
+func (r *RunLogRepository) GetLastSuccess() (*time.Time, error) {
+	var runAt time.Time
+	err := r.db.QueryRow(`SELECT run_at FROM run_logs WHERE status = 'success' ORDER BY run_at DESC, id DESC LIMIT 1`).Scan(&runAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &runAt, nil
+}
+
+// CreateCompanyRuns records the per-company results of a run.
+func (r *RunLogRepository) CreateCompanyRuns(runID int64, runs []*model.CompanyRun) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range runs {
+		result, err := tx.Exec(
+			`INSERT INTO company_runs (run_id, company_id, company, status, http_status, bytes, pages, jobs_found, new_jobs,
+				duration_ms, error_class, error_message, fingerprint, challenge)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			runID, c.CompanyID, c.Company, c.Status, nullInt(c.HTTPStatus), c.Bytes, c.Pages, c.JobsFound, c.NewJobs,
+			c.DurationMs, nullString(c.ErrorClass), nullString(c.ErrorMessage), nullString(c.Fingerprint), c.Challenge,
+		)
+		if err != nil {
+			return err
+		}
+		c.ID, _ = result.LastInsertId()
+		c.RunID = runID
+	}
+	return tx.Commit()
+}
+
+// GetCompanyRuns returns a company's most recent scrape results, newest first.
+func (r *RunLogRepository) GetCompanyRuns(companyID int64, limit int) ([]*model.CompanyRun, error) {
+	rows, err := r.db.Query(
+		`SELECT id, COALESCE(run_id, 0), company_id, company, run_at, status, COALESCE(http_status, 0), bytes, pages,
+			jobs_found, new_jobs, duration_ms, COALESCE(error_class, ''), COALESCE(error_message, ''),
+			COALESCE(fingerprint, ''), COALESCE(challenge, FALSE)
+		 FROM company_runs WHERE company_id = ? ORDER BY run_at DESC, id DESC LIMIT ?`,
+		companyID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*model.CompanyRun
+	for rows.Next() {
+		c := &model.CompanyRun{}
+		err := rows.Scan(&c.ID, &c.RunID, &c.CompanyID, &c.Company, &c.RunAt, &c.Status, &c.HTTPStatus, &c.Bytes, &c.Pages,
+			&c.JobsFound, &c.NewJobs, &c.DurationMs, &c.ErrorClass, &c.ErrorMessage,
+			&c.Fingerprint, &c.Challenge)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, c)
+	}
+	return runs, rows.Err()
+}
+
+// GetCompanyHealth summarizes a company's last window scrapes.
+func (r *RunLogRepository) GetCompanyHealth(company *model.Company, window int) (*model.CompanyHealth, error) {
+	runs, err := r.GetCompanyRuns(company.ID, window)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeHealth(company, runs), nil
+}
+
+// summarizeHealth computes a company's health from its scrape results,
+// newest first. A company is flagged "failing" when its latest scrape failed
+// or fewer than half of its scrapes succeeded, and "empty" when its latest
+// successful scrape found no jobs although earlier ones usually did.
+func summarizeHealth(company *model.Company, runs []*model.CompanyRun) *model.CompanyHealth {
+	h := &model.CompanyHealth{
+		CompanyID: company.ID,
+		Company:   company.Name,
+		Runs:      len(runs),
+		Flags:     []string{},
+		Trend:     make([]*model.CompanyRun, len(runs)),
+	}
+	if len(runs) == 0 {
+		return h
+	}
+
+	var successes []*model.CompanyRun
+	for i, run := range runs {
+		h.Trend[len(runs)-1-i] = run
+		if run.Status == "success" {
+			successes = append(successes, run)
+		}
+	}
+	h.LastRun = &runs[0].RunAt
+	h.SuccessRate = math.Round(float64(len(successes))/float64(len(runs))*100) / 100
+
+	if len(successes) > 0 {
+		h.LastSuccess = &successes[0].RunAt
+		total := 0
+		for _, run := range successes {
+			total += run.JobsFound
+		}
+		h.AvgJobsFound = math.Round(float64(total)/float64(len(successes))*10) / 10
+	}
+
+	if runs[0].Status != "success" || h.SuccessRate < 0.5 {
+		h.Flags = append(h.Flags, "failing")
+	}
+	if len(successes) > 1 && successes[0].JobsFound == 0 {
+		earlier := 0
+		for _, run := range successes[1:] {
+			earlier += run.JobsFound
+		}
+		if float64(earlier)/float64(len(successes)-1) >= 1 {
+			h.Flags = append(h.Flags, "empty")
+		}
+	}
+	return h
+}
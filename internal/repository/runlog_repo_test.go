@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestRunLogRepository_CompanyRuns(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRunLogRepository(database)
+
+	run := &model.RunLog{Status: "success"}
+	if err := repo.Create(run); err != nil {
+		t.Fatalf("failed to create run log: %v", err)
+	}
+	results := []*model.CompanyRun{
+		{CompanyID: 1, Company: "Google", Status: "success", HTTPStatus: 200, Bytes: 5120, Pages: 1, JobsFound: 4, NewJobs: 1, DurationMs: 800},
+		{CompanyID: 2, Company: "Uber", Status: "error", HTTPStatus: 503, Pages: 1, ErrorClass: "http_status", ErrorMessage: "unexpected status code 503"},
+	}
+	if err := repo.CreateCompanyRuns(run.ID, results); err != nil {
+		t.Fatalf("failed to create company runs: %v", err)
+	}
+
+	runs, err := repo.GetCompanyRuns(2, 10)
+	if err != nil {
+		t.Fatalf("failed to get company runs: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	got := runs[0]
+	if got.RunID != run.ID || got.HTTPStatus != 503 || got.ErrorClass != "http_status" || got.RunAt.IsZero() {
+		t.Errorf("unexpected company run: %+v", got)
+	}
+
+	health, err := repo.GetCompanyHealth(&model.Company{ID: 1, Name: "Google"}, 30)
+	if err != nil {
+		t.Fatalf("failed to get health: %v", err)
+	}
+	if health.Runs != 1 || health.SuccessRate != 1 || health.LastSuccess == nil || len(health.Flags) != 0 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+}
+
+func TestRunLogRepository_GetLastSuccess(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRunLogRepository(database)
+
+	last, err := repo.GetLastSuccess()
+	if err != nil || last != nil {
+		t.Fatalf("expected no last success, got %v (%v)", last, err)
+	}
+
+	repo.Create(&model.RunLog{Status: "success"})
+	database.Exec(`UPDATE run_logs SET run_at = '2026-10-01 09:00:00'`)
+	repo.Create(&model.RunLog{Status: "error", ErrorMessage: "database locked"})
+
+	last, err = repo.GetLastSuccess()
+	if err != nil {
+		t.Fatalf("failed to get last success: %v", err)
+	}
+	if last == nil || last.Format("2006-01-02 15:04") != "2026-10-01 09:00" {
+		t.Errorf("expected the successful run's time, got %v", last)
+	}
+}
+
+func TestSummarizeHealth(t *testing.T) {
+	now := time.Now()
+	run := func(hoursAgo int, status string, jobs int) *model.CompanyRun {
+		return &model.CompanyRun{RunAt: now.Add(-time.Duration(hoursAgo) * time.Hour), Status: status, JobsFound: jobs}
+	}
+	company := &model.Company{ID: 1, Name: "Uber"}
+
+	tests := []struct {
+		name  string
+		runs  []*model.CompanyRun // newest first
+		flags []string
+	}{
+		{"healthy", []*model.CompanyRun{run(0, "success", 5), run(24, "success", 4)}, nil},
+		{"latest failed", []*model.CompanyRun{run(0, "error", 0), run(24, "success", 4)}, []string{"failing"}},
+		{"mostly failing", []*model.CompanyRun{run(0, "success", 3), run(24, "error", 0), run(48, "error", 0)}, []string{"failing"}},
+		{"suddenly empty", []*model.CompanyRun{run(0, "success", 0), run(24, "success", 6), run(48, "success", 5)}, []string{"empty"}},
+		{"always empty", []*model.CompanyRun{run(0, "success", 0), run(24, "success", 0)}, nil},
+		{"no runs", nil, nil},
+	}
+	for _, tt := range tests {
+		h := summarizeHealth(company, tt.runs)
+		if len(h.Flags) != len(tt.flags) {
+			t.Errorf("%s: expected flags %v, got %v", tt.name, tt.flags, h.Flags)
+			continue
+		}
+		for i := range tt.flags {
+			if h.Flags[i] != tt.flags[i] {
+				t.Errorf("%s: expected flags %v, got %v", tt.name, tt.flags, h.Flags)
+			}
+		}
+	}
+
+	h := summarizeHealth(company, []*model.CompanyRun{run(0, "error", 0), run(24, "success", 4), run(48, "success", 2)})
+	if h.SuccessRate != 0.67 || h.AvgJobsFound != 3 || !h.LastSuccess.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("unexpected summary: %+v", h)
+	}
+	if h.Trend[0].RunAt.After(h.Trend[2].RunAt) {
+		t.Error("expected trend oldest first")
+	}
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+// AlertRules control the notifications sent when a run goes wrong.
+type AlertRules struct {
+	// FailurePercent sends an alert when more than this percentage of the
+	// companies checked by a run fail. Zero disables the check. Runs ending
+	// with status error always alert.
+	FailurePercent float64
+	// PingURL is requested at the end of every run, healthchecks.io style:
+	// failed runs request PingURL + "/fail". Empty disables pinging.
+	PingURL string
+}
+
+// pingTimeout bounds the request to the ping URL, so that a slow monitoring
+// service cannot hold up a run.
+const pingTimeout = 10 * time.Second
+
+// alert notifies about a finished run that failed, or in which too many
+// companies failed.
+func (s *Scheduler) alert(logger *slog.Logger, runLog *model.RunLog, companyRuns []*model.CompanyRun) {
+	s.mu.Lock()
+	rules := s.alerts
+	s.mu.Unlock()
+
+	var msg string
+	if runLog.Status == "error" {
+		msg = fmt.Sprintf("❌ Job check failed\n\n%s\n\n⏰ %s", orNone(runLog.ErrorMessage), runLog.RunAt.Format("2006-01-02 15:04"))
+	} else if failed := failedCompanies(companyRuns); rules.FailurePercent > 0 && len(companyRuns) > 0 &&
+		float64(len(failed))*100/float64(len(companyRuns)) > rules.FailurePercent {
+		msg = fmt.Sprintf("⚠️ Job check degraded\n\n%d of %d companies failed:\n• %s\n\n⏰ %s",
+			len(failed), len(companyRuns), strings.Join(failed, "\n• "), runLog.RunAt.Format("2006-01-02 15:04"))
+	}
+	if msg == "" {
+		return
+	}
+
+	logger.Warn("sending run failure alert", "status", runLog.Status)
+	if err := s.notifier.Send(s.getRecipient(), msg); err != nil {
+		logger.Error("failed to send failure alert", "error", err)
+	}
+}
+
+// failedCompanies describes each failed company scrape.
+func failedCompanies(companyRuns []*model.CompanyRun) []string {
+	var failed []string
+	for _, run := range companyRuns {
+		if run.Status == "success" {
+			continue
+		}
+		desc := run.Company
+		if run.ErrorClass != "" {
+			desc += " (" + run.ErrorClass + ")"
+		}
+		failed = append(failed, desc)
+	}
+	return failed
+}
+
+// ping reports a finished run to the configured ping URL.
+func (s *Scheduler) ping(logger *slog.Logger, runLog *model.RunLog) {
+	s.mu.Lock()
+	url := s.alerts.PingURL
+	s.mu.Unlock()
+	if url == "" {
+		return
+	}
+
+	if runLog.Status == "error" {
+		url = strings.TrimRight(url, "/") + "/fail"
+	}
+	client := &http.Client{Timeout: pingTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		logger.Warn("ping failed", "url", url, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		logger.Warn("ping failed", "url", url, "http_status", resp.StatusCode)
+	}
+}
+
+// SetAlertRules updates the rules deciding when a run sends a failure alert.
+func (s *Scheduler) SetAlertRules(rules AlertRules) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = rules
+}
@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"intern-job-tracker/internal/model"
+	"intern-job-tracker/internal/scraper"
+
+	"github.com/robfig/cron/v3"
+)
+
+// minInterval is the shortest interval a company may be checked at.
+const minInterval = time.Minute
+
+// ParseSchedule parses a company's check frequency: a cron expression such
+// as "*/15 * * * *", or an interval such as "15m" or "168h".
+func ParseSchedule(expr string) (cron.Schedule, error) {
+	if d, err := time.ParseDuration(expr); err == nil {
+		if d < minInterval {
+			return nil, fmt.Errorf("interval %s is shorter than %s", d, minInterval)
+		}
+	}
+	return cron.ParseStandard(cronSpec(expr))
+}
+
+// ValidateCompany checks a company's own schedule, active date range and
+// scrape source.
+func ValidateCompany(company *model.Company) error {
+	if company.Schedule != "" {
+		if _, err := ParseSchedule(company.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %v", err)
+		}
+	}
+	for _, date := range []string{company.ActiveFrom, company.ActiveUntil} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if company.ActiveFrom != "" && company.ActiveUntil != "" && company.ActiveFrom > company.ActiveUntil {
+		return fmt.Errorf("active_from must not be after active_until")
+	}
+	return scraper.ValidateSource(company.SourceType, company.SourceID, company.Selectors)
+}
+
+// cronSpec turns an interval into the equivalent "@every" cron spec and
+// leaves cron expressions as they are.
+func cronSpec(expr string) string {
+	if d, err := time.ParseDuration(expr); err == nil {
+		return "@every " + d.String()
+	}
+	return expr
+}
+
+// dueSet lists the companies a scheduled run checks.
+type dueSet struct {
+	// defaults covers every company without a schedule of its own.
+	defaults bool
+	// ids are the companies whose own schedule fired.
+	ids map[int64]bool
+}
+
+// includes reports whether a company is due. A nil set, as used by manual
+// runs, includes every company.
+func (d *dueSet) includes(company *model.Company) bool {
+	if d == nil {
+		return true
+	}
+	return d.ids[company.ID] || (d.defaults && company.Schedule == "")
+}
+
+// markDue records companies as due and checks them. If another run is active,
+// they are checked as soon as it finishes; companies that come due several
+// times meanwhile are checked once.
+func (s *Scheduler) markDue(defaults bool, ids ...int64) {
+	s.dueMu.Lock()
+	if s.due == nil {
+		s.due = &dueSet{ids: make(map[int64]bool)}
+	}
+	s.due.defaults = s.due.defaults || defaults
+	for _, id := range ids {
+		s.due.ids[id] = true
+	}
+	s.dueMu.Unlock()
+
+	s.runDue()
+}
+
+// runDue checks the companies that are due, one run after another, until
+// none are left or another run is active. The run that is active then calls
+// runDue again when it finishes.
+func (s *Scheduler) runDue() {
+	for {
+		s.dueMu.Lock()
+		if s.due == nil || s.isClosing() {
+			s.dueMu.Unlock()
+			return
+		}
+		run, created := s.runs.start("scheduled")
+		if !created {
+			s.dueMu.Unlock()
+			slog.Info("run in progress, checking due companies once it finishes", "run_id", run.ID)
+			return
+		}
+		due := s.due
+		s.due = nil
+		s.dueMu.Unlock()
+
+		if err := s.execute(run, due); err != nil {
+			slog.Error("scheduled check failed", "run_id", run.ID, "error", err)
+		}
+	}
+}
+
+// companyEntry is the cron entry checking a company on its own schedule.
+type companyEntry struct {
+	id   cron.EntryID
+	spec string
+}
+
+// companySpecs returns the cron spec of each enabled company with its own
+// schedule. Companies with an invalid schedule are left out.
+func (s *Scheduler) companySpecs() (map[int64]string, error) {
+	specs := make(map[int64]string)
+	if s.companyRepo == nil {
+		return specs, nil
+	}
+	companies, err := s.companyRepo.GetEnabled()
+	if err != nil {
+		return nil, err
+	}
+	for _, company := range companies {
+		if company.Schedule == "" {
+			continue
+		}
+		if _, err := ParseSchedule(company.Schedule); err != nil {
+			slog.Warn("ignoring invalid company schedule", "company", company.Name, "company_id", company.ID, "schedule", company.Schedule, "error", err)
+			continue
+		}
+		specs[company.ID] = cronSpec(company.Schedule)
+	}
+	return specs, nil
+}
+
+// addCompanyEntry adds a cron entry checking a company and records it in
+// entries.
+func (s *Scheduler) addCompanyEntry(c *cron.Cron, entries map[int64]companyEntry, id int64, spec string) {
+	entryID, err := c.AddFunc(spec, func() { s.markDue(false, id) })
+	if err != nil {
+		slog.Warn("failed to schedule company", "company_id", id, "schedule", spec, "error", err)
+		return
+	}
+	entries[id] = companyEntry{id: entryID, spec: spec}
+}
+
+// SyncCompanySchedules re-reads the companies' own schedules and updates the
+// cron entries of those whose schedule changed, leaving the others, and the
+// time they next fire, alone. Call it after companies are added, changed or
+// removed.
+func (s *Scheduler) SyncCompanySchedules() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cron == nil {
+		return nil
+	}
+	specs, err := s.companySpecs()
+	if err != nil {
+		return err
+	}
+	var added, removed int
+	for id, entry := range s.companyEntries {
+		if specs[id] != entry.spec {
+			s.cron.Remove(entry.id)
+			delete(s.companyEntries, id)
+			removed++
+		}
+	}
+	for id, spec := range specs {
+		if _, ok := s.companyEntries[id]; !ok {
+			s.addCompanyEntry(s.cron, s.companyEntries, id, spec)
+			added++
+		}
+	}
+	if added > 0 || removed > 0 {
+		slog.Info("updated company schedules", "added", added, "removed", removed, "count", len(s.companyEntries))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package scheduler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"intern-job-tracker/internal/metrics"
+)
+
+var (
+	runsTotal = metrics.Default.NewCounterVec(prometheus.CounterOpts{
+		Name: "intern_tracker_runs_total",
+		Help: "Job check runs by final status.",
+	}, []string{"status"})
+	runDuration = metrics.Default.NewHistogram(prometheus.HistogramOpts{
+		Name:    "intern_tracker_run_duration_seconds",
+		Help:    "Time taken by a job check run.",
+		Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600},
+	})
+	jobsDiscovered = metrics.Default.NewCounterVec(prometheus.CounterOpts{
+		Name: "intern_tracker_jobs_discovered_total",
+		Help: "New jobs saved, by company.",
+	}, []string{"company"})
+	enabledCompanies = metrics.Default.NewGauge(prometheus.GaugeOpts{
+		Name: "intern_tracker_enabled_companies",
+		Help: "Companies enabled at the most recent run, whether or not they were due.",
+	})
+	outboxDepth = metrics.Default.NewGauge(prometheus.GaugeOpts{
+		Name: "intern_tracker_notification_outbox_depth",
+		Help: "Job notifications queued and not yet delivered.",
+	})
+)
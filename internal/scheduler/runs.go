@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+// ErrRunInProgress is returned by RunNow when another run has not finished.
+var ErrRunInProgress = errors.New("a run is already in progress")
+
+// keptRuns is how many finished runs are remembered for status lookups.
+const keptRuns = 50
+
+// runTracker hands out run IDs and tracks the state of recent runs. At most
+// one run is active, queued or running, at a time.
+type runTracker struct {
+	mu        sync.Mutex
+	nextID    int64
+	active    *model.Run
+	done      chan struct{} // closed when the active run finishes
+	runs      []*model.Run  // oldest first
+	cancelled map[int64]bool
+}
+
+// start registers a new queued run and makes it the active run. If a run is
+// already active, it is returned instead and created is false.
+func (t *runTracker) start(trigger string) (run *model.Run, created bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.active != nil {
+		return t.active, false
+	}
+	t.nextID++
+	run = &model.Run{
+		ID:       t.nextID,
+		State:    model.RunQueued,
+		Trigger:  trigger,
+		QueuedAt: time.Now(),
+	}
+	t.runs = append(t.runs, run)
+	if len(t.runs) > keptRuns {
+		delete(t.cancelled, t.runs[0].ID)
+		t.runs = t.runs[1:]
+	}
+	t.active = run
+	t.done = make(chan struct{})
+	return run, true
+}
+
+// update applies a change to a run while holding the lock.
+func (t *runTracker) update(run *model.Run, change func(r *model.Run)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	change(run)
+}
+
+// finish moves a run to a final state and clears it as the active run. It
+// reports false, changing nothing, if the run had already finished.
+func (t *runTracker) finish(run *model.Run, state, errMsg string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if run.FinishedAt != nil {
+		return false
+	}
+	now := time.Now()
+	run.State = state
+	run.FinishedAt = &now
+	run.Company = ""
+	run.Error = errMsg
+	if t.active == run {
+		t.active = nil
+		close(t.done)
+	}
+	return true
+}
+
+// finishActive moves the active run, if any, to a final state and returns a
+// copy of it.
+func (t *runTracker) finishActive(state, errMsg string) *model.Run {
+	t.mu.Lock()
+	run := t.active
+	t.mu.Unlock()
+
+	if run == nil || !t.finish(run, state, errMsg) {
+		return nil
+	}
+	return t.snapshot(run)
+}
+
+// idle returns a channel that is closed once no run is active.
+func (t *runTracker) idle() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.active == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return t.done
+}
+
+// get returns a copy of a remembered run.
+func (t *runTracker) get(id int64) (*model.Run, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, run := range t.runs {
+		if run.ID == id {
+			return t.copy(run), true
+		}
+	}
+	return nil, false
+}
+
+// current returns a copy of the active run, or nil if there is none.
+func (t *runTracker) current() *model.Run {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.active == nil {
+		return nil
+	}
+	return t.copy(t.active)
+}
+
+// snapshot returns a copy of a run that is safe to read without the lock.
+func (t *runTracker) snapshot(run *model.Run) *model.Run {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.copy(run)
+}
+
+func (t *runTracker) copy(run *model.Run) *model.Run {
+	c := *run
+	return &c
+}
+
+// cancel asks the active run to stop. It reports whether the run was active.
+func (t *runTracker) cancel(id int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.active == nil || t.active.ID != id {
+		return false
+	}
+	if t.cancelled == nil {
+		t.cancelled = make(map[int64]bool)
+	}
+	t.cancelled[id] = true
+	return true
+}
+
+// isCancelled reports whether a run was asked to stop.
+func (t *runTracker) isCancelled(run *model.Run) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cancelled[run.ID]
+}
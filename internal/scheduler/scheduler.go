@@ -1,13 +1,20 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
+	"intern-job-tracker/internal/anomaly"
+	"intern-job-tracker/internal/events"
+	"intern-job-tracker/internal/history"
+	"intern-job-tracker/internal/logging"
 	"intern-job-tracker/internal/model"
 	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
 
 	"github.com/robfig/cron/v3"
 )
@@ -15,7 +22,8 @@ import (
 // Scraper interface for job scraping.
 type Scraper interface {
 	ScrapeAll() ([]*model.Job, error)
-	ScrapeCompany(config scraper.CompanyConfig) ([]*model.Job, error)
+	// Scrape scrapes one company. The result must not be nil, even on error.
+	Scrape(config scraper.CompanyConfig) (*scraper.Result, error)
 }
 
 // Repository interface for job storage.
@@ -23,6 +31,10 @@ type Repository interface {
 	Create(job *model.Job) error
 	GetByURL(url string) (*model.Job, error)
 	MarkNotified(id int64) error
+	// FindDuplicate returns the ID of a stored job the job duplicates, or 0.
+	FindDuplicate(job *model.Job) (int64, error)
+	// SaveRevision stores a changed version of an existing job.
+	SaveRevision(job *model.Job, changes []model.FieldChange) error
 }
 
 // CompanyRepository interface for company storage.
@@ -33,6 +45,16 @@ type CompanyRepository interface {
 // RunLogRepository interface for run logs.
 type RunLogRepository interface {
 	Create(log *model.RunLog) error
+	CreateCompanyRuns(runID int64, runs []*model.CompanyRun) error
+	// GetCompanyRuns returns a company's most recent results, newest first.
+	GetCompanyRuns(companyID int64, limit int) ([]*model.CompanyRun, error)
+}
+
+// IncidentRepository interface for scrape incident storage.
+type IncidentRepository interface {
+	Open(incident *model.Incident) error
+	Resolve(id int64) error
+	GetOpen(companyID int64) ([]*model.Incident, error)
 }
 
 // Notifier interface for sending notifications.
@@ -41,6 +63,37 @@ type Notifier interface {
 	Send(recipient string, message string) error
 }
 
+// Publisher receives the events of a run, such as jobs being found, as they
+// happen.
+type Publisher interface {
+	Publish(e events.Event)
+}
+
+// Enricher adds derived information, such as a relevance score, to a newly
+// discovered job before it is saved. Enrichers run in the order they were added.
+type Enricher interface {
+	Enrich(job *model.Job)
+}
+
+// ContextEnricher is an Enricher that logs. The scheduler calls EnrichContext
+// instead of Enrich, with the logger of the run and company carried by ctx.
+type ContextEnricher interface {
+	Enricher
+	EnrichContext(ctx context.Context, job *model.Job)
+}
+
+// NotificationRules control which new jobs trigger a notification.
+type NotificationRules struct {
+	// MinScore is the minimum relevance score a job needs to be notified.
+	// Jobs below it are still saved and shown on the dashboard. Zero disables
+	// the check.
+	MinScore float64
+	// WatchedChanges sends a notification when a watched job's posting changes.
+	WatchedChanges bool
+	// Mode is one of the settings.Notify modes. Empty means settings.NotifyAll.
+	Mode string
+}
+
 // Scheduler manages the job checking schedule.
 type Scheduler struct {
 	repo        Repository
@@ -49,8 +102,28 @@ type Scheduler struct {
 	scraper     Scraper
 	notifier    Notifier
 	recipient   string
+	enrichers   []Enricher
+	rules       NotificationRules
+	alerts      AlertRules
+	incidents   IncidentRepository
+	detector    anomaly.Detector
+	events      Publisher
+	concurrency int
+	schedule    string
+	location    *time.Location
+	runs        runTracker
 	cron        *cron.Cron
-	mu          sync.Mutex
+	// companyEntries are cron's entries for companies with their own
+	// schedule, by company ID.
+	companyEntries map[int64]companyEntry
+	mu             sync.Mutex
+	due            *dueSet // companies waiting for a scheduled check
+	state          StateStore
+	lastFire       *time.Time // when the global schedule last fired
+	paused         bool
+	closing        bool       // no new runs start once Shutdown is called
+	closed         bool       // nothing is saved once Shutdown returns
+	dueMu          sync.Mutex // held while due companies are handed to a run
 }
 
 // New creates a new Scheduler.
@@ -70,26 +143,73 @@ func (s *Scheduler) Start() error {
 	return s.StartWithSchedule("0 9 * * *")
 }
 
-// StartWithSchedule begins job checking with a custom cron schedule.
+// StartWithSchedule begins job checking with a custom cron schedule. While
+// the scheduler is paused, the schedule is only remembered until Resume.
 func (s *Scheduler) StartWithSchedule(schedule string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.cron = cron.New()
-	_, err := s.cron.AddFunc(schedule, func() {
-		if err := s.RunNow(); err != nil {
-			log.Printf("❌ Error during scheduled check: %v", err)
-		}
-	})
+	c, entries, err := s.newCron(schedule, s.location)
+	if err != nil {
+		return err
+	}
+	s.schedule = schedule
+	if s.paused {
+		slog.Info("scheduler paused, not starting schedule", "schedule", schedule)
+		return nil
+	}
+	if s.cron != nil {
+		s.cron.Stop()
+	}
+	s.cron, s.companyEntries = c, entries
+	s.cron.Start()
+	slog.Info("scheduler started", "schedule", schedule)
+	return nil
+}
+
+// SetSchedule changes the cron schedule and the time zone it is evaluated in.
+// A nil location means the server's local time. If the scheduler is started,
+// the new schedule replaces the old one immediately.
+func (s *Scheduler) SetSchedule(schedule string, loc *time.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, entries, err := s.newCron(schedule, loc)
 	if err != nil {
 		return err
 	}
+	s.schedule = schedule
+	s.location = loc
+	if s.cron == nil {
+		return nil
+	}
 
+	s.cron.Stop()
+	s.cron, s.companyEntries = c, entries
 	s.cron.Start()
-	log.Printf("⏰ Scheduler started with schedule: %s", schedule)
+	slog.Info("schedule changed", "schedule", schedule, "timezone", c.Location().String())
 	return nil
 }
 
+// newCron creates a cron runner with an entry checking the companies that
+// follow the global schedule, and one entry per company with its own, which
+// it also returns by company ID.
+func (s *Scheduler) newCron(schedule string, loc *time.Location) (*cron.Cron, map[int64]companyEntry, error) {
+	c := cron.New(cron.WithLocation(locationOrLocal(loc)))
+	if _, err := c.AddFunc(schedule, s.runScheduled); err != nil {
+		return nil, nil, err
+	}
+	entries := make(map[int64]companyEntry)
+	specs, err := s.companySpecs()
+	if err != nil {
+		slog.Warn("failed to load company schedules", "error", err)
+	}
+	for id, spec := range specs {
+		s.addCompanyEntry(c, entries, id, spec)
+	}
+	return c, entries, nil
+}
+
 // Stop stops the scheduler.
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
@@ -97,21 +217,200 @@ func (s *Scheduler) Stop() {
 
 	if s.cron != nil {
 		s.cron.Stop()
-		log.Println("⏹️  Scheduler stopped")
+		s.cron, s.companyEntries = nil, nil
+		slog.Info("scheduler stopped")
 	}
 }
 
-// RunNow performs an immediate job check.
+// runScheduled checks the companies that follow the global schedule.
+func (s *Scheduler) runScheduled() {
+	s.recordFire(time.Now())
+	s.markDue(true)
+}
+
+// RunNow checks every active company immediately and waits for the check to
+// finish. It returns ErrRunInProgress if another run has not finished yet.
 func (s *Scheduler) RunNow() error {
+	run, created := s.runs.start("manual")
+	if !created {
+		return ErrRunInProgress
+	}
+	defer func() { go s.runDue() }()
+	return s.execute(run, nil)
+}
+
+// RunCompanies checks the given companies immediately, whatever their own
+// schedules, waits for the check to finish and returns the finished run.
+// Disabled companies and companies outside their active dates are skipped,
+// and no summary is sent. It returns ErrRunInProgress if another run has not
+// finished yet.
+func (s *Scheduler) RunCompanies(ids ...int64) (*model.Run, error) {
+	run, created := s.runs.start("manual")
+	if !created {
+		return nil, ErrRunInProgress
+	}
+	defer func() { go s.runDue() }()
+	due := &dueSet{ids: make(map[int64]bool)}
+	for _, id := range ids {
+		due.ids[id] = true
+	}
+	err := s.execute(run, due)
+	return s.runs.snapshot(run), err
+}
+
+// Enqueue starts a job check in the background and returns it. Only one run
+// executes at a time: while one is queued or running, requests are coalesced
+// into it, so the active run is returned and created is false.
+func (s *Scheduler) Enqueue() (run *model.Run, created bool) {
+	run, created = s.runs.start("manual")
+	if created {
+		go func(run *model.Run) {
+			s.execute(run, nil)
+			s.runDue()
+		}(run)
+	}
+	return s.runs.snapshot(run), created
+}
+
+// GetRun returns the state of a recent run.
+func (s *Scheduler) GetRun(id int64) (*model.Run, bool) {
+	return s.runs.get(id)
+}
+
+// CancelRun asks a queued or running run to stop. A running check stops
+// before the next company. It reports whether the run was still active.
+func (s *Scheduler) CancelRun(id int64) bool {
+	return s.runs.cancel(id)
+}
+
+// execute performs a tracked run of the due companies, or of every company
+// if due is nil. A panic during the check is recorded as a failed run, so
+// that it is alerted on rather than going unnoticed.
+func (s *Scheduler) execute(run *model.Run, due *dueSet) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger := runLogger(run)
+			logger.Error("job check crashed", "panic", r)
+			err = fmt.Errorf("crashed: %v", r)
+			runLog := &model.RunLog{RunAt: time.Now(), Status: "error", ErrorMessage: err.Error()}
+			s.saveRunLog(logger, runLog, runLog.RunAt, nil)
+			s.finishRun(run, model.RunFailed, err.Error())
+		}
+	}()
+
+	if s.runs.isCancelled(run) {
+		s.finishRun(run, model.RunCancelled, "")
+		return nil
+	}
+	s.runs.update(run, func(r *model.Run) {
+		now := time.Now()
+		r.State = model.RunRunning
+		r.StartedAt = &now
+	})
+	s.publish(run, events.RunStarted, s.runs.snapshot(run))
+
+	runLog, err := s.check(run, due)
+	s.runs.update(run, func(r *model.Run) { r.RunLogID = runLog.ID })
+	switch {
+	case err != nil:
+		s.finishRun(run, model.RunFailed, err.Error())
+	case runLog.Status == "cancelled":
+		s.finishRun(run, model.RunCancelled, "")
+	case runLog.Status == "skipped":
+		s.finishRun(run, model.RunDone, "")
+	case runLog.Status != "success":
+		s.finishRun(run, model.RunFailed, runLog.ErrorMessage)
+	default:
+		s.finishRun(run, model.RunDone, "")
+	}
+	return err
+}
+
+// scrapeResult is the outcome of one company's scrape.
+type scrapeResult struct {
+	result *scraper.Result
+	err    error
+}
+
+// scrapeCompanies scrapes companies in the background, at most the configured
+// concurrency at a time, and returns one channel per company that receives
+// its result. Once the run is cancelled, companies not yet started are
+// skipped and their channels closed without a result.
+func (s *Scheduler) scrapeCompanies(run *model.Run, companies []*model.Company) []chan scrapeResult {
+	s.mu.Lock()
+	limit := s.concurrency
+	s.mu.Unlock()
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]chan scrapeResult, len(companies))
+	for i := range results {
+		results[i] = make(chan scrapeResult, 1)
+	}
+
+	go func() {
+		sem := make(chan struct{}, limit)
+		for i, company := range companies {
+			sem <- struct{}{}
+			if s.runs.isCancelled(run) {
+				for _, ch := range results[i:] {
+					close(ch)
+				}
+				return
+			}
+			go func(i int, company *model.Company) {
+				defer func() { <-sem }()
+				results[i] <- s.scrapeCompany(run, company, i+1, len(companies))
+			}(i, company)
+		}
+	}()
+	return results
+}
+
+// scrapeCompany scrapes one company of a run. A panicking scraper is reported
+// as a failed scrape.
+func (s *Scheduler) scrapeCompany(run *model.Run, company *model.Company, index, total int) (sr scrapeResult) {
+	companyLogger(runLogger(run), company).Debug("scraping company", "url", company.CareerURL, "index", index, "total", total)
+	s.runs.update(run, func(r *model.Run) { r.Company = company.Name })
+	s.publish(run, events.CompanyStarted, map[string]interface{}{
+		"company": company.Name, "index": index, "total": total,
+	})
+
+	defer func() {
+		if r := recover(); r != nil {
+			sr = scrapeResult{&scraper.Result{ErrorClass: "panic"}, fmt.Errorf("scraper crashed: %v", r)}
+		}
+	}()
+	config, err := scraper.ConfigFor(company)
+	if err != nil {
+		return scrapeResult{&scraper.Result{ErrorClass: "config"}, err}
+	}
+	result, err := s.scraper.Scrape(config)
+	return scrapeResult{result, err}
+}
+
+// finishRun moves a run to its final state and announces it, unless it was
+// already finished, as happens to runs interrupted by Shutdown.
+func (s *Scheduler) finishRun(run *model.Run, state, errMsg string) {
+	if s.runs.finish(run, state, errMsg) {
+		s.publish(run, events.RunFinished, s.runs.snapshot(run))
+	}
+}
+
+// check scrapes the due companies that are enabled and within their active
+// date range, saving and notifying new jobs, and returns the run log it
+// recorded. If no company is due, nothing is recorded and the run log's
+// status is "skipped".
+func (s *Scheduler) check(run *model.Run, due *dueSet) (*model.RunLog, error) {
 	startTime := time.Now()
 	runLog := &model.RunLog{
 		RunAt:  startTime,
 		Status: "success",
 	}
 
-	log.Println("═══════════════════════════════════════════")
-	log.Println("🔍 Starting job check...")
-	log.Printf("⏰ Time: %s", startTime.Format("2006-01-02 15:04:05"))
+	logger := runLogger(run)
+	logger.Info("job check started", "trigger", run.Trigger)
 
 	// Get enabled companies
 	var companies []*model.Company
@@ -119,143 +418,481 @@ func (s *Scheduler) RunNow() error {
 	if s.companyRepo != nil {
 		companies, err = s.companyRepo.GetEnabled()
 		if err != nil {
-			log.Printf("❌ Error getting companies: %v", err)
+			logger.Error("failed to load companies", "error", err)
 			runLog.Status = "error"
 			runLog.ErrorMessage = err.Error()
-			s.saveRunLog(runLog, startTime)
-			return err
+			s.saveRunLog(logger, runLog, startTime, nil)
+			return runLog, err
 		}
+		enabledCompanies.Set(float64(len(companies)))
 	}
 
-	if len(companies) == 0 {
-		log.Println("⚠️  No companies configured, using defaults")
+	if len(companies) == 0 && (due == nil || due.defaults) {
+		logger.Warn("no companies configured, scraping the defaults")
 		// Fall back to default scraper
-		return s.runWithDefaultScraper(runLog, startTime)
+		return runLog, s.runWithDefaultScraper(run, logger, runLog, startTime)
+	}
+
+	companies = s.dueCompanies(companies, due, startTime)
+	if len(companies) == 0 {
+		logger.Info("no companies due")
+		runLog.Status = "skipped"
+		return runLog, nil
 	}
 
 	runLog.CompaniesChecked = len(companies)
-	log.Printf("📋 Companies to check: %d", len(companies))
-	log.Println("───────────────────────────────────────────")
+	s.runs.update(run, func(r *model.Run) { r.CompaniesTotal = len(companies) })
+	logger.Info("checking companies", "count", len(companies))
 
 	totalJobs := 0
 	newCount := 0
 	notificationsSent := 0
-
-	for _, company := range companies {
-		log.Printf("🏢 Checking: %s", company.Name)
-
-		config := scraper.CompanyConfig{
-			Name:       company.Name,
-			CareerURL:  company.CareerURL,
-			SearchTerm: company.SearchTerm,
+	var companyRuns []*model.CompanyRun
+
+	scrapes := s.scrapeCompanies(run, companies)
+	for i, company := range companies {
+		scraped, ok := <-scrapes[i]
+		if !ok {
+			logger.Info("run cancelled")
+			runLog.Status = "cancelled"
+			break
 		}
-
-		jobs, err := s.scraper.ScrapeCompany(config)
+		if s.isClosed() {
+			logger.Warn("scheduler shut down, not saving the remaining companies")
+			break
+		}
+		result, err := scraped.result, scraped.err
+		companyRun := &model.CompanyRun{
+			CompanyID:   company.ID,
+			Company:     company.Name,
+			Status:      "success",
+			HTTPStatus:  result.StatusCode,
+			Bytes:       result.Bytes,
+			Pages:       result.Pages,
+			JobsFound:   len(result.Jobs),
+			DurationMs:  result.Duration.Milliseconds(),
+			Fingerprint: result.Fingerprint,
+			Challenge:   result.Challenge,
+		}
+		companyRuns = append(companyRuns, companyRun)
+		clog := companyLogger(logger, company)
 		if err != nil {
-			log.Printf("   ❌ Error scraping %s: %v", company.Name, err)
+			clog.Warn("scrape failed", "error", err, "error_class", result.ErrorClass,
+				"http_status", result.StatusCode, "duration_ms", companyRun.DurationMs)
+			companyRun.Status = "error"
+			companyRun.ErrorClass = result.ErrorClass
+			companyRun.ErrorMessage = err.Error()
+		}
+		s.checkAnomalies(clog, company, companyRun)
+		if err != nil {
+			s.runs.update(run, func(r *model.Run) { r.CompaniesDone++ })
+			s.publish(run, events.CompanyFinished, *companyRun)
 			continue
 		}
 
-		log.Printf("   📄 Found %d job listings", len(jobs))
+		jobs := result.Jobs
+		clog.Info("scraped company", "jobs_found", len(jobs), "http_status", result.StatusCode,
+			"pages", result.Pages, "duration_ms", companyRun.DurationMs)
 		totalJobs += len(jobs)
 
+		ctx := logging.WithLogger(context.Background(), clog)
+		var outbox []*model.Job
 		for _, job := range jobs {
+			if s.isClosed() {
+				clog.Warn("scheduler shut down, not saving the remaining jobs")
+				break
+			}
 			existing, err := s.repo.GetByURL(job.URL)
 			if err != nil {
-				log.Printf("   ❌ Error checking job: %v", err)
+				clog.Error("failed to look up job", "url", job.URL, "error", err)
 				continue
 			}
 
 			if existing != nil {
+				s.trackChanges(ctx, existing, job)
 				continue
 			}
 
 			// New job found!
-			log.Printf("   ✨ NEW: %s", job.Title)
+			clog.Info("new job", "title", job.Title, "url", job.URL)
+			s.enrich(ctx, job)
+			s.linkDuplicate(clog, job)
 			if err := s.repo.Create(job); err != nil {
-				log.Printf("   ❌ Error saving job: %v", err)
+				clog.Error("failed to save job", "url", job.URL, "error", err)
+				continue
+			}
+			jobsDiscovered.WithLabelValues(job.Company).Inc()
+			companyRun.NewJobs++
+			s.publish(run, events.JobFound, *job)
+
+			if job.DuplicateOf != 0 {
+				clog.Info("duplicate job, not notifying", "job_id", job.ID, "duplicate_of", job.DuplicateOf)
 				continue
 			}
 
-			if err := s.notifier.NotifyJob(s.recipient, job); err != nil {
-				log.Printf("   ❌ Error sending notification: %v", err)
-			} else {
-				s.repo.MarkNotified(job.ID)
-				newCount++
-				notificationsSent++
+			if !s.shouldNotify(job) {
+				clog.Info("job below minimum score, not notifying", "job_id", job.ID, "score", job.Score)
+				continue
 			}
+			outbox = append(outbox, job)
 		}
+
+		sent := s.deliver(clog, run, outbox)
+		newCount += sent
+		notificationsSent += sent
+		s.runs.update(run, func(r *model.Run) {
+			r.CompaniesDone++
+			r.JobsFound += len(jobs)
+			r.NewJobs += companyRun.NewJobs
+		})
+		s.publish(run, events.CompanyFinished, *companyRun)
 	}
 
 	runLog.JobsFound = totalJobs
 	runLog.NewJobs = newCount
 	runLog.NotificationsSent = notificationsSent
 
-	log.Println("───────────────────────────────────────────")
-	log.Printf("📊 Summary:")
-	log.Printf("   • Companies checked: %d", len(companies))
-	log.Printf("   • Total jobs found: %d", totalJobs)
-	log.Printf("   • New positions: %d", newCount)
-	log.Printf("   • Notifications sent: %d", notificationsSent)
+	logger.Info("job check finished", "status", runLog.Status, "companies", len(companies),
+		"jobs_found", totalJobs, "new_jobs", newCount, "notifications_sent", notificationsSent)
 
-	// Send summary notification
-	if newCount == 0 {
+	// Send summary notification, except for companies checked on their own
+	// schedules, which may be checked every few minutes
+	if newCount == 0 && runLog.Status == "success" && (due == nil || due.defaults) && s.notificationMode() == settings.NotifyAll && !s.isClosed() {
 		msg := fmt.Sprintf("📋 Intern Job Tracker Update\n\n✅ Checked %d companies\n📄 Found %d job listings\n🆕 No new positions found\n\nTracking: %s",
 			len(companies), totalJobs, s.getCompanyNames(companies))
-		if err := s.notifier.Send(s.recipient, msg); err != nil {
-			log.Printf("   ❌ Error sending summary: %v", err)
+		if err := s.notifier.Send(s.getRecipient(), msg); err != nil {
+			logger.Error("failed to send summary", "error", err)
 		} else {
 			notificationsSent++
 		}
 	}
 
-	s.saveRunLog(runLog, startTime)
+	s.saveRunLog(logger, runLog, startTime, companyRuns)
+	return runLog, nil
+}
 
-	log.Println("═══════════════════════════════════════════")
-	return nil
+// dueCompanies returns the companies that are due and active on the day of
+// now, in the scheduler's time zone.
+func (s *Scheduler) dueCompanies(companies []*model.Company, due *dueSet, now time.Time) []*model.Company {
+	s.mu.Lock()
+	if s.location != nil {
+		now = now.In(s.location)
+	}
+	s.mu.Unlock()
+
+	var checked []*model.Company
+	for _, company := range companies {
+		if due.includes(company) && company.ActiveOn(now) {
+			checked = append(checked, company)
+		}
+	}
+	return checked
 }
 
-func (s *Scheduler) runWithDefaultScraper(runLog *model.RunLog, startTime time.Time) error {
+// runWithDefaultScraper checks the built-in companies. Companies that fail
+// are logged; the run fails only if no jobs were found at all.
+func (s *Scheduler) runWithDefaultScraper(run *model.Run, logger *slog.Logger, runLog *model.RunLog, startTime time.Time) error {
 	jobs, err := s.scraper.ScrapeAll()
-	if err != nil {
+	for _, ce := range scraper.CompanyErrors(err) {
+		logger.Warn("scrape failed", "company", ce.Company, "error_class", ce.Class, "error", ce.Err)
+	}
+	if err != nil && len(jobs) == 0 {
+		runLog.Status = "error"
+		runLog.ErrorMessage = err.Error()
+		s.saveRunLog(logger, runLog, startTime, nil)
 		return err
 	}
 
 	runLog.JobsFound = len(jobs)
-	newCount := 0
 
+	var outbox []*model.Job
 	for _, job := range jobs {
+		if s.isClosed() {
+			logger.Warn("scheduler shut down, not saving the remaining jobs")
+			break
+		}
+		clog := logger.With("company", job.Company)
+		ctx := logging.WithLogger(context.Background(), clog)
 		existing, _ := s.repo.GetByURL(job.URL)
 		if existing != nil {
+			s.trackChanges(ctx, existing, job)
 			continue
 		}
 
-		s.repo.Create(job)
-		if err := s.notifier.NotifyJob(s.recipient, job); err == nil {
-			s.repo.MarkNotified(job.ID)
-			newCount++
+		clog.Info("new job", "title", job.Title, "url", job.URL)
+		s.enrich(ctx, job)
+		s.linkDuplicate(clog, job)
+		if err := s.repo.Create(job); err != nil {
+			clog.Error("failed to save job", "url", job.URL, "error", err)
+			continue
 		}
+		jobsDiscovered.WithLabelValues(job.Company).Inc()
+		s.runs.update(run, func(r *model.Run) { r.NewJobs++ })
+		s.publish(run, events.JobFound, *job)
+		if job.DuplicateOf != 0 || !s.shouldNotify(job) {
+			continue
+		}
+		outbox = append(outbox, job)
 	}
+	newCount := s.deliver(logger, run, outbox)
 
 	runLog.NewJobs = newCount
 	runLog.NotificationsSent = newCount
 
-	if newCount == 0 {
-		s.notifier.Send(s.recipient, "📋 No new intern positions found.")
+	if newCount == 0 && s.notificationMode() == settings.NotifyAll && !s.isClosed() {
+		s.notifier.Send(s.getRecipient(), "📋 No new intern positions found.")
 	}
 
-	s.saveRunLog(runLog, startTime)
+	logger.Info("job check finished", "status", runLog.Status, "jobs_found", len(jobs),
+		"new_jobs", newCount, "notifications_sent", newCount)
+	s.saveRunLog(logger, runLog, startTime, nil)
 	return nil
 }
 
-func (s *Scheduler) saveRunLog(runLog *model.RunLog, startTime time.Time) {
+// deliver sends a notification for each queued job, marks the delivered ones
+// as notified and returns how many were delivered. It stops once the
+// scheduler is shut down, leaving the rest unnotified.
+func (s *Scheduler) deliver(logger *slog.Logger, run *model.Run, outbox []*model.Job) int {
+	if len(outbox) > 0 && s.notificationMode() == settings.NotifyOff {
+		logger.Info("notifications off, not notifying", "jobs", len(outbox))
+		return 0
+	}
+	outboxDepth.Add(float64(len(outbox)))
+	sent := 0
+	for i, job := range outbox {
+		if s.isClosed() {
+			logger.Warn("scheduler shut down, not sending the remaining notifications", "jobs", len(outbox)-i)
+			outboxDepth.Add(-float64(len(outbox) - i))
+			break
+		}
+		err := s.notifier.NotifyJob(s.getRecipient(), job)
+		outboxDepth.Add(-1)
+		if err != nil {
+			logger.Error("failed to send notification", "job_id", job.ID, "error", err)
+			s.publish(run, events.NotificationFailed, map[string]interface{}{
+				"job_id": job.ID, "title": job.Title, "error": err.Error(),
+			})
+			continue
+		}
+		logger.Debug("notification sent", "job_id", job.ID)
+		s.repo.MarkNotified(job.ID)
+		s.publish(run, events.NotificationSent, map[string]interface{}{"job_id": job.ID, "title": job.Title})
+		sent++
+	}
+	return sent
+}
+
+// publish sends a run event to the event bus, if one is set.
+func (s *Scheduler) publish(run *model.Run, eventType string, data interface{}) {
+	s.mu.Lock()
+	bus := s.events
+	s.mu.Unlock()
+	if bus != nil {
+		bus.Publish(events.Event{Type: eventType, RunID: run.ID, Data: data})
+	}
+}
+
+// enrich runs every registered enricher over a new job.
+func (s *Scheduler) enrich(ctx context.Context, job *model.Job) {
+	s.mu.Lock()
+	enrichers := s.enrichers
+	s.mu.Unlock()
+
+	for _, e := range enrichers {
+		if ce, ok := e.(ContextEnricher); ok {
+			ce.EnrichContext(ctx, job)
+		} else {
+			e.Enrich(job)
+		}
+	}
+}
+
+// trackChanges compares a re-scraped posting with its stored version and
+// records a revision if any tracked field changed. Watched jobs, and jobs
+// whose title or location changed, are enriched again so that detail page
+// changes are seen too.
+func (s *Scheduler) trackChanges(ctx context.Context, existing, scraped *model.Job) {
+	logger := logging.FromContext(ctx)
+	current := *existing
+	current.Title = scraped.Title
+	if scraped.Location != "" {
+		current.Location = scraped.Location
+	}
+	if existing.Watched || current.Title != existing.Title || current.Location != existing.Location {
+		s.enrich(ctx, &current)
+	}
+
+	if history.Hash(&current) == existing.ContentHash {
+		return
+	}
+	changes := history.Diff(existing, &current)
+	if len(changes) == 0 {
+		return
+	}
+
+	names := make([]string, len(changes))
+	for i, c := range changes {
+		names[i] = c.Field
+	}
+	logger.Info("job changed", "job_id", existing.ID, "title", current.Title, "fields", strings.Join(names, ","))
+	if err := s.repo.SaveRevision(&current, changes); err != nil {
+		logger.Error("failed to save revision", "job_id", existing.ID, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	notify := s.rules.WatchedChanges && existing.Watched && s.rules.Mode != settings.NotifyOff
+	s.mu.Unlock()
+	if notify {
+		if err := s.notifier.Send(s.getRecipient(), formatChangeMessage(&current, changes)); err != nil {
+			logger.Error("failed to send change notification", "job_id", existing.ID, "error", err)
+		}
+	}
+}
+
+// formatChangeMessage describes the changes to a watched job.
+func formatChangeMessage(job *model.Job, changes []model.FieldChange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✏️ Watched job changed\n\n🏢 %s\n💼 %s\n", job.Company, job.Title)
+	for _, c := range changes {
+		if c.Field == "description" || c.Field == "requirements" {
+			fmt.Fprintf(&b, "\n• %s updated", c.Field)
+			continue
+		}
+		fmt.Fprintf(&b, "\n• %s: %s → %s", c.Field, orNone(c.Old), orNone(c.New))
+	}
+	fmt.Fprintf(&b, "\n\n🔗 %s", job.URL)
+	return b.String()
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// checkAnomalies compares a company's scrape with its history and opens an
+// incident for each new anomaly, notifying once when it opens. Incidents the
+// scrape no longer shows are resolved. Failed scrapes say nothing about the
+// page, so they only open challenge incidents and resolve nothing.
+func (s *Scheduler) checkAnomalies(logger *slog.Logger, company *model.Company, run *model.CompanyRun) {
+	s.mu.Lock()
+	incidents, detector := s.incidents, s.detector
+	s.mu.Unlock()
+	if incidents == nil || s.runLogRepo == nil || (run.Status != "success" && !run.Challenge) {
+		return
+	}
+
+	previous, err := s.runLogRepo.GetCompanyRuns(company.ID, detector.Window*2)
+	if err != nil {
+		logger.Warn("failed to load scrape history", "error", err)
+		return
+	}
+	open, err := incidents.GetOpen(company.ID)
+	if err != nil {
+		logger.Warn("failed to load incidents", "error", err)
+		return
+	}
+	openByReason := make(map[string]*model.Incident)
+	for _, incident := range open {
+		openByReason[incident.Reason] = incident
+	}
+
+	for _, a := range detector.Check(run, previous) {
+		if openByReason[a.Reason] != nil {
+			delete(openByReason, a.Reason)
+			continue
+		}
+		incident := &model.Incident{
+			CompanyID: company.ID,
+			Company:   company.Name,
+			Reason:    a.Reason,
+			Detail:    a.Detail,
+		}
+		if err := incidents.Open(incident); err != nil {
+			logger.Error("failed to save incident", "reason", a.Reason, "error", err)
+			continue
+		}
+		logger.Warn("incident opened", "incident_id", incident.ID, "reason", a.Reason, "detail", a.Detail)
+		msg := fmt.Sprintf("🚨 Attention needed\n\n🏢 %s\n⚠️ %s\n\nThe scraper may need updating: %s",
+			company.Name, a.Detail, company.CareerURL)
+		if err := s.notifier.Send(s.getRecipient(), msg); err != nil {
+			logger.Error("failed to send attention notification", "incident_id", incident.ID, "error", err)
+		}
+	}
+
+	if run.Status != "success" {
+		return
+	}
+	for _, incident := range openByReason {
+		if err := incidents.Resolve(incident.ID); err != nil {
+			logger.Error("failed to resolve incident", "incident_id", incident.ID, "error", err)
+			continue
+		}
+		logger.Info("incident resolved", "incident_id", incident.ID, "reason", incident.Reason, "detail", incident.Detail)
+	}
+}
+
+// linkDuplicate links a new job to the stored job it duplicates, if any.
+func (s *Scheduler) linkDuplicate(logger *slog.Logger, job *model.Job) {
+	id, err := s.repo.FindDuplicate(job)
+	if err != nil {
+		logger.Warn("failed to check for duplicates", "url", job.URL, "error", err)
+		return
+	}
+	job.DuplicateOf = id
+}
+
+// notificationMode returns the current notification mode.
+func (s *Scheduler) notificationMode() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rules.Mode == "" {
+		return settings.NotifyAll
+	}
+	return s.rules.Mode
+}
+
+// shouldNotify reports whether a new job passes the notification rules.
+func (s *Scheduler) shouldNotify(job *model.Job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rules.MinScore == 0 || job.Score >= s.rules.MinScore
+}
+
+// saveRunLog records a finished run and the per-company results it produced,
+// then alerts on failures and pings the configured monitoring URL.
+func (s *Scheduler) saveRunLog(logger *slog.Logger, runLog *model.RunLog, startTime time.Time, companyRuns []*model.CompanyRun) {
+	if s.isClosed() {
+		logger.Warn("not saving run log after shutdown", "status", runLog.Status)
+		return
+	}
 	runLog.DurationMs = time.Since(startTime).Milliseconds()
+	runsTotal.WithLabelValues(runLog.Status).Inc()
+	runDuration.Observe(time.Since(startTime).Seconds())
 	if s.runLogRepo != nil {
 		if err := s.runLogRepo.Create(runLog); err != nil {
-			log.Printf("❌ Error saving run log: %v", err)
+			logger.Error("failed to save run log", "error", err)
+		} else if len(companyRuns) > 0 {
+			if err := s.runLogRepo.CreateCompanyRuns(runLog.ID, companyRuns); err != nil {
+				logger.Error("failed to save company results", "run_log_id", runLog.ID, "error", err)
+			}
 		}
 	}
-	log.Printf("⏱️  Duration: %dms", runLog.DurationMs)
+	logger.Info("run log saved", "run_log_id", runLog.ID, "status", runLog.Status, "duration_ms", runLog.DurationMs)
+	s.alert(logger, runLog, companyRuns)
+	s.ping(logger, runLog)
+}
+
+// runLogger returns a logger whose records carry a run's ID.
+func runLogger(run *model.Run) *slog.Logger {
+	return slog.With("run_id", run.ID)
+}
+
+// companyLogger returns a logger whose records also carry a company.
+func companyLogger(logger *slog.Logger, company *model.Company) *slog.Logger {
+	return logger.With("company", company.Name, "company_id", company.ID)
 }
 
 func (s *Scheduler) getCompanyNames(companies []*model.Company) string {
@@ -272,9 +909,69 @@ func (s *Scheduler) getCompanyNames(companies []*model.Company) string {
 	return names
 }
 
+// getRecipient returns the current notification recipient.
+func (s *Scheduler) getRecipient() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recipient
+}
+
 // SetRecipient updates the notification recipient.
 func (s *Scheduler) SetRecipient(recipient string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.recipient = recipient
 }
+
+// AddEnricher registers an enricher to run on every newly discovered job.
+func (s *Scheduler) AddEnricher(e Enricher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enrichers = append(s.enrichers, e)
+}
+
+// SetAnomalyDetection enables checking each company's scrape against its
+// history, recording incidents in the given repository.
+func (s *Scheduler) SetAnomalyDetection(incidents IncidentRepository, detector anomaly.Detector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.incidents = incidents
+	s.detector = detector
+}
+
+// SetEventBus publishes the events of every run to bus.
+func (s *Scheduler) SetEventBus(bus Publisher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = bus
+}
+
+// SetNotificationMode changes which notifications are sent, keeping the
+// other notification rules.
+func (s *Scheduler) SetNotificationMode(mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules.Mode = mode
+}
+
+// SetConcurrency sets how many companies are scraped at once.
+func (s *Scheduler) SetConcurrency(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.concurrency = n
+}
+
+// ApplySettings applies runtime settings to the scheduler.
+func (s *Scheduler) ApplySettings(cfg settings.Settings) error {
+	s.SetRecipient(cfg.Recipient)
+	s.SetNotificationMode(cfg.NotificationMode)
+	s.SetConcurrency(cfg.ScrapeConcurrency)
+	return s.SetSchedule(cfg.Schedule, cfg.Location())
+}
+
+// SetNotificationRules updates the rules deciding which jobs are notified.
+func (s *Scheduler) SetNotificationRules(rules NotificationRules) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+}
@@ -1,11 +1,29 @@
 package scheduler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"intern-job-tracker/internal/anomaly"
+	"intern-job-tracker/internal/events"
+	"intern-job-tracker/internal/logging"
 	"intern-job-tracker/internal/model"
 	"intern-job-tracker/internal/scraper"
+	"intern-job-tracker/internal/settings"
 )
 
 // MockScraper for testing
@@ -18,22 +36,27 @@ func (m *MockScraper) ScrapeAll() ([]*model.Job, error) {
 	return m.Jobs, m.Err
 }
 
-func (m *MockScraper) ScrapeCompany(config scraper.CompanyConfig) ([]*model.Job, error) {
-	return m.Jobs, m.Err
+func (m *MockScraper) Scrape(config scraper.CompanyConfig) (*scraper.Result, error) {
+	if m.Err != nil {
+		return &scraper.Result{ErrorClass: "network"}, m.Err
+	}
+	return &scraper.Result{Jobs: m.Jobs, StatusCode: 200, Pages: 1}, nil
 }
 
 // MockRepository for testing
 type MockRepository struct {
 	Jobs       map[string]*model.Job
 	Notified   map[int64]bool
+	Revisions  map[int64][][]model.FieldChange
 	CreateErr  error
 	CreatedIDs int64
 }
 
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
-		Jobs:     make(map[string]*model.Job),
-		Notified: make(map[int64]bool),
+		Jobs:      make(map[string]*model.Job),
+		Notified:  make(map[int64]bool),
+		Revisions: make(map[int64][][]model.FieldChange),
 	}
 }
 
@@ -56,6 +79,21 @@ func (m *MockRepository) MarkNotified(id int64) error {
 	return nil
 }
 
+func (m *MockRepository) SaveRevision(job *model.Job, changes []model.FieldChange) error {
+	m.Jobs[job.URL] = job
+	m.Revisions[job.ID] = append(m.Revisions[job.ID], changes)
+	return nil
+}
+
+func (m *MockRepository) FindDuplicate(job *model.Job) (int64, error) {
+	for _, existing := range m.Jobs {
+		if existing.Company == job.Company && existing.Title == job.Title && existing.DuplicateOf == 0 {
+			return existing.ID, nil
+		}
+	}
+	return 0, nil
+}
+
 // MockCompanyRepository for testing
 type MockCompanyRepository struct {
 	Companies []*model.Company
@@ -67,14 +105,62 @@ func (m *MockCompanyRepository) GetEnabled() ([]*model.Company, error) {
 
 // MockRunLogRepository for testing
 type MockRunLogRepository struct {
-	Logs []*model.RunLog
+	Logs        []*model.RunLog
+	CompanyRuns []*model.CompanyRun
 }
 
 func (m *MockRunLogRepository) Create(log *model.RunLog) error {
 	m.Logs = append(m.Logs, log)
+	log.ID = int64(len(m.Logs))
+	return nil
+}
+
+func (m *MockRunLogRepository) CreateCompanyRuns(runID int64, runs []*model.CompanyRun) error {
+	for _, r := range runs {
+		r.RunID = runID
+	}
+	m.CompanyRuns = append(m.CompanyRuns, runs...)
 	return nil
 }
 
+func (m *MockRunLogRepository) GetCompanyRuns(companyID int64, limit int) ([]*model.CompanyRun, error) {
+	var runs []*model.CompanyRun
+	for i := len(m.CompanyRuns) - 1; i >= 0 && len(runs) < limit; i-- {
+		if m.CompanyRuns[i].CompanyID == companyID {
+			runs = append(runs, m.CompanyRuns[i])
+		}
+	}
+	return runs, nil
+}
+
+// MockIncidentRepository for testing
+type MockIncidentRepository struct {
+	Incidents []*model.Incident
+}
+
+func (m *MockIncidentRepository) Open(incident *model.Incident) error {
+	m.Incidents = append(m.Incidents, incident)
+	incident.ID = int64(len(m.Incidents))
+	incident.OpenedAt = time.Now()
+	return nil
+}
+
+func (m *MockIncidentRepository) Resolve(id int64) error {
+	now := time.Now()
+	m.Incidents[id-1].ResolvedAt = &now
+	return nil
+}
+
+func (m *MockIncidentRepository) GetOpen(companyID int64) ([]*model.Incident, error) {
+	var open []*model.Incident
+	for _, i := range m.Incidents {
+		if i.CompanyID == companyID && i.ResolvedAt == nil {
+			open = append(open, i)
+		}
+	}
+	return open, nil
+}
+
 // MockNotifier for testing
 type MockNotifier struct {
 	SentMessages []string
@@ -163,3 +249,1076 @@ func TestScheduler_StartStop(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	sched.Stop()
 }
+
+// fixedScoreEnricher assigns a score based on the job title.
+type fixedScoreEnricher map[string]float64
+
+func (e fixedScoreEnricher) Enrich(job *model.Job) {
+	job.Score = e[job.Title]
+}
+
+func TestScheduler_RunNow_MinScore(t *testing.T) {
+	repo := NewMockRepository()
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 1, Name: "Google", CareerURL: "https://google.com/careers", SearchTerm: "intern"},
+		},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	scr := &MockScraper{
+		Jobs: []*model.Job{
+			{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"},
+			{Company: "Google", Title: "Sales Intern", URL: "https://google.com/job/2"},
+		},
+	}
+	notifier := &MockNotifier{}
+
+	sched := New(repo, companyRepo, runLogRepo, scr, notifier, "+1234567890")
+	sched.AddEnricher(fixedScoreEnricher{"SWE Intern": 8, "Sales Intern": 1})
+	sched.SetNotificationRules(NotificationRules{MinScore: 5})
+
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.Jobs) != 2 {
+		t.Errorf("expected both jobs saved, got %d", len(repo.Jobs))
+	}
+	if repo.Jobs["https://google.com/job/1"].Score != 8 {
+		t.Error("expected enricher to score job before saving")
+	}
+	if len(notifier.SentMessages) != 1 || notifier.SentMessages[0] != "SWE Intern" {
+		t.Errorf("expected only the high-scoring job to be notified, got %v", notifier.SentMessages)
+	}
+}
+
+func TestScheduler_RunNow_Duplicates(t *testing.T) {
+	repo := NewMockRepository()
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 1, Name: "Google", CareerURL: "https://google.com/careers", SearchTerm: "intern"},
+		},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	scr := &MockScraper{
+		Jobs: []*model.Job{
+			{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"},
+			{Company: "Google", Title: "SWE Intern", URL: "https://boards.greenhouse.io/google/jobs/1"},
+		},
+	}
+	notifier := &MockNotifier{}
+
+	sched := New(repo, companyRepo, runLogRepo, scr, notifier, "+1234567890")
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.Jobs) != 2 {
+		t.Errorf("expected both jobs saved, got %d", len(repo.Jobs))
+	}
+	duplicate := repo.Jobs["https://boards.greenhouse.io/google/jobs/1"]
+	if duplicate.DuplicateOf != repo.Jobs["https://google.com/job/1"].ID {
+		t.Errorf("expected second posting linked to the first, got duplicate_of %d", duplicate.DuplicateOf)
+	}
+	if len(notifier.SentMessages) != 1 {
+		t.Errorf("expected one notification, got %v", notifier.SentMessages)
+	}
+}
+
+func TestScheduler_RunNow_MarkdownList(t *testing.T) {
+	list := "| Company | Role | Location | Link |\n|---|---|---|---|\n" +
+		"| Stripe | SWE Intern | SF | [Apply](https://stripe.com/jobs/1) |\n" +
+		"| ↳ | Data Intern | NYC | 🔒 |\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(list))
+	}))
+	defer server.Close()
+
+	repo := NewMockRepository()
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 1, Name: "Summer list", CareerURL: server.URL + "/README.md", SearchTerm: "intern", SourceType: scraper.SourceMarkdown},
+		},
+	}
+	notifier := &MockNotifier{}
+	sched := New(repo, companyRepo, &MockRunLogRepository{}, scraper.NewScraper(server.Client()), notifier, "+1234567890")
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job := repo.Jobs["https://stripe.com/jobs/1"]
+	if len(repo.Jobs) != 1 || job == nil || job.Company != "Stripe" || job.Source != "Summer list" {
+		t.Fatalf("expected the open row to be saved, got %v", repo.Jobs)
+	}
+
+	// Rows added to the list are notified like scraped jobs.
+	list += "| Acme | ML Intern | Remote | [Apply](https://acme.com/jobs/2) |\n"
+	notifier.SentMessages = nil
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.SentMessages) != 1 || notifier.SentMessages[0] != "ML Intern" {
+		t.Errorf("expected only the new row to be notified, got %v", notifier.SentMessages)
+	}
+}
+
+func TestScheduler_RunNow_WatchedJobChanged(t *testing.T) {
+	repo := NewMockRepository()
+	repo.Jobs["https://google.com/job/1"] = &model.Job{
+		ID: 1, Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1", Location: "Mountain View, CA", Watched: true,
+	}
+	repo.Jobs["https://google.com/job/2"] = &model.Job{
+		ID: 2, Company: "Google", Title: "Data Intern", URL: "https://google.com/job/2",
+	}
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 1, Name: "Google", CareerURL: "https://google.com/careers", SearchTerm: "intern"},
+		},
+	}
+	scr := &MockScraper{
+		Jobs: []*model.Job{
+			{Company: "Google", Title: "SWE Intern, Summer 2027", URL: "https://google.com/job/1"},
+			{Company: "Google", Title: "Data Intern", URL: "https://google.com/job/2"},
+		},
+	}
+	notifier := &MockNotifier{}
+
+	sched := New(repo, companyRepo, &MockRunLogRepository{}, scr, notifier, "+1234567890")
+	sched.SetNotificationRules(NotificationRules{WatchedChanges: true})
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	revisions := repo.Revisions[1]
+	if len(revisions) != 1 || len(revisions[0]) != 1 {
+		t.Fatalf("expected one revision with one change, got %+v", revisions)
+	}
+	if c := revisions[0][0]; c.Field != "title" || c.Old != "SWE Intern" || c.New != "SWE Intern, Summer 2027" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+	if repo.Jobs["https://google.com/job/1"].Location != "Mountain View, CA" {
+		t.Error("expected a missing scraped location to keep the stored one")
+	}
+	if len(repo.Revisions[2]) != 0 {
+		t.Errorf("expected no revision for unchanged job, got %+v", repo.Revisions[2])
+	}
+
+	changed := false
+	for _, msg := range notifier.SentMessages {
+		if strings.Contains(msg, "Watched job changed") && strings.Contains(msg, "SWE Intern → SWE Intern, Summer 2027") {
+			changed = true
+		}
+	}
+	if !changed {
+		t.Errorf("expected a change notification, got %v", notifier.SentMessages)
+	}
+}
+
+func TestScheduler_RunNow_Metrics(t *testing.T) {
+	repo := NewMockRepository()
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 1, Name: "Metrics Co", CareerURL: "https://metrics.example.com/careers", SearchTerm: "intern"},
+		},
+	}
+	scr := &MockScraper{
+		Jobs: []*model.Job{
+			{Company: "Metrics Co", Title: "SWE Intern", URL: "https://metrics.example.com/job/1"},
+			{Company: "Metrics Co", Title: "Data Intern", URL: "https://metrics.example.com/job/2"},
+		},
+	}
+	runs := testutil.ToFloat64(runsTotal.WithLabelValues("success"))
+	runCount := observations(runDuration)
+
+	sched := New(repo, companyRepo, &MockRunLogRepository{}, scr, &MockNotifier{}, "+1234567890")
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := testutil.ToFloat64(runsTotal.WithLabelValues("success")) - runs; got != 1 {
+		t.Errorf("expected 1 successful run counted, got %v", got)
+	}
+	if observations(runDuration) != runCount+1 {
+		t.Error("expected run duration to be observed")
+	}
+	if got := testutil.ToFloat64(jobsDiscovered.WithLabelValues("Metrics Co")); got != 2 {
+		t.Errorf("expected 2 jobs discovered, got %v", got)
+	}
+	if got := testutil.ToFloat64(enabledCompanies); got != 1 {
+		t.Errorf("expected 1 enabled company, got %v", got)
+	}
+	if got := testutil.ToFloat64(outboxDepth); got != 0 {
+		t.Errorf("expected an empty outbox after the run, got %v", got)
+	}
+}
+
+// observations returns the number of values h has recorded.
+func observations(h prometheus.Histogram) uint64 {
+	var m dto.Metric
+	h.Write(&m)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestScheduler_RunNow_CompanyRuns(t *testing.T) {
+	repo := NewMockRepository()
+	repo.Jobs["https://google.com/job/1"] = &model.Job{ID: 1, Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"}
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 7, Name: "Google", CareerURL: "https://google.com/careers", SearchTerm: "intern"},
+		},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	scr := &MockScraper{
+		Jobs: []*model.Job{
+			{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"},
+			{Company: "Google", Title: "Data Intern", URL: "https://google.com/job/2"},
+		},
+	}
+
+	sched := New(repo, companyRepo, runLogRepo, scr, &MockNotifier{}, "+1234567890")
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(runLogRepo.CompanyRuns) != 1 {
+		t.Fatalf("expected one company result, got %d", len(runLogRepo.CompanyRuns))
+	}
+	got := runLogRepo.CompanyRuns[0]
+	if got.RunID != runLogRepo.Logs[0].ID || got.CompanyID != 7 || got.Status != "success" ||
+		got.HTTPStatus != 200 || got.JobsFound != 2 || got.NewJobs != 1 {
+		t.Errorf("unexpected company result: %+v", got)
+	}
+
+	scr.Err = errors.New("connection refused")
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	failed := runLogRepo.CompanyRuns[1]
+	if failed.Status != "error" || failed.ErrorClass != "network" || failed.ErrorMessage != "connection refused" {
+		t.Errorf("expected a failed company result, got %+v", failed)
+	}
+}
+
+func TestScheduler_RunNow_Anomalies(t *testing.T) {
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 3, Name: "Uber", CareerURL: "https://uber.com/careers", SearchTerm: "intern"},
+		},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	incidents := &MockIncidentRepository{}
+	notifier := &MockNotifier{}
+	listing := []*model.Job{
+		{Company: "Uber", Title: "SWE Intern", URL: "https://uber.com/job/1"},
+		{Company: "Uber", Title: "Data Intern", URL: "https://uber.com/job/2"},
+	}
+	scr := &MockScraper{Jobs: listing}
+
+	sched := New(NewMockRepository(), companyRepo, runLogRepo, scr, notifier, "+1234567890")
+	sched.SetAnomalyDetection(incidents, anomaly.DefaultDetector())
+	sched.RunNow()
+	if len(incidents.Incidents) != 0 {
+		t.Fatalf("expected no incidents for a healthy scrape, got %+v", incidents.Incidents)
+	}
+
+	attentionSent := func() int {
+		n := 0
+		for _, msg := range notifier.SentMessages {
+			if strings.Contains(msg, "Attention needed") {
+				n++
+			}
+		}
+		return n
+	}
+
+	// The page stops listing jobs: one incident, notified once.
+	scr.Jobs = nil
+	sched.RunNow()
+	sched.RunNow()
+	if len(incidents.Incidents) != 1 || incidents.Incidents[0].Reason != anomaly.ReasonEmpty {
+		t.Fatalf("expected one empty incident, got %+v", incidents.Incidents)
+	}
+	if n := attentionSent(); n != 1 {
+		t.Errorf("expected one attention notification, got %d", n)
+	}
+
+	// A failed scrape leaves the incident open.
+	scr.Err = errors.New("connection refused")
+	sched.RunNow()
+	if incidents.Incidents[0].ResolvedAt != nil {
+		t.Error("expected incident to stay open after a failed scrape")
+	}
+
+	scr.Err = nil
+	scr.Jobs = listing
+	sched.RunNow()
+	if incidents.Incidents[0].ResolvedAt == nil {
+		t.Error("expected incident to be resolved once jobs are listed again")
+	}
+	if n := attentionSent(); n != 1 {
+		t.Errorf("expected no further attention notifications, got %d", n)
+	}
+}
+
+func TestScheduler_RunNow_FailureAlerts(t *testing.T) {
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{
+			{ID: 1, Name: "Google"},
+			{ID: 2, Name: "Uber"},
+		},
+	}
+	notifier := &MockNotifier{}
+	scr := &MockScraper{Err: errors.New("connection refused")}
+
+	var pings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings = append(pings, r.URL.Path)
+	}))
+	defer server.Close()
+
+	sched := New(NewMockRepository(), companyRepo, &MockRunLogRepository{}, scr, notifier, "+1234567890")
+	sched.SetAlertRules(AlertRules{FailurePercent: 50, PingURL: server.URL + "/ping/abc"})
+	sched.RunNow()
+
+	last := notifier.SentMessages[len(notifier.SentMessages)-1]
+	if !strings.Contains(last, "2 of 2 companies failed") || !strings.Contains(last, "Uber (network)") {
+		t.Errorf("expected a degraded run alert, got %q", last)
+	}
+	if len(pings) != 1 || pings[0] != "/ping/abc" {
+		t.Errorf("expected a success ping, got %v", pings)
+	}
+
+	// A run in which every company succeeds does not alert.
+	notifier.SentMessages = nil
+	sched.SetAlertRules(AlertRules{FailurePercent: 50})
+	companyRepo.Companies = append(companyRepo.Companies, &model.Company{ID: 3, Name: "Acme"}, &model.Company{ID: 4, Name: "Initech"})
+	scr.Err = nil
+	sched.RunNow()
+	for _, msg := range notifier.SentMessages {
+		if strings.Contains(msg, "companies failed") {
+			t.Errorf("expected no alert when all companies succeed, got %q", msg)
+		}
+	}
+
+	// A run that ends with status error always alerts and pings /fail.
+	notifier.SentMessages = nil
+	pings = nil
+	sched = New(NewMockRepository(), &MockCompanyRepository{}, &MockRunLogRepository{}, &MockScraper{Err: errors.New("boom")}, notifier, "+1234567890")
+	sched.SetAlertRules(AlertRules{PingURL: server.URL + "/ping/abc/"})
+	sched.RunNow()
+	if len(notifier.SentMessages) != 1 || !strings.Contains(notifier.SentMessages[0], "Job check failed\n\nboom") {
+		t.Errorf("expected a failed run alert, got %v", notifier.SentMessages)
+	}
+	if len(pings) != 1 || pings[0] != "/ping/abc/fail" {
+		t.Errorf("expected a failure ping, got %v", pings)
+	}
+}
+
+// panickingScraper crashes on every scrape.
+type panickingScraper struct{}
+
+func (panickingScraper) ScrapeAll() ([]*model.Job, error) {
+	panic("nil map")
+}
+
+func (panickingScraper) Scrape(config scraper.CompanyConfig) (*scraper.Result, error) {
+	panic("nil map")
+}
+
+func TestScheduler_RunScheduled_Crash(t *testing.T) {
+	runLogRepo := &MockRunLogRepository{}
+	notifier := &MockNotifier{}
+	sched := New(NewMockRepository(), &MockCompanyRepository{}, runLogRepo, panickingScraper{}, notifier, "+1234567890")
+
+	sched.runScheduled()
+
+	if len(runLogRepo.Logs) != 1 || runLogRepo.Logs[0].Status != "error" || runLogRepo.Logs[0].ErrorMessage != "crashed: nil map" {
+		t.Errorf("expected the crash to be logged as a failed run, got %+v", runLogRepo.Logs)
+	}
+	if len(notifier.SentMessages) != 1 || !strings.Contains(notifier.SentMessages[0], "Job check failed") {
+		t.Errorf("expected a failure alert, got %v", notifier.SentMessages)
+	}
+}
+
+// blockingScraper waits for release before finishing each scrape.
+type blockingScraper struct {
+	MockScraper
+	started chan string
+	release chan struct{}
+}
+
+func (b *blockingScraper) Scrape(config scraper.CompanyConfig) (*scraper.Result, error) {
+	b.started <- config.Name
+	<-b.release
+	return b.MockScraper.Scrape(config)
+}
+
+// waitForRun polls until a run reaches a final state.
+func waitForRun(t *testing.T, sched *Scheduler, id int64) *model.Run {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		run, _ := sched.GetRun(id)
+		switch run.State {
+		case model.RunDone, model.RunFailed, model.RunCancelled:
+			return run
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("run %d did not finish", id)
+	return nil
+}
+
+func TestScheduler_Enqueue(t *testing.T) {
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{{ID: 1, Name: "Google"}, {ID: 2, Name: "Uber"}},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	scr := &blockingScraper{
+		MockScraper: MockScraper{Jobs: []*model.Job{{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"}}},
+		started:     make(chan string),
+		release:     make(chan struct{}),
+	}
+	sched := New(NewMockRepository(), companyRepo, runLogRepo, scr, &MockNotifier{}, "+1234567890")
+
+	run, created := sched.Enqueue()
+	if !created || run.ID == 0 {
+		t.Fatalf("expected a new run, got %+v", run)
+	}
+	<-scr.started
+
+	// Requests while the run is active are coalesced into it.
+	again, created := sched.Enqueue()
+	if created || again.ID != run.ID {
+		t.Errorf("expected duplicate request to return run %d, got %+v", run.ID, again)
+	}
+	if err := sched.RunNow(); err != ErrRunInProgress {
+		t.Errorf("expected ErrRunInProgress, got %v", err)
+	}
+
+	running, _ := sched.GetRun(run.ID)
+	if running.State != model.RunRunning || running.Company != "Google" || running.CompaniesTotal != 2 || running.CompaniesDone != 0 {
+		t.Errorf("unexpected progress %+v", running)
+	}
+
+	scr.release <- struct{}{}
+	<-scr.started
+	scr.release <- struct{}{}
+
+	done := waitForRun(t, sched, run.ID)
+	if done.State != model.RunDone || done.CompaniesDone != 2 || done.NewJobs != 1 || done.RunLogID != runLogRepo.Logs[0].ID {
+		t.Errorf("unexpected finished run %+v", done)
+	}
+
+	next, created := sched.Enqueue()
+	if !created || next.ID == run.ID {
+		t.Errorf("expected a new run once the previous one finished, got %+v", next)
+	}
+	<-scr.started
+	if !sched.CancelRun(next.ID) {
+		t.Fatal("expected the active run to be cancellable")
+	}
+	scr.release <- struct{}{}
+	if cancelled := waitForRun(t, sched, next.ID); cancelled.State != model.RunCancelled || cancelled.CompaniesDone != 1 {
+		t.Errorf("expected run to stop after the current company, got %+v", cancelled)
+	}
+	if sched.CancelRun(next.ID) {
+		t.Error("expected a finished run not to be cancellable")
+	}
+	if _, ok := sched.GetRun(999); ok {
+		t.Error("expected unknown run not to be found")
+	}
+}
+
+func TestScheduler_RunNow_Events(t *testing.T) {
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{{ID: 1, Name: "Google"}},
+	}
+	scr := &MockScraper{Jobs: []*model.Job{{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"}}}
+	notifier := &MockNotifier{}
+	bus := events.NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	sched := New(NewMockRepository(), companyRepo, &MockRunLogRepository{}, scr, notifier, "+1234567890")
+	sched.SetEventBus(bus)
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var types []string
+	var received []events.Event
+	for len(ch) > 0 {
+		e := <-ch
+		types = append(types, e.Type)
+		received = append(received, e)
+	}
+	want := []string{events.RunStarted, events.CompanyStarted, events.JobFound, events.NotificationSent, events.CompanyFinished, events.RunFinished}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+	for _, e := range received {
+		if e.RunID != received[0].RunID || e.RunID == 0 {
+			t.Errorf("expected all events to carry the run ID, got %+v", e)
+		}
+	}
+	if job, ok := received[2].Data.(model.Job); !ok || job.Title != "SWE Intern" || job.ID == 0 {
+		t.Errorf("expected the saved job, got %+v", received[2].Data)
+	}
+	if finished := received[5].Data.(*model.Run); finished.State != model.RunDone || finished.NewJobs != 1 {
+		t.Errorf("expected a finished run, got %+v", finished)
+	}
+
+	// Failed notifications are announced too.
+	notifier.Err = errors.New("Messages not running")
+	scr.Jobs = []*model.Job{{Company: "Google", Title: "Data Intern", URL: "https://google.com/job/2"}}
+	sched.RunNow()
+	failed := false
+	for len(ch) > 0 {
+		if e := <-ch; e.Type == events.NotificationFailed {
+			failed = e.Data.(map[string]interface{})["error"] == "Messages not running"
+		}
+	}
+	if !failed {
+		t.Error("expected a notification_failed event")
+	}
+}
+
+func TestScheduler_NotificationModes(t *testing.T) {
+	companyRepo := &MockCompanyRepository{Companies: []*model.Company{{ID: 1, Name: "Google"}}}
+	scr := &MockScraper{}
+	notifier := &MockNotifier{}
+	repo := NewMockRepository()
+	sched := New(repo, companyRepo, &MockRunLogRepository{}, scr, notifier, "+1234567890")
+
+	// new_jobs skips the summary of a run without new jobs.
+	sched.SetNotificationMode(settings.NotifyNewJobs)
+	sched.RunNow()
+	if len(notifier.SentMessages) != 0 {
+		t.Errorf("expected no summary, got %v", notifier.SentMessages)
+	}
+
+	// off saves new jobs without notifying them.
+	sched.SetNotificationMode(settings.NotifyOff)
+	scr.Jobs = []*model.Job{{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"}}
+	sched.RunNow()
+	if len(notifier.SentMessages) != 0 || len(repo.Notified) != 0 {
+		t.Errorf("expected no notifications, got %v", notifier.SentMessages)
+	}
+	if len(repo.Jobs) != 1 {
+		t.Errorf("expected the job to be saved, got %d jobs", len(repo.Jobs))
+	}
+}
+
+// countingScraper records how many scrapes run at once.
+type countingScraper struct {
+	MockScraper
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (c *countingScraper) Scrape(config scraper.CompanyConfig) (*scraper.Result, error) {
+	c.mu.Lock()
+	c.current++
+	if c.current > c.max {
+		c.max = c.current
+	}
+	c.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+	return &scraper.Result{Jobs: []*model.Job{{Company: config.Name, Title: "Intern", URL: "https://example.com/" + config.Name}}}, nil
+}
+
+func TestScheduler_ScrapeConcurrency(t *testing.T) {
+	var companies []*model.Company
+	for i, name := range []string{"A", "B", "C", "D", "E", "F"} {
+		companies = append(companies, &model.Company{ID: int64(i + 1), Name: name})
+	}
+	runLogRepo := &MockRunLogRepository{}
+	scr := &countingScraper{}
+	sched := New(NewMockRepository(), &MockCompanyRepository{Companies: companies}, runLogRepo, scr, &MockNotifier{}, "")
+
+	sched.ApplySettings(settings.Settings{Schedule: "0 9 * * *", NotificationMode: settings.NotifyAll, ScrapeConcurrency: 3})
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if scr.max < 2 || scr.max > 3 {
+		t.Errorf("expected up to 3 concurrent scrapes, got %d", scr.max)
+	}
+	// Results are still processed in company order.
+	for i, run := range runLogRepo.CompanyRuns {
+		if run.Company != companies[i].Name || run.NewJobs != 1 {
+			t.Errorf("unexpected company result %d: %+v", i, run)
+		}
+	}
+}
+
+func TestScheduler_SetSchedule(t *testing.T) {
+	sched := New(NewMockRepository(), nil, nil, &MockScraper{}, &MockNotifier{}, "")
+
+	if err := sched.SetSchedule("not a schedule", nil); err == nil {
+		t.Error("expected error for an invalid schedule")
+	}
+
+	// Before starting, the schedule is only remembered.
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	if err := sched.SetSchedule("0 8 * * *", tokyo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sched.cron != nil {
+		t.Error("expected scheduler to stay stopped")
+	}
+
+	if err := sched.StartWithSchedule("0 9 * * *"); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer sched.Stop()
+	if sched.cron.Location() != tokyo {
+		t.Errorf("expected the configured time zone, got %v", sched.cron.Location())
+	}
+
+	started := sched.cron
+	if err := sched.SetSchedule("*/5 * * * *", time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sched.cron == started || len(sched.cron.Entries()) != 1 || sched.cron.Location() != time.UTC {
+		t.Error("expected a new cron runner with one entry in UTC")
+	}
+	if sched.schedule != "*/5 * * * *" {
+		t.Errorf("unexpected schedule %q", sched.schedule)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, expr := range []string{"*/15 * * * *", "0 9 * * 1", "15m", "168h", "@weekly"} {
+		if _, err := ParseSchedule(expr); err != nil {
+			t.Errorf("expected %q to be valid, got %v", expr, err)
+		}
+	}
+	for _, expr := range []string{"", "30s", "every day", "* * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("expected %q to be invalid", expr)
+		}
+	}
+}
+
+func TestScheduler_CompanySchedules(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	companies := []*model.Company{
+		{ID: 1, Name: "Google"},
+		{ID: 2, Name: "Uber", Schedule: "15m"},
+		{ID: 3, Name: "Amazon", ActiveUntil: yesterday},
+		{ID: 4, Name: "DoorDash", Schedule: "0 9 * * 1"},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	notifier := &MockNotifier{}
+	sched := New(NewMockRepository(), &MockCompanyRepository{Companies: companies}, runLogRepo, &countingScraper{}, notifier, "+1234567890")
+
+	checked := func() []string {
+		var names []string
+		for _, run := range runLogRepo.CompanyRuns {
+			names = append(names, run.Company)
+		}
+		runLogRepo.CompanyRuns = nil
+		return names
+	}
+
+	// The global schedule checks active companies without their own schedule.
+	sched.runScheduled()
+	if got := checked(); len(got) != 1 || got[0] != "Google" {
+		t.Errorf("expected only Google to be checked, got %v", got)
+	}
+
+	// A company's own schedule checks only that company.
+	sched.markDue(false, 2)
+	if got := checked(); len(got) != 1 || got[0] != "Uber" {
+		t.Errorf("expected only Uber to be checked, got %v", got)
+	}
+
+	// Companies outside their active range are skipped without a run log.
+	logs := len(runLogRepo.Logs)
+	sched.markDue(false, 3)
+	if len(runLogRepo.Logs) != logs {
+		t.Errorf("expected no run log for a run without due companies, got %+v", runLogRepo.Logs[logs:])
+	}
+
+	// Manual runs check every active company.
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := checked(); len(got) != 3 {
+		t.Errorf("expected all active companies to be checked, got %v", got)
+	}
+
+	// The global entry plus one per company with a valid schedule.
+	if err := sched.StartWithSchedule("0 9 * * *"); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer sched.Stop()
+	if n := len(sched.cron.Entries()); n != 3 {
+		t.Errorf("expected 3 cron entries, got %d", n)
+	}
+	c, doorDash := sched.cron, sched.companyEntries[4]
+	companies[1].Schedule = "10s"
+	if err := sched.SyncCompanySchedules(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 2 {
+		t.Errorf("expected the invalid schedule to be left out, got %d entries", n)
+	}
+
+	// Only the changed company's entry is replaced; the others keep theirs.
+	companies[1].Schedule = "30m"
+	if err := sched.SyncCompanySchedules(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(sched.cron.Entries()); n != 3 {
+		t.Errorf("expected 3 cron entries, got %d", n)
+	}
+	if entry := sched.companyEntries[2]; entry.spec != "@every 30m0s" || !sched.cron.Entry(entry.id).Valid() {
+		t.Errorf("expected Uber every 30 minutes, got %+v", entry)
+	}
+	if sched.cron != c || sched.companyEntries[4] != doorDash {
+		t.Errorf("expected DoorDash's entry to be kept, got %+v", sched.companyEntries[4])
+	}
+}
+
+func TestScheduler_CompanyDueDuringRun(t *testing.T) {
+	companyRepo := &MockCompanyRepository{
+		Companies: []*model.Company{{ID: 1, Name: "Google"}, {ID: 2, Name: "Uber", Schedule: "15m"}},
+	}
+	runLogRepo := &MockRunLogRepository{}
+	scr := &blockingScraper{started: make(chan string), release: make(chan struct{})}
+	sched := New(NewMockRepository(), companyRepo, runLogRepo, scr, &MockNotifier{}, "")
+
+	run, _ := sched.Enqueue()
+	<-scr.started
+
+	// Uber comes due twice while the manual run is active.
+	sched.markDue(false, 2)
+	sched.markDue(false, 2)
+
+	scr.release <- struct{}{}
+	<-scr.started
+	scr.release <- struct{}{}
+	waitForRun(t, sched, run.ID)
+
+	// Once the manual run finishes, Uber is checked once more.
+	if name := <-scr.started; name != "Uber" {
+		t.Errorf("expected Uber to be checked after the run, got %s", name)
+	}
+	scr.release <- struct{}{}
+	next := waitForRun(t, sched, run.ID+1)
+	if next.Trigger != "scheduled" || next.CompaniesTotal != 1 {
+		t.Errorf("unexpected follow-up run %+v", next)
+	}
+	if got := testutil.ToFloat64(enabledCompanies); got != 2 {
+		t.Errorf("expected both enabled companies in the gauge, not only the due one, got %v", got)
+	}
+	select {
+	case name := <-scr.started:
+		t.Errorf("expected no further scrapes, got %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// MockStateStore keeps scheduler state in memory.
+type MockStateStore map[string]string
+
+func (m MockStateStore) Get(key string) (string, bool, error) {
+	v, ok := m[key]
+	return v, ok, nil
+}
+
+func (m MockStateStore) Set(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	newScheduler := func(store MockStateStore) (*Scheduler, *MockRunLogRepository) {
+		runLogRepo := &MockRunLogRepository{}
+		sched := New(NewMockRepository(), &MockCompanyRepository{}, runLogRepo, &MockScraper{}, &MockNotifier{}, "")
+		if err := sched.SetStateStore(store); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tokyo, _ := time.LoadLocation("Asia/Tokyo")
+		sched.SetSchedule("0 9 * * *", tokyo)
+		return sched, runLogRepo
+	}
+	// 2026-08-03 08:00 in Tokyo
+	now := time.Date(2026, 8, 2, 23, 0, 0, 0, time.UTC)
+
+	// Without a previous run, now becomes the baseline.
+	store := MockStateStore{}
+	sched, _ := newScheduler(store)
+	if sched.CatchUp(now) {
+		t.Error("expected no catch-up without a previous run")
+	}
+	if store[keyLastFire] != "2026-08-02T23:00:00Z" {
+		t.Errorf("expected the baseline to be saved, got %q", store[keyLastFire])
+	}
+
+	// The last run was yesterday at 9 AM Tokyo time; today's is not due yet.
+	store = MockStateStore{keyLastFire: "2026-08-02T00:00:00Z"}
+	sched, _ = newScheduler(store)
+	if sched.CatchUp(now) {
+		t.Error("expected no catch-up before the next scheduled run")
+	}
+	if status := sched.Status(); status.PreviousRun == nil || !status.PreviousRun.Equal(time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the saved run as the previous run, got %+v", status)
+	}
+
+	// Two days were missed; they are caught up on with a single run.
+	store = MockStateStore{keyLastFire: "2026-07-31T00:00:00Z"}
+	sched, runLogRepo := newScheduler(store)
+	if !sched.CatchUp(now) {
+		t.Fatal("expected a catch-up run")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if run, ok := sched.GetRun(1); ok && run.State == model.RunDone {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("catch-up run did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(runLogRepo.Logs) != 1 {
+		t.Errorf("expected one catch-up run, got %d", len(runLogRepo.Logs))
+	}
+	if store[keyLastFire] == "2026-07-31T00:00:00Z" {
+		t.Error("expected the catch-up run to be saved as the last scheduled run")
+	}
+}
+
+func TestScheduler_Status(t *testing.T) {
+	sched := New(NewMockRepository(), nil, nil, &MockScraper{}, &MockNotifier{}, "")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	sched.SetSchedule("0 9 * * *", tokyo)
+
+	status := sched.Status()
+	if status.State != model.SchedulerStopped || status.NextRun != nil || status.Timezone != "Asia/Tokyo" {
+		t.Errorf("unexpected status of a stopped scheduler %+v", status)
+	}
+
+	if err := sched.StartWithSchedule("0 9 * * *"); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer sched.Stop()
+	status = sched.Status()
+	if status.State != model.SchedulerRunning || status.Schedule != "0 9 * * *" || status.NextRun == nil {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if next := status.NextRun.In(tokyo); next.Hour() != 9 || next.Minute() != 0 || !next.After(time.Now()) {
+		t.Errorf("expected the next run at 9 AM Tokyo time, got %v", next)
+	}
+}
+
+func TestScheduler_PauseResume(t *testing.T) {
+	store := MockStateStore{}
+	sched := New(NewMockRepository(), nil, nil, &MockScraper{}, &MockNotifier{}, "")
+	sched.SetStateStore(store)
+	if err := sched.StartWithSchedule("0 9 * * *"); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer sched.Stop()
+
+	if err := sched.Pause(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := sched.Status()
+	if status.State != model.SchedulerPaused || status.NextRun != nil || sched.cron != nil {
+		t.Errorf("expected a paused scheduler without a next run, got %+v", status)
+	}
+	if store[keyPaused] != "true" {
+		t.Errorf("expected the paused state to be saved, got %q", store[keyPaused])
+	}
+	if sched.CatchUp(time.Now()) {
+		t.Error("expected no catch-up while paused")
+	}
+
+	// The paused state survives a restart, and starting keeps it paused.
+	restarted := New(NewMockRepository(), nil, nil, &MockScraper{}, &MockNotifier{}, "")
+	restarted.SetStateStore(store)
+	if err := restarted.StartWithSchedule("0 10 * * *"); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	if !restarted.Paused() || restarted.cron != nil {
+		t.Error("expected the restarted scheduler to stay paused")
+	}
+
+	if err := restarted.Resume(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer restarted.Stop()
+	status = restarted.Status()
+	if status.State != model.SchedulerRunning || status.Schedule != "0 10 * * *" || status.NextRun == nil {
+		t.Errorf("expected the resumed scheduler to run its schedule, got %+v", status)
+	}
+	if store[keyPaused] != "false" {
+		t.Errorf("expected the resumed state to be saved, got %q", store[keyPaused])
+	}
+}
+
+func TestScheduler_Shutdown(t *testing.T) {
+	newScheduler := func() (*Scheduler, *blockingScraper, *MockRunLogRepository) {
+		companyRepo := &MockCompanyRepository{Companies: []*model.Company{{ID: 1, Name: "Google"}, {ID: 2, Name: "Uber"}}}
+		runLogRepo := &MockRunLogRepository{}
+		scr := &blockingScraper{started: make(chan string), release: make(chan struct{})}
+		return New(NewMockRepository(), companyRepo, runLogRepo, scr, &MockNotifier{}, ""), scr, runLogRepo
+	}
+
+	// Without an active run, shutdown is immediate and due companies are
+	// no longer checked.
+	sched, _, runLogRepo := newScheduler()
+	if err := sched.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sched.markDue(true)
+	if len(runLogRepo.Logs) != 0 {
+		t.Errorf("expected no run after shutdown, got %+v", runLogRepo.Logs)
+	}
+
+	// An active run is waited for.
+	sched, scr, runLogRepo := newScheduler()
+	run, _ := sched.Enqueue()
+	<-scr.started
+	done := make(chan error)
+	go func() { done <- sched.Shutdown(context.Background()) }()
+	scr.release <- struct{}{}
+	<-scr.started
+	scr.release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runLogRepo.Logs) != 1 || runLogRepo.Logs[0].Status != "success" {
+		t.Errorf("expected the run to finish, got %+v", runLogRepo.Logs)
+	}
+	if finished, _ := sched.GetRun(run.ID); finished.State != model.RunDone {
+		t.Errorf("expected the run to be done, got %+v", finished)
+	}
+
+	// A run still going at the deadline is recorded as interrupted.
+	sched, scr, runLogRepo = newScheduler()
+	repo := sched.repo.(*MockRepository)
+	notifier := sched.notifier.(*MockNotifier)
+	scr.Jobs = []*model.Job{{Company: "Google", Title: "SWE Intern", URL: "https://google.com/job/1"}}
+	run, _ = sched.Enqueue()
+	<-scr.started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := sched.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+	if len(runLogRepo.Logs) != 1 || runLogRepo.Logs[0].Status != "interrupted" {
+		t.Errorf("expected an interrupted run log, got %+v", runLogRepo.Logs)
+	}
+	if interrupted, _ := sched.GetRun(run.ID); interrupted.State != model.RunInterrupted {
+		t.Errorf("expected the run to be interrupted, got %+v", interrupted)
+	}
+
+	// The run stops before the next company and saves nothing more.
+	scr.release <- struct{}{}
+	select {
+	case name := <-scr.started:
+		t.Errorf("expected the run to stop, but it checked %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if len(runLogRepo.Logs) != 1 {
+		t.Errorf("expected no run log after shutdown, got %+v", runLogRepo.Logs)
+	}
+	if len(repo.Jobs) != 0 || len(notifier.SentMessages) != 0 {
+		t.Errorf("expected no job saved or notified after shutdown, got %v and %q", repo.Jobs, notifier.SentMessages)
+	}
+}
+
+// captureLogs sends the default logger's records to the returned buffer as
+// JSON until the test ends.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, output, flags := slog.Default(), log.Writer(), log.Flags()
+	t.Cleanup(func() {
+		slog.SetDefault(logger)
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	})
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	return &buf
+}
+
+// loggingEnricher logs through the logger the scheduler passes it.
+type loggingEnricher struct{}
+
+func (loggingEnricher) Enrich(job *model.Job) {}
+
+func (loggingEnricher) EnrichContext(ctx context.Context, job *model.Job) {
+	logging.FromContext(ctx).Info("enriched", "url", job.URL)
+}
+
+func TestScheduler_RunNow_LogAttributes(t *testing.T) {
+	buf := captureLogs(t)
+
+	companyRepo := &MockCompanyRepository{Companies: []*model.Company{
+		{ID: 3, Name: "Google", CareerURL: "https://google.com/careers", SearchTerm: "intern"},
+	}}
+	scr := &MockScraper{Jobs: []*model.Job{{Company: "Google", Title: "SDE Intern", URL: "https://google.com/job/1"}}}
+	sched := New(NewMockRepository(), companyRepo, &MockRunLogRepository{}, scr, &MockNotifier{}, "+1234567890")
+	sched.AddEnricher(loggingEnricher{})
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := make(map[string]map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("expected JSON records, got %q", line)
+		}
+		records[record["msg"].(string)] = record
+	}
+
+	for _, msg := range []string{"job check started", "scraped company", "new job", "enriched", "job check finished"} {
+		record, ok := records[msg]
+		if !ok {
+			t.Errorf("expected a %q record, got %v", msg, records)
+			continue
+		}
+		if record["run_id"] != 1.0 {
+			t.Errorf("expected %q to carry the run ID, got %v", msg, record)
+		}
+	}
+	for _, msg := range []string{"scraped company", "new job", "enriched"} {
+		if record := records[msg]; record["company"] != "Google" || record["company_id"] != 3.0 {
+			t.Errorf("expected %q to carry the company, got %v", msg, record)
+		}
+	}
+}
+
+func TestScheduler_RunNow_DefaultScraperPartialFailure(t *testing.T) {
+	scr := &partialScraper{MockScraper{Jobs: []*model.Job{{Company: "Google", Title: "SDE Intern", URL: "https://google.com/job/1"}}}}
+	repo := NewMockRepository()
+	runLogRepo := &MockRunLogRepository{}
+	sched := New(repo, &MockCompanyRepository{}, runLogRepo, scr, &MockNotifier{}, "+1234567890")
+	if err := sched.RunNow(); err != nil {
+		t.Fatalf("expected failed companies not to fail the run, got %v", err)
+	}
+	if len(repo.Jobs) != 1 || runLogRepo.Logs[0].Status != "success" {
+		t.Errorf("expected the jobs found to be saved, got %d jobs and status %q", len(repo.Jobs), runLogRepo.Logs[0].Status)
+	}
+}
+
+// partialScraper finds jobs at some default companies and fails at others.
+type partialScraper struct {
+	MockScraper
+}
+
+func (p *partialScraper) ScrapeAll() ([]*model.Job, error) {
+	return p.Jobs, errors.Join(&scraper.CompanyError{Company: "Uber", Class: "timeout", Err: errors.New("deadline exceeded")})
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+
+	"intern-job-tracker/internal/events"
+	"intern-job-tracker/internal/model"
+)
+
+// Shutdown stops scheduled checks and waits for the active run, including
+// its pending notifications, to finish. Due companies that have not started
+// yet are dropped. If ctx ends first, the run is cancelled and recorded with
+// the status "interrupted", and ctx's error is returned. Either way, the
+// scheduler saves nothing afterwards, so the database can be closed: the run
+// checks before each company, job and notification it saves or sends, and
+// stops there.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.Stop()
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+
+	select {
+	case <-s.runs.idle():
+		s.markClosed()
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.markClosed()
+	if active := s.runs.current(); active != nil {
+		s.runs.cancel(active.ID)
+	}
+	run := s.runs.finishActive(model.RunInterrupted, "shutdown deadline exceeded")
+	if run == nil {
+		return nil
+	}
+	s.publish(run, events.RunFinished, run)
+	logger := runLogger(run)
+	logger.Warn("run did not finish in time, recording it as interrupted")
+
+	runLog := &model.RunLog{
+		RunAt:            run.QueuedAt,
+		CompaniesChecked: run.CompaniesDone,
+		JobsFound:        run.JobsFound,
+		NewJobs:          run.NewJobs,
+		Status:           "interrupted",
+		ErrorMessage:     run.Error,
+	}
+	if run.StartedAt != nil {
+		runLog.RunAt = *run.StartedAt
+		runLog.DurationMs = run.FinishedAt.Sub(*run.StartedAt).Milliseconds()
+	}
+	runsTotal.WithLabelValues(runLog.Status).Inc()
+	if s.runLogRepo != nil {
+		if err := s.runLogRepo.Create(runLog); err != nil {
+			logger.Error("failed to save run log", "error", err)
+		}
+	}
+	return ctx.Err()
+}
+
+// markClosed stops the scheduler from saving anything more.
+func (s *Scheduler) markClosed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+// isClosing reports whether Shutdown has been called.
+func (s *Scheduler) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// isClosed reports whether Shutdown has returned or is about to.
+func (s *Scheduler) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"intern-job-tracker/internal/model"
+
+	"github.com/robfig/cron/v3"
+)
+
+// StateStore persists scheduler state across restarts.
+type StateStore interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string) error
+}
+
+// State keys.
+const (
+	// keyLastFire holds when the global schedule last fired.
+	keyLastFire = "scheduler_last_fire"
+	// keyPaused is "true" while scheduled checks are paused.
+	keyPaused = "scheduler_paused"
+)
+
+// SetStateStore enables remembering when the schedule last fired, so that
+// runs missed while the server was down or asleep can be caught up on, and
+// whether scheduled checks are paused. Call it before starting the scheduler.
+func (s *Scheduler) SetStateStore(store StateStore) error {
+	value, ok, err := store.Get(keyLastFire)
+	if err != nil {
+		return err
+	}
+	paused, _, err := store.Get(keyPaused)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = store
+	s.paused = paused == "true"
+	s.lastFire = nil
+	if ok {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			slog.Warn("ignoring invalid last scheduled run", "value", value, "error", err)
+			return nil
+		}
+		s.lastFire = &t
+	}
+	return nil
+}
+
+// recordFire remembers that the global schedule fired at t.
+func (s *Scheduler) recordFire(t time.Time) {
+	s.mu.Lock()
+	s.lastFire = &t
+	store := s.state
+	s.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	if err := store.Set(keyLastFire, t.UTC().Format(time.RFC3339)); err != nil {
+		slog.Warn("failed to save last scheduled run", "error", err)
+	}
+}
+
+// CatchUp starts one check in the background if the global schedule should
+// have fired since it last did, for example because the machine was asleep,
+// and reports whether it did. Several missed runs are caught up on with a
+// single check. The first time, there is nothing to compare with, so now is
+// recorded instead.
+func (s *Scheduler) CatchUp(now time.Time) bool {
+	s.mu.Lock()
+	last, schedule, loc, paused := s.lastFire, s.schedule, s.location, s.paused
+	s.mu.Unlock()
+
+	if paused {
+		return false
+	}
+	if last == nil {
+		s.recordFire(now)
+		return false
+	}
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return false
+	}
+	missed := sched.Next(last.In(locationOrLocal(loc)))
+	if !missed.Before(now) {
+		return false
+	}
+
+	slog.Info("missed a scheduled run, catching up", "missed", missed)
+	go s.runScheduled()
+	return true
+}
+
+// Pause stops scheduled checks, including companies' own schedules, until
+// Resume is called, also across restarts. Manual runs still work, and a run
+// in progress is not interrupted.
+func (s *Scheduler) Pause() error {
+	if err := s.savePaused(true); err != nil {
+		return err
+	}
+	s.Stop()
+	slog.Info("scheduled checks paused")
+	return nil
+}
+
+// Resume restarts scheduled checks after Pause.
+func (s *Scheduler) Resume() error {
+	if err := s.savePaused(false); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	schedule := s.schedule
+	s.mu.Unlock()
+	if schedule == "" {
+		schedule = "0 9 * * *"
+	}
+	if err := s.StartWithSchedule(schedule); err != nil {
+		return err
+	}
+	slog.Info("scheduled checks resumed")
+	return nil
+}
+
+// Paused reports whether scheduled checks are paused.
+func (s *Scheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// savePaused persists and sets whether scheduled checks are paused.
+func (s *Scheduler) savePaused(paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != nil {
+		if err := s.state.Set(keyPaused, strconv.FormatBool(paused)); err != nil {
+			return err
+		}
+	}
+	s.paused = paused
+	return nil
+}
+
+// Status describes the global schedule and the active run, if any.
+func (s *Scheduler) Status() model.SchedulerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loc := locationOrLocal(s.location)
+	status := model.SchedulerStatus{
+		State:       model.SchedulerStopped,
+		Schedule:    s.schedule,
+		Timezone:    loc.String(),
+		PreviousRun: s.lastFire,
+		ActiveRun:   s.runs.current(),
+	}
+	if s.paused {
+		status.State = model.SchedulerPaused
+		return status
+	}
+	if s.cron == nil {
+		return status
+	}
+	status.State = model.SchedulerRunning
+	if sched, err := cron.ParseStandard(s.schedule); err == nil {
+		next := sched.Next(time.Now().In(loc))
+		status.NextRun = &next
+	}
+	return status
+}
+
+// locationOrLocal returns loc, or the server's local time zone if loc is nil.
+func locationOrLocal(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+	return loc
+}
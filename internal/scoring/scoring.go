@@ -0,0 +1,294 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+// Preferences describes what makes a posting relevant to the user.
+type Preferences struct {
+	// TitleKeywords maps a lowercase keyword or phrase to the points awarded
+	// when it appears in the job title. Negative weights penalize a match.
+	TitleKeywords map[string]float64
+
+	// PreferredLocations are matched case-insensitively against the job location.
+	PreferredLocations []string
+	LocationWeight     float64
+	RemoteWeight       float64
+
+	// CompanyTiers maps a company name to its tier (1 is the most preferred).
+	// TierWeights maps each tier to the points it is worth.
+	CompanyTiers map[string]int
+	TierWeights  map[int]float64
+
+	// TargetTerm is the internship term being recruited for, e.g. "Summer 2027".
+	// If empty, it is the DefaultTargetTerm of the time a job is scored.
+	TargetTerm string
+	TermWeight float64
+
+	// FreshnessWeight is awarded to a brand-new posting and halves every
+	// FreshnessHalfLife after it was posted, or discovered if the posting
+	// date is unknown.
+	FreshnessWeight   float64
+	FreshnessHalfLife time.Duration
+}
+
+// DefaultPreferences returns preferences tuned for summer SWE internships.
+// They have no preferred locations or company tiers, which only the user can
+// choose.
+func DefaultPreferences() Preferences {
+	return Preferences{
+		TitleKeywords: map[string]float64{
+			"software":         3,
+			"engineer":         2,
+			"swe":              3,
+			"sde":              3,
+			"backend":          1,
+			"machine learning": 1,
+			"senior":           -5,
+			"manager":          -3,
+		},
+		LocationWeight: 2,
+		RemoteWeight:   1,
+		TierWeights: map[int]float64{
+			1: 3,
+			2: 2,
+			3: 1,
+		},
+		TermWeight:        4,
+		FreshnessWeight:   2,
+		FreshnessHalfLife: 7 * 24 * time.Hour,
+	}
+}
+
+// DefaultTargetTerm returns the summer term currently being recruited for.
+// Recruiting for a summer starts the previous July, so from July onwards the
+// target is next year's summer.
+func DefaultTargetTerm(now time.Time) string {
+	year := now.Year()
+	if now.Month() >= time.July {
+		year++
+	}
+	return fmt.Sprintf("Summer %d", year)
+}
+
+// ParseLocations parses a comma-separated list of preferred locations, such
+// as "Seattle, New York".
+func ParseLocations(s string) []string {
+	var locations []string
+	for _, loc := range strings.Split(s, ",") {
+		if loc = strings.TrimSpace(loc); loc != "" {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// ParseCompanyTiers parses a comma-separated list of company tiers, such as
+// "Google=1,Stripe=2". Tiers run from 1, the most preferred, to 3.
+func ParseCompanyTiers(s string) (map[string]int, error) {
+	tiers := make(map[string]int)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid company tier %q, expected company=tier", entry)
+		}
+		tier, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || tier < 1 || tier > 3 {
+			return nil, fmt.Errorf("invalid tier %q for %s, expected 1, 2 or 3", strings.TrimSpace(value), name)
+		}
+		tiers[name] = tier
+	}
+	return tiers, nil
+}
+
+// Scorer computes relevance scores for jobs.
+type Scorer struct {
+	prefs Preferences
+	now   func() time.Time
+}
+
+// New creates a new Scorer with the given preferences.
+func New(prefs Preferences) *Scorer {
+	return &Scorer{prefs: prefs, now: time.Now}
+}
+
+// Enrich scores the job and stores the result on it.
+func (s *Scorer) Enrich(job *model.Job) {
+	job.Score, job.ScoreBreakdown = s.Score(job)
+}
+
+// Store is the job storage needed to rescore stored jobs.
+type Store interface {
+	GetAll() ([]*model.Job, error)
+	UpdateScore(id int64, score float64, breakdown []model.ScoreComponent) error
+}
+
+// Rescore recomputes the score of every stored job and returns the number of
+// jobs whose score changed. It scores jobs saved before scoring existed and,
+// run periodically, lets freshness decay after a job was discovered.
+func (s *Scorer) Rescore(store Store) (int, error) {
+	jobs, err := store.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, job := range jobs {
+		score, breakdown := s.Score(job)
+		if score == job.Score && slices.Equal(breakdown, job.ScoreBreakdown) {
+			continue
+		}
+		if err := store.UpdateScore(job.ID, score, breakdown); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// Score returns the job's total score and the components that make it up.
+func (s *Scorer) Score(job *model.Job) (float64, []model.ScoreComponent) {
+	var components []model.ScoreComponent
+	add := func(factor string, points float64, reason string) {
+		if points != 0 {
+			components = append(components, model.ScoreComponent{Factor: factor, Points: round(points), Reason: reason})
+		}
+	}
+
+	title := strings.ToLower(job.Title)
+	location := strings.ToLower(job.Location)
+
+	// Iterate keywords in a stable order so breakdowns are reproducible.
+	keywords := make([]string, 0, len(s.prefs.TitleKeywords))
+	for kw := range s.prefs.TitleKeywords {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+	for _, kw := range keywords {
+		if containsWord(title, kw) {
+			add("keyword", s.prefs.TitleKeywords[kw], fmt.Sprintf("title mentions %q", kw))
+		}
+	}
+
+	for _, loc := range s.prefs.PreferredLocations {
+		if location != "" && strings.Contains(location, strings.ToLower(loc)) {
+			add("location", s.prefs.LocationWeight, fmt.Sprintf("located in %s", loc))
+			break
+		}
+	}
+
+	if containsWord(location, "remote") || containsWord(title, "remote") {
+		add("remote", s.prefs.RemoteWeight, "remote eligible")
+	}
+
+	if tier, ok := s.prefs.CompanyTiers[job.Company]; ok {
+		add("company", s.prefs.TierWeights[tier], fmt.Sprintf("%s is a tier %d company", job.Company, tier))
+	}
+
+	if points, reason := s.termPoints(title); points != 0 {
+		add("term", points, reason)
+	}
+
+	if s.prefs.FreshnessWeight != 0 && s.prefs.FreshnessHalfLife > 0 {
+		// Prefer the date the company says it posted the job.
+		verb, since := "discovered", job.DiscoveredAt
+		if job.Detail != nil && job.Detail.PostedAt != nil {
+			verb, since = "posted", *job.Detail.PostedAt
+		}
+		if since.IsZero() {
+			since = s.now()
+		}
+		age := s.now().Sub(since)
+		if age < 0 {
+			age = 0
+		}
+		decay := math.Pow(0.5, float64(age)/float64(s.prefs.FreshnessHalfLife))
+		add("freshness", s.prefs.FreshnessWeight*decay, verb+" "+formatAge(age))
+	}
+
+	total := 0.0
+	for _, c := range components {
+		total += c.Points
+	}
+	return round(total), components
+}
+
+var yearPattern = regexp.MustCompile(`\b20\d{2}\b`)
+
+// termPoints compares the title against the target term. A title naming the
+// target term scores the full weight, one naming only the season scores half,
+// and one naming a different year is penalized.
+func (s *Scorer) termPoints(title string) (float64, string) {
+	if s.prefs.TermWeight == 0 {
+		return 0, ""
+	}
+	term := s.prefs.TargetTerm
+	if term == "" {
+		term = DefaultTargetTerm(s.now())
+	}
+	target := strings.ToLower(term)
+	if strings.Contains(title, target) {
+		return s.prefs.TermWeight, fmt.Sprintf("matches %s", term)
+	}
+
+	targetYear := yearPattern.FindString(target)
+	if year := yearPattern.FindString(title); year != "" && targetYear != "" && year != targetYear {
+		return -s.prefs.TermWeight, fmt.Sprintf("posted for %s, not %s", year, targetYear)
+	}
+
+	season := strings.TrimSpace(yearPattern.ReplaceAllString(target, ""))
+	if season != "" && containsWord(title, season) {
+		return s.prefs.TermWeight / 2, fmt.Sprintf("mentions %s without a year", season)
+	}
+	return 0, ""
+}
+
+// containsWord reports whether phrase appears in s on word boundaries.
+func containsWord(s, phrase string) bool {
+	if s == "" || phrase == "" {
+		return false
+	}
+	for i := 0; ; {
+		j := strings.Index(s[i:], phrase)
+		if j < 0 {
+			return false
+		}
+		start := i + j
+		end := start + len(phrase)
+		if (start == 0 || !isWordChar(s[start-1])) && (end == len(s) || !isWordChar(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordChar(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+func formatAge(d time.Duration) string {
+	if d < time.Hour {
+		return "just now"
+	}
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
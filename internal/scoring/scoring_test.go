@@ -0,0 +1,202 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+func testScorer(prefs Preferences, now time.Time) *Scorer {
+	s := New(prefs)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestScorer_KeywordsAndTerm(t *testing.T) {
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	prefs := Preferences{
+		TitleKeywords: map[string]float64{"software": 3, "senior": -5},
+		TargetTerm:    "Summer 2027",
+		TermWeight:    4,
+	}
+	s := testScorer(prefs, now)
+
+	score, breakdown := s.Score(&model.Job{Title: "Software Engineering Intern, Summer 2027"})
+	if score != 7 {
+		t.Errorf("expected score 7, got %v (%+v)", score, breakdown)
+	}
+	if len(breakdown) != 2 {
+		t.Errorf("expected 2 components, got %d", len(breakdown))
+	}
+
+	score, _ = s.Score(&model.Job{Title: "Software Engineering Intern, Summer 2026"})
+	if score != -1 {
+		t.Errorf("expected stale term to be penalized, got %v", score)
+	}
+
+	score, _ = s.Score(&model.Job{Title: "Software Intern - Summer"})
+	if score != 5 {
+		t.Errorf("expected half term weight for season only, got %v", score)
+	}
+}
+
+func TestScorer_KeywordWordBoundaries(t *testing.T) {
+	s := testScorer(Preferences{TitleKeywords: map[string]float64{"swe": 3}}, time.Now())
+
+	if score, _ := s.Score(&model.Job{Title: "SWE Intern"}); score != 3 {
+		t.Errorf("expected keyword match, got %v", score)
+	}
+	if score, _ := s.Score(&model.Job{Title: "Answer Desk Intern"}); score != 0 {
+		t.Errorf("expected no match inside another word, got %v", score)
+	}
+}
+
+func TestScorer_LocationCompanyAndFreshness(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	prefs := Preferences{
+		PreferredLocations: []string{"Seattle"},
+		LocationWeight:     2,
+		RemoteWeight:       1,
+		CompanyTiers:       map[string]int{"Amazon": 1},
+		TierWeights:        map[int]float64{1: 3},
+		FreshnessWeight:    2,
+		FreshnessHalfLife:  7 * 24 * time.Hour,
+	}
+	s := testScorer(prefs, now)
+
+	job := &model.Job{
+		Company:      "Amazon",
+		Title:        "SDE Intern",
+		Location:     "Seattle, WA or Remote",
+		DiscoveredAt: now.Add(-7 * 24 * time.Hour),
+	}
+	score, breakdown := s.Score(job)
+
+	// location 2 + remote 1 + tier 3 + freshness 2 * 0.5
+	if score != 7 {
+		t.Errorf("expected score 7, got %v (%+v)", score, breakdown)
+	}
+}
+
+func TestScorer_Enrich(t *testing.T) {
+	s := testScorer(Preferences{TitleKeywords: map[string]float64{"intern": 1}}, time.Now())
+	job := &model.Job{Title: "Data Intern"}
+
+	s.Enrich(job)
+
+	if job.Score != 1 || len(job.ScoreBreakdown) != 1 {
+		t.Errorf("expected job to be scored, got %v %+v", job.Score, job.ScoreBreakdown)
+	}
+}
+
+func TestDefaultTargetTerm(t *testing.T) {
+	cases := map[time.Month]string{
+		time.March:   "Summer 2026",
+		time.July:    "Summer 2027",
+		time.October: "Summer 2027",
+	}
+	for month, want := range cases {
+		got := DefaultTargetTerm(time.Date(2026, month, 1, 0, 0, 0, 0, time.UTC))
+		if got != want {
+			t.Errorf("%s: expected %s, got %s", month, want, got)
+		}
+	}
+}
+
+func TestScorer_FreshnessUsesPostedDate(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	prefs := Preferences{FreshnessWeight: 4, FreshnessHalfLife: 7 * 24 * time.Hour}
+	s := testScorer(prefs, now)
+
+	posted := now.Add(-14 * 24 * time.Hour)
+	job := &model.Job{
+		Title:        "Intern",
+		DiscoveredAt: now,
+		Detail:       &model.JobDetail{PostedAt: &posted},
+	}
+	score, breakdown := s.Score(job)
+
+	if score != 1 {
+		t.Errorf("expected two half-lives of decay, got %v (%+v)", score, breakdown)
+	}
+}
+
+type fakeStore struct {
+	jobs    []*model.Job
+	updated map[int64]float64
+}
+
+func (f *fakeStore) GetAll() ([]*model.Job, error) {
+	return f.jobs, nil
+}
+
+func (f *fakeStore) UpdateScore(id int64, score float64, breakdown []model.ScoreComponent) error {
+	f.updated[id] = score
+	return nil
+}
+
+func TestScorer_Rescore(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	prefs := Preferences{FreshnessWeight: 4, FreshnessHalfLife: 7 * 24 * time.Hour}
+	s := testScorer(prefs, now)
+
+	// Scored when discovered a week ago, and never since.
+	stale := &model.Job{ID: 1, Title: "Intern", DiscoveredAt: now.Add(-7 * 24 * time.Hour), Score: 4}
+	unscored := &model.Job{ID: 2, Title: "Intern", DiscoveredAt: now.Add(-14 * 24 * time.Hour)}
+	current := &model.Job{ID: 3, Title: "Intern", DiscoveredAt: now}
+	s.Enrich(current)
+	store := &fakeStore{jobs: []*model.Job{stale, unscored, current}, updated: make(map[int64]float64)}
+
+	n, err := s.Rescore(store)
+	if err != nil {
+		t.Fatalf("rescore failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 jobs rescored, got %d (%v)", n, store.updated)
+	}
+	if store.updated[1] != 2 || store.updated[2] != 1 {
+		t.Errorf("expected freshness to decay, got %v", store.updated)
+	}
+	if _, ok := store.updated[3]; ok {
+		t.Error("expected an unchanged score not to be written")
+	}
+}
+
+func TestScorer_TargetTermFromScoringTime(t *testing.T) {
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	s := testScorer(Preferences{TermWeight: 4}, now)
+	job := &model.Job{Title: "Software Intern, Summer 2027"}
+
+	if score, _ := s.Score(job); score != -4 {
+		t.Errorf("expected Summer 2027 to be off target in June 2026, got %v", score)
+	}
+	// A scorer created before July targets the next summer once July comes.
+	s.now = func() time.Time { return now.AddDate(0, 1, 0) }
+	if score, _ := s.Score(job); score != 4 {
+		t.Errorf("expected Summer 2027 to be on target in July 2026, got %v", score)
+	}
+}
+
+func TestParseCompanyTiers(t *testing.T) {
+	tiers, err := ParseCompanyTiers("Google=1, Jane Street = 2,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tiers) != 2 || tiers["Google"] != 1 || tiers["Jane Street"] != 2 {
+		t.Errorf("unexpected tiers %v", tiers)
+	}
+
+	for _, s := range []string{"Google", "=1", "Google=first", "Google=4"} {
+		if _, err := ParseCompanyTiers(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestParseLocations(t *testing.T) {
+	got := ParseLocations(" Seattle, New York ,,")
+	if len(got) != 2 || got[0] != "Seattle" || got[1] != "New York" {
+		t.Errorf("unexpected locations %q", got)
+	}
+}
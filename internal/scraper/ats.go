@@ -0,0 +1,259 @@
+package scraper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Applicant tracking systems recognized by Detect. Each is also a source
+// type, reading a company's jobs from its board on the platform.
+const (
+	PlatformGreenhouse = "greenhouse"
+	PlatformLever      = "lever"
+	PlatformWorkday    = "workday"
+	PlatformAshby      = "ashby"
+	PlatformICIMS      = "icims"
+	PlatformJobvite    = "jobvite"
+)
+
+// Detection is the applicant tracking system found behind a career page.
+type Detection struct {
+	Platform string `json:"platform"`
+	// SourceID identifies the company's board on the platform, in the form
+	// Company.SourceID takes: the board token on Greenhouse, the company's
+	// name on Lever, Ashby and Jobvite, "host/tenant/site" on Workday and the
+	// portal's host on iCIMS.
+	SourceID string `json:"source_id"`
+	// Identifiers name the parts of SourceID, such as "board_token" on
+	// Greenhouse or "tenant" and "site" on Workday.
+	Identifiers map[string]string `json:"identifiers"`
+	// Evidence says what gave the platform away: "url", "redirect",
+	// "script", "iframe", "meta" or "link".
+	Evidence string `json:"evidence"`
+	// URL is the address the platform was recognized in.
+	URL string `json:"url"`
+}
+
+// detectTimeout bounds how long Detect waits for a career page, as it runs
+// while a company is being added.
+const detectTimeout = 10 * time.Second
+
+// Detect finds the applicant tracking system serving a career page. The URL
+// itself is tried first, then the URLs it redirects to, then the page's
+// embed scripts, iframes, meta tags and links. It returns nil without an
+// error if no platform is recognized.
+func (s *Scraper) Detect(careerURL string) (*Detection, error) {
+	if d := matchPlatformURL(careerURL); d != nil {
+		d.Evidence, d.URL = "url", careerURL
+		return d, nil
+	}
+
+	var redirects []string
+	client := *s.client
+	if client.Timeout == 0 || client.Timeout > detectTimeout {
+		client.Timeout = detectTimeout
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		redirects = append(redirects, req.URL.String())
+		if matchPlatformURL(req.URL.String()) != nil {
+			// The platform is known; its page isn't needed.
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	resp, err := client.Get(careerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", careerURL, err)
+	}
+	defer resp.Body.Close()
+
+	for _, r := range redirects {
+		if d := matchPlatformURL(r); d != nil {
+			d.Evidence, d.URL = "redirect", r
+			return d, nil
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, careerURL)
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxDetailPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", careerURL, err)
+	}
+	return DetectPage(resp.Request.URL.String(), page), nil
+}
+
+// DetectPage finds the applicant tracking system serving a fetched career
+// page, judged by its URL and markup. It returns nil if none is recognized.
+func DetectPage(pageURL string, page []byte) *Detection {
+	if d := matchPlatformURL(pageURL); d != nil {
+		d.Evidence, d.URL = "url", pageURL
+		return d
+	}
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return nil
+	}
+	base, _ := url.Parse(pageURL)
+
+	// Embeds are better evidence than links, which may point anywhere.
+	found := make(map[string]*Detection)
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		var evidence, ref string
+		switch n.Data {
+		case "script", "iframe":
+			evidence, ref = n.Data, attr(n, "src")
+		case "meta":
+			evidence, ref = "meta", attr(n, "content")
+		case "a", "link":
+			evidence, ref = "link", attr(n, "href")
+		}
+		if ref == "" || found[evidence] != nil {
+			return true
+		}
+		if u, err := url.Parse(strings.TrimSpace(ref)); err == nil && base != nil {
+			ref = base.ResolveReference(u).String()
+		}
+		if d := matchPlatformURL(ref); d != nil {
+			d.Evidence, d.URL = evidence, ref
+			found[evidence] = d
+		}
+		return true
+	})
+	for _, evidence := range []string{"script", "iframe", "meta", "link"} {
+		if d := found[evidence]; d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// slugPattern matches the board names platforms put in their URLs.
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// localePattern matches the locale Workday puts before the site name.
+var localePattern = regexp.MustCompile(`^[a-z]{2}-[A-Z]{2}$`)
+
+// matchPlatformURL recognizes the URL of a job board, or of a platform API or
+// embed script naming one.
+func matchPlatformURL(raw string) *Detection {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	var segs []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	seg := func(i int) string {
+		if i < len(segs) {
+			return segs[i]
+		}
+		return ""
+	}
+
+	switch {
+	case host == "boards-api.greenhouse.io" || host == "api.greenhouse.io":
+		// /v1/boards/{token}/jobs
+		if seg(1) == "boards" {
+			return slugDetection(PlatformGreenhouse, "board_token", seg(2))
+		}
+	case hasDomain(host, "greenhouse.io"):
+		// boards.greenhouse.io/{token}, or the embed script
+		// boards.greenhouse.io/embed/job_board/js?for={token}
+		if seg(0) == "embed" {
+			return slugDetection(PlatformGreenhouse, "board_token", u.Query().Get("for"))
+		}
+		return slugDetection(PlatformGreenhouse, "board_token", seg(0))
+	case host == "api.lever.co":
+		// /v0/postings/{company}
+		if seg(1) == "postings" {
+			return slugDetection(PlatformLever, "company", seg(2))
+		}
+	case hasDomain(host, "lever.co"):
+		if strings.HasPrefix(host, "jobs.") {
+			return slugDetection(PlatformLever, "company", seg(0))
+		}
+	case host == "api.ashbyhq.com":
+		// /posting-api/job-board/{organization}
+		if seg(0) == "posting-api" && seg(1) == "job-board" {
+			return slugDetection(PlatformAshby, "organization", seg(2))
+		}
+	case hasDomain(host, "ashbyhq.com"):
+		if host == "jobs.ashbyhq.com" {
+			return slugDetection(PlatformAshby, "organization", seg(0))
+		}
+	case hasDomain(host, "myworkdayjobs.com"):
+		// {tenant}.wd5.myworkdayjobs.com/[{locale}/]{site}, or the API path
+		// /wday/cxs/{tenant}/{site}
+		tenant, site := strings.Split(host, ".")[0], seg(0)
+		if seg(0) == "wday" && seg(1) == "cxs" {
+			tenant, site = seg(2), seg(3)
+		} else if localePattern.MatchString(site) {
+			site = seg(1)
+		}
+		return workdayDetection(host, tenant, site)
+	case hasDomain(host, "myworkdaysite.com"):
+		// wd5.myworkdaysite.com/[{locale}/]recruiting/{tenant}/{site}
+		i := 0
+		if localePattern.MatchString(seg(0)) {
+			i = 1
+		}
+		if seg(i) == "recruiting" {
+			return workdayDetection(host, seg(i+1), seg(i+2))
+		}
+		if seg(0) == "wday" && seg(1) == "cxs" {
+			return workdayDetection(host, seg(2), seg(3))
+		}
+	case hasDomain(host, "icims.com"):
+		// careers-{company}.icims.com
+		if label := strings.TrimSuffix(host, ".icims.com"); label != host && label != "www" && !strings.Contains(label, ".") {
+			return &Detection{Platform: PlatformICIMS, SourceID: host, Identifiers: map[string]string{"portal": host}}
+		}
+	case hasDomain(host, "jobvite.com"):
+		if host == "jobs.jobvite.com" {
+			return slugDetection(PlatformJobvite, "company", seg(0))
+		}
+	}
+	return nil
+}
+
+func hasDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func slugDetection(platform, name, slug string) *Detection {
+	if !slugPattern.MatchString(slug) {
+		return nil
+	}
+	return &Detection{Platform: platform, SourceID: slug, Identifiers: map[string]string{name: slug}}
+}
+
+func workdayDetection(host, tenant, site string) *Detection {
+	if !slugPattern.MatchString(tenant) || !slugPattern.MatchString(site) {
+		return nil
+	}
+	return &Detection{
+		Platform:    PlatformWorkday,
+		SourceID:    host + "/" + tenant + "/" + site,
+		Identifiers: map[string]string{"host": host, "tenant": tenant, "site": site},
+	}
+}
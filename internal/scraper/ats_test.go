@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectPage(t *testing.T) {
+	tests := []struct {
+		url, page          string
+		platform, sourceID string
+		evidence           string
+	}{
+		{"https://boards.greenhouse.io/acme", "", PlatformGreenhouse, "acme", "url"},
+		{"https://job-boards.greenhouse.io/acme/jobs/123", "", PlatformGreenhouse, "acme", "url"},
+		{"https://jobs.lever.co/acme", "", PlatformLever, "acme", "url"},
+		{"https://acme.wd5.myworkdayjobs.com/en-US/External", "", PlatformWorkday, "acme.wd5.myworkdayjobs.com/acme/External", "url"},
+		{"https://wd1.myworkdaysite.com/recruiting/acme/Careers", "", PlatformWorkday, "wd1.myworkdaysite.com/acme/Careers", "url"},
+		{"https://jobs.ashbyhq.com/acme", "", PlatformAshby, "acme", "url"},
+		{"https://careers-acme.icims.com/jobs/search", "", PlatformICIMS, "careers-acme.icims.com", "url"},
+		{"https://jobs.jobvite.com/acme/jobs", "", PlatformJobvite, "acme", "url"},
+		{"https://acme.com/careers", `<script src="https://boards.greenhouse.io/embed/job_board/js?for=acme"></script>`, PlatformGreenhouse, "acme", "script"},
+		{"https://acme.com/careers", `<iframe src="https://jobs.lever.co/acme?embed=true"></iframe>`, PlatformLever, "acme", "iframe"},
+		{"https://acme.com/careers", `<meta property="og:url" content="https://jobs.ashbyhq.com/acme">`, PlatformAshby, "acme", "meta"},
+		// Embeds win over links, wherever they are on the page.
+		{"https://acme.com/careers", `<a href="https://jobs.lever.co/other">Old board</a>
+			<script src="https://boards-api.greenhouse.io/v1/boards/acme/jobs?content=true"></script>`, PlatformGreenhouse, "acme", "script"},
+		{"https://acme.com/careers", `<a href="/jobs">Jobs</a>`, "", "", ""},
+		{"https://boards.greenhouse.io/", "", "", "", ""},
+		{"https://notgreenhouse.io/jobs", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		d := DetectPage(tt.url, []byte(tt.page))
+		if tt.platform == "" {
+			if d != nil {
+				t.Errorf("DetectPage(%q) = %+v, want nil", tt.url, d)
+			}
+			continue
+		}
+		if d == nil || d.Platform != tt.platform || d.SourceID != tt.sourceID || d.Evidence != tt.evidence {
+			t.Errorf("DetectPage(%q) = %+v, want %s %s from %s", tt.url, d, tt.platform, tt.sourceID, tt.evidence)
+		}
+	}
+
+	d := DetectPage("https://acme.wd5.myworkdayjobs.com/External", nil)
+	if d == nil || d.Identifiers["tenant"] != "acme" || d.Identifiers["site"] != "External" {
+		t.Errorf("expected the Workday tenant and site, got %+v", d)
+	}
+}
+
+func TestScraper_Detect_Redirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/careers":
+			http.Redirect(w, r, "/board?next=https://jobs.lever.co/acme", http.StatusFound)
+		case "/embed":
+			w.Write([]byte(`<html><body><iframe src="https://jobs.ashbyhq.com/acme"></iframe></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := NewScraper(server.Client())
+	// The redirect names no platform, so the page it leads to must be fetched.
+	if _, err := s.Detect(server.URL + "/careers"); err == nil {
+		t.Error("expected an error for a page that can't be fetched")
+	}
+
+	d, err := s.Detect(server.URL + "/embed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d == nil || d.Platform != PlatformAshby || d.Evidence != "iframe" {
+		t.Errorf("unexpected detection %+v", d)
+	}
+
+	// Redirects to a platform are recognized without fetching it.
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://boards.greenhouse.io/acme", http.StatusMovedPermanently)
+	}))
+	defer redirecting.Close()
+	d, err = NewScraper(redirecting.Client()).Detect(redirecting.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d == nil || d.Platform != PlatformGreenhouse || d.SourceID != "acme" || d.Evidence != "redirect" {
+		t.Errorf("unexpected detection %+v", d)
+	}
+}
+
+func TestValidateSource(t *testing.T) {
+	valid := [][3]string{
+		{"", "", ""},
+		{SourceHTML, "", "job=li"},
+		{PlatformGreenhouse, "acme", ""},
+		{PlatformWorkday, "acme.wd5.myworkdayjobs.com/acme/External", ""},
+		{PlatformICIMS, "careers-acme.icims.com", ""},
+		{PlatformJobvite, "acme", "job=tr"},
+	}
+	for _, v := range valid {
+		if err := ValidateSource(v[0], v[1], v[2]); err != nil {
+			t.Errorf("ValidateSource(%q, %q, %q): unexpected error %v", v[0], v[1], v[2], err)
+		}
+	}
+	invalid := [][3]string{
+		{"rss", "", ""},
+		{"", "acme", ""},
+		{PlatformGreenhouse, "", ""},
+		{PlatformLever, "acme/jobs", ""},
+		{PlatformWorkday, "acme", ""},
+		{PlatformWorkday, "example.com/acme/External", ""},
+		{PlatformICIMS, "example.com", ""},
+		{PlatformGreenhouse, "acme", "job=li"},
+	}
+	for _, v := range invalid {
+		if err := ValidateSource(v[0], v[1], v[2]); err == nil {
+			t.Errorf("ValidateSource(%q, %q, %q): expected an error", v[0], v[1], v[2])
+		}
+	}
+}
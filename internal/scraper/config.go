@@ -1,10 +1,103 @@
 package scraper
 
+import (
+	"fmt"
+	"strings"
+
+	"intern-job-tracker/internal/model"
+)
+
+// Source types, which say how a company's jobs are read.
+const (
+	// SourceHTML reads job links from the career page's HTML.
+	SourceHTML = "html"
+)
+
 // CompanyConfig defines how to scrape a company's career page.
 type CompanyConfig struct {
 	Name       string
 	CareerURL  string
 	SearchTerm string // Search term to look for (intern, internship, etc.)
+	// SourceType is SourceHTML or one of the platforms. Empty means
+	// SourceHTML.
+	SourceType string
+	// SourceID identifies the company's board on its platform, in the form
+	// of Detection.SourceID. For SourceMarkdown it is the local file the list
+	// is read from, if not CareerURL. It is empty for SourceHTML.
+	SourceID string
+	// Selectors narrow the page to its job listings. Zero means every link
+	// whose text contains the search term.
+	Selectors ListSelectors
+}
+
+// ConfigFor returns the scrape configuration of a company. It fails if the
+// company's source is invalid.
+func ConfigFor(company *model.Company) (CompanyConfig, error) {
+	if err := ValidateSource(company.SourceType, company.SourceID, company.Selectors); err != nil {
+		return CompanyConfig{}, err
+	}
+	selectors, _ := ParseListSelectors(company.Selectors)
+	return CompanyConfig{
+		Name:       company.Name,
+		CareerURL:  company.CareerURL,
+		SearchTerm: company.SearchTerm,
+		SourceType: company.SourceType,
+		SourceID:   company.SourceID,
+		Selectors:  selectors,
+	}, nil
+}
+
+// ValidateSource checks a company's source type, source ID and selectors.
+// Selectors only apply to pages scraped as HTML, which iCIMS and Jobvite
+// boards are.
+func ValidateSource(sourceType, sourceID, selectors string) error {
+	switch sourceType {
+	case "", SourceHTML:
+		if sourceID != "" {
+			return fmt.Errorf("source_id requires a platform source type")
+		}
+	case PlatformGreenhouse, PlatformLever, PlatformAshby, PlatformJobvite:
+		if !slugPattern.MatchString(sourceID) {
+			return fmt.Errorf("invalid %s source_id %q, expected the board name from its URL", sourceType, sourceID)
+		}
+	case PlatformWorkday:
+		parts := strings.Split(sourceID, "/")
+		if len(parts) != 3 || workdayDetection(parts[0], parts[1], parts[2]) == nil ||
+			!(hasDomain(parts[0], "myworkdayjobs.com") || hasDomain(parts[0], "myworkdaysite.com")) {
+			return fmt.Errorf("invalid workday source_id %q, expected host/tenant/site", sourceID)
+		}
+	case SourceMarkdown:
+		// Any source ID is a file name.
+	case PlatformICIMS:
+		if d := matchPlatformURL("https://" + sourceID); d == nil || d.Platform != PlatformICIMS || d.SourceID != sourceID {
+			return fmt.Errorf("invalid icims source_id %q, expected the portal's host", sourceID)
+		}
+	default:
+		return fmt.Errorf("unknown source type %q, expected %s", sourceType, strings.Join(SourceTypes(), ", "))
+	}
+	sel, err := ParseListSelectors(selectors)
+	if err != nil {
+		return err
+	}
+	if sel.Job != "" && !scrapesHTML(sourceType) {
+		return fmt.Errorf("selectors are not used with source type %q", sourceType)
+	}
+	return nil
+}
+
+// SourceTypes returns the valid source types.
+func SourceTypes() []string {
+	return []string{SourceHTML, SourceMarkdown, PlatformGreenhouse, PlatformLever, PlatformWorkday, PlatformAshby, PlatformICIMS, PlatformJobvite}
+}
+
+// scrapesHTML reports whether a source type reads jobs from a page's links
+// rather than from an API.
+func scrapesHTML(sourceType string) bool {
+	switch sourceType {
+	case "", SourceHTML, PlatformICIMS, PlatformJobvite:
+		return true
+	}
+	return false
 }
 
 // DefaultCompanies returns the list of companies to monitor.
@@ -0,0 +1,557 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"intern-job-tracker/internal/logging"
+	"intern-job-tracker/internal/model"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DetailSelectors locate job information on detail pages that do not embed a
+// JSON-LD JobPosting. Each selector is a space-separated chain of simple
+// selectors ("tag", ".class", "#id" or combinations such as "div.content").
+type DetailSelectors struct {
+	Description  string
+	Requirements string
+	PostedDate   string
+	Deadline     string
+	Salary       string
+}
+
+// ParseDetailSelectors parses selectors written as comma-separated
+// key=selector pairs, e.g. "description=.job-body,deadline=#apply-by".
+func ParseDetailSelectors(s string) (DetailSelectors, error) {
+	var sel DetailSelectors
+	if strings.TrimSpace(s) == "" {
+		return sel, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return sel, fmt.Errorf("invalid selector %q: expected key=selector", pair)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "description":
+			sel.Description = value
+		case "requirements":
+			sel.Requirements = value
+		case "posted":
+			sel.PostedDate = value
+		case "deadline":
+			sel.Deadline = value
+		case "salary":
+			sel.Salary = value
+		default:
+			return sel, fmt.Errorf("unknown selector key %q", key)
+		}
+	}
+	return sel, nil
+}
+
+// maxDetailPageSize caps how much of a detail page is read.
+const maxDetailPageSize = 5 << 20
+
+// DetailFetcher fetches job detail pages, waiting at least the configured
+// interval between requests so career sites are not hammered.
+type DetailFetcher struct {
+	client    *http.Client
+	interval  time.Duration
+	selectors DetailSelectors
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewDetailFetcher creates a new DetailFetcher with the given HTTP client.
+func NewDetailFetcher(client *http.Client, interval time.Duration, selectors DetailSelectors) *DetailFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &DetailFetcher{client: client, interval: interval, selectors: selectors}
+}
+
+// Enrich fetches the job's detail page and stores the result on it. On a new
+// job's first fetch, the posting date a curated list gave is kept if the page
+// has none; later fetches replace the details whole, so fields the page
+// dropped show up as changes. Failures are logged and leave the job's
+// details as they were.
+func (f *DetailFetcher) Enrich(job *model.Job) {
+	f.EnrichContext(context.Background(), job)
+}
+
+// EnrichContext is Enrich logging to the logger carried by ctx.
+func (f *DetailFetcher) EnrichContext(ctx context.Context, job *model.Job) {
+	detail, err := f.Fetch(job.URL)
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not fetch job details", "url", job.URL, "error", err)
+		return
+	}
+	if job.ID == 0 && job.Detail != nil && detail.PostedAt == nil {
+		detail.PostedAt = job.Detail.PostedAt
+	}
+	job.Detail = detail
+}
+
+// Fetch downloads a detail page and extracts the job information from it.
+func (f *DetailFetcher) Fetch(pageURL string) (*model.JobDetail, error) {
+	f.wait()
+
+	resp, err := f.client.Get(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, pageURL)
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxDetailPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	detail, err := ParseDetail(bytes.NewReader(page), f.selectors)
+	if err != nil {
+		return nil, err
+	}
+	detail.Page = page
+	return detail, nil
+}
+
+// wait blocks until the rate limit allows another request.
+func (f *DetailFetcher) wait() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if next := f.last.Add(f.interval); time.Now().Before(next) {
+		time.Sleep(time.Until(next))
+	}
+	f.last = time.Now()
+}
+
+// ParseDetail extracts job information from a detail page. A JSON-LD
+// JobPosting is preferred; selectors fill in anything it does not provide.
+func ParseDetail(r io.Reader, selectors DetailSelectors) (*model.JobDetail, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse detail page: %w", err)
+	}
+
+	detail := &model.JobDetail{FetchedAt: time.Now()}
+	if posting := findJobPosting(doc); posting != nil {
+		posting.apply(detail)
+	}
+
+	fill := func(field *string, selector string) {
+		if *field == "" && selector != "" {
+			if n := querySelector(doc, selector); n != nil {
+				*field = nodeText(n)
+			}
+		}
+	}
+	fillDate := func(field **time.Time, selector string) {
+		var text string
+		fill(&text, selector)
+		if *field == nil && text != "" {
+			*field = parseDate(text)
+		}
+	}
+	fill(&detail.Description, selectors.Description)
+	fill(&detail.Requirements, selectors.Requirements)
+	fill(&detail.Salary, selectors.Salary)
+	fillDate(&detail.PostedAt, selectors.PostedDate)
+	fillDate(&detail.Deadline, selectors.Deadline)
+
+	if detail.Description == "" {
+		detail.Description = metaDescription(doc)
+	}
+	return detail, nil
+}
+
+// jobPosting is the subset of a schema.org JobPosting used by the tracker.
+type jobPosting struct {
+	Type                   any               `json:"@type"`
+	Description            string            `json:"description"`
+	Qualifications         json.RawMessage   `json:"qualifications"`
+	ExperienceRequirements json.RawMessage   `json:"experienceRequirements"`
+	EducationRequirements  json.RawMessage   `json:"educationRequirements"`
+	DatePosted             string            `json:"datePosted"`
+	ValidThrough           string            `json:"validThrough"`
+	BaseSalary             json.RawMessage   `json:"baseSalary"`
+	Graph                  []json.RawMessage `json:"@graph"`
+}
+
+func (p *jobPosting) isJobPosting() bool {
+	switch t := p.Type.(type) {
+	case string:
+		return t == "JobPosting"
+	case []any:
+		for _, v := range t {
+			if v == "JobPosting" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *jobPosting) apply(d *model.JobDetail) {
+	d.Description = htmlToText(p.Description)
+	for _, raw := range []json.RawMessage{p.Qualifications, p.ExperienceRequirements, p.EducationRequirements} {
+		if text := requirementText(raw); text != "" {
+			d.Requirements = text
+			break
+		}
+	}
+	d.PostedAt = parseDate(p.DatePosted)
+	d.Deadline = parseDate(p.ValidThrough)
+	d.Salary = salaryText(p.BaseSalary)
+}
+
+// salaryText formats a baseSalary as e.g. "USD 40 - 50 per HOUR". Pages
+// write it as a MonetaryAmount whose value is a number, a numeric string or
+// a QuantitativeValue, or as a bare amount. Shapes it cannot read give "".
+func salaryText(raw json.RawMessage) string {
+	var v any
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return ""
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		if n := number(v); n != nil {
+			return formatAmount(*n)
+		}
+		return ""
+	}
+
+	var amount, unit string
+	switch value := obj["value"].(type) {
+	case map[string]any:
+		minValue, maxValue, exact := number(value["minValue"]), number(value["maxValue"]), number(value["value"])
+		switch {
+		case minValue != nil && maxValue != nil:
+			amount = fmt.Sprintf("%s - %s", formatAmount(*minValue), formatAmount(*maxValue))
+		case exact != nil:
+			amount = formatAmount(*exact)
+		case minValue != nil:
+			amount = formatAmount(*minValue)
+		}
+		unit, _ = value["unitText"].(string)
+	default:
+		if n := number(value); n != nil {
+			amount = formatAmount(*n)
+		}
+	}
+	if amount == "" {
+		return ""
+	}
+	currency, _ := obj["currency"].(string)
+	s := strings.TrimSpace(currency + " " + amount)
+	if unit != "" {
+		s += " per " + unit
+	}
+	return s
+}
+
+// number reads an amount written as a JSON number or a numeric string such
+// as "45" or "1,200".
+func number(v any) *float64 {
+	switch n := v.(type) {
+	case float64:
+		return &n
+	case string:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(n), ",", ""), 64)
+		if err == nil {
+			return &f
+		}
+	}
+	return nil
+}
+
+func formatAmount(f float64) string {
+	if f == float64(int64(f)) {
+		return fmt.Sprintf("%d", int64(f))
+	}
+	return fmt.Sprintf("%.2f", f)
+}
+
+// requirementText reads a requirement that may be a string or an object with
+// a description.
+func requirementText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return htmlToText(s)
+	}
+	var obj struct {
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		return htmlToText(obj.Description)
+	}
+	return ""
+}
+
+// findJobPosting returns the first JobPosting in the page's JSON-LD scripts.
+func findJobPosting(doc *html.Node) *jobPosting {
+	var found *jobPosting
+	walk(doc, func(n *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if n.Type == html.ElementNode && n.Data == "script" && attr(n, "type") == "application/ld+json" && n.FirstChild != nil {
+			found = decodeJobPosting([]byte(n.FirstChild.Data))
+		}
+		return true
+	})
+	return found
+}
+
+func decodeJobPosting(data []byte) *jobPosting {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		items = []json.RawMessage{data}
+	}
+	for _, item := range items {
+		var p jobPosting
+		if err := json.Unmarshal(item, &p); err != nil {
+			continue
+		}
+		if p.isJobPosting() {
+			return &p
+		}
+		if len(p.Graph) > 0 {
+			graph, _ := json.Marshal(p.Graph)
+			if nested := decodeJobPosting(graph); nested != nil {
+				return nested
+			}
+		}
+	}
+	return nil
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"01/02/2006",
+}
+
+// parseDate parses the date formats commonly found on career sites.
+func parseDate(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// htmlToText converts an HTML fragment, such as a JSON-LD description, to
+// plain text.
+func htmlToText(s string) string {
+	if !strings.Contains(s, "<") {
+		return strings.TrimSpace(html.UnescapeString(s))
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "div"}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	return nodeText(root)
+}
+
+// blockElements start a new line when converting HTML to text.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "ul": true, "ol": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "tr": true,
+}
+
+// nodeText returns the readable text of a node, one line per block element.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+		case html.ElementNode:
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+			if blockElements[n.Data] {
+				sb.WriteString("\n")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] {
+			sb.WriteString("\n")
+		}
+	}
+	visit(n)
+
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func metaDescription(doc *html.Node) string {
+	var desc string
+	walk(doc, func(n *html.Node) bool {
+		if desc != "" {
+			return false
+		}
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			if name := attr(n, "name") + attr(n, "property"); name == "description" || name == "og:description" {
+				desc = strings.TrimSpace(attr(n, "content"))
+			}
+		}
+		return true
+	})
+	return desc
+}
+
+// walk visits nodes depth-first until fn returns false.
+func walk(n *html.Node, fn func(*html.Node) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !walk(c, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// simpleSelector matches an element by tag, id and classes.
+type simpleSelector struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+func parseSimpleSelector(s string) simpleSelector {
+	var sel simpleSelector
+	// Split "div.a.b#c" into its parts, keeping the leading marker.
+	var parts []string
+	start := 0
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || s[i] == '.' || s[i] == '#' {
+			parts = append(parts, s[start:i])
+			start = i
+		}
+	}
+	for _, p := range parts {
+		switch {
+		case strings.HasPrefix(p, "."):
+			sel.classes = append(sel.classes, p[1:])
+		case strings.HasPrefix(p, "#"):
+			sel.id = p[1:]
+		default:
+			sel.tag = strings.ToLower(p)
+		}
+	}
+	return sel
+}
+
+func (s simpleSelector) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if s.tag != "" && s.tag != "*" && n.Data != s.tag {
+		return false
+	}
+	if s.id != "" && attr(n, "id") != s.id {
+		return false
+	}
+	classes := strings.Fields(attr(n, "class"))
+	for _, want := range s.classes {
+		found := false
+		for _, c := range classes {
+			if c == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// querySelector returns the first element matching a descendant chain of
+// simple selectors, such as "main div.description".
+func querySelector(root *html.Node, selector string) *html.Node {
+	var chain []simpleSelector
+	for _, part := range strings.Fields(selector) {
+		chain = append(chain, parseSimpleSelector(part))
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	return queryChain(root, chain)
+}
+
+func queryChain(root *html.Node, chain []simpleSelector) *html.Node {
+	var found *html.Node
+	walk(root, func(n *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if n != root && chain[0].matches(n) {
+			if len(chain) == 1 {
+				found = n
+				return false
+			}
+			if m := queryChain(n, chain[1:]); m != nil {
+				found = m
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
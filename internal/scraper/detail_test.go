@@ -0,0 +1,213 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+func TestParseDetail_JSONLD(t *testing.T) {
+	page := `<html><head>
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "Organization", "name": "Acme"},
+			{
+				"@type": "JobPosting",
+				"title": "Software Engineering Intern",
+				"description": "<p>Build things.</p><ul><li>Ship code</li></ul>",
+				"qualifications": "Pursuing a BS in CS",
+				"datePosted": "2026-09-01",
+				"validThrough": "2026-11-15T23:59:00-08:00",
+				"baseSalary": {
+					"@type": "MonetaryAmount",
+					"currency": "USD",
+					"value": {"@type": "QuantitativeValue", "minValue": 40, "maxValue": 55.5, "unitText": "HOUR"}
+				}
+			}
+		]
+	}
+	</script></head><body></body></html>`
+
+	detail, err := ParseDetail(strings.NewReader(page), DetailSelectors{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if detail.Description != "Build things.\nShip code" {
+		t.Errorf("unexpected description %q", detail.Description)
+	}
+	if detail.Requirements != "Pursuing a BS in CS" {
+		t.Errorf("unexpected requirements %q", detail.Requirements)
+	}
+	if detail.PostedAt == nil || detail.PostedAt.Format("2006-01-02") != "2026-09-01" {
+		t.Errorf("unexpected posted date %v", detail.PostedAt)
+	}
+	if detail.Deadline == nil || detail.Deadline.Month() != time.November {
+		t.Errorf("unexpected deadline %v", detail.Deadline)
+	}
+	if detail.Salary != "USD 40 - 55.50 per HOUR" {
+		t.Errorf("unexpected salary %q", detail.Salary)
+	}
+}
+
+func TestParseDetail_SalaryShapes(t *testing.T) {
+	tests := []struct {
+		name, salary, want string
+	}{
+		{"range", `{"currency":"USD","value":{"minValue":40,"maxValue":50,"unitText":"HOUR"}}`, "USD 40 - 50 per HOUR"},
+		{"number value", `{"currency":"USD","value":45}`, "USD 45"},
+		{"string value", `{"currency":"USD","value":"45"}`, "USD 45"},
+		{"quantitative string", `{"@type":"MonetaryAmount","currency":"USD","value":{"@type":"QuantitativeValue","value":"45","unitText":"HOUR"}}`, "USD 45 per HOUR"},
+		{"string bounds", `{"currency":"USD","value":{"minValue":"8,000","maxValue":"9,500","unitText":"MONTH"}}`, "USD 8000 - 9500 per MONTH"},
+		{"bare number", `7000`, "7000"},
+		{"unreadable", `{"currency":"USD","value":"competitive"}`, ""},
+		{"list", `[{"currency":"USD","value":45}]`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := `<script type="application/ld+json">{"@type":"JobPosting","description":"Build things.","datePosted":"2026-09-01","baseSalary":` + tt.salary + `}</script>`
+			detail, err := ParseDetail(strings.NewReader(page), DetailSelectors{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// A salary in any shape leaves the rest of the posting intact.
+			if detail.Description != "Build things." || detail.PostedAt == nil {
+				t.Errorf("expected the posting to be read, got %+v", detail)
+			}
+			if detail.Salary != tt.want {
+				t.Errorf("expected salary %q, got %q", tt.want, detail.Salary)
+			}
+		})
+	}
+}
+
+func TestParseDetail_Selectors(t *testing.T) {
+	page := `<html><head><meta name="description" content="Fallback text"></head><body>
+	<main>
+		<div class="section description"><p>Work on search.</p></div>
+		<div class="section requirements"><h3>Requirements</h3><p>Go or Python</p></div>
+		<span id="deadline">December 1, 2026</span>
+		<p class="pay">$45/hr</p>
+	</main>
+	</body></html>`
+
+	selectors, err := ParseDetailSelectors("description=main .description, requirements=div.requirements p,deadline=#deadline,salary=p.pay")
+	if err != nil {
+		t.Fatalf("failed to parse selectors: %v", err)
+	}
+
+	detail, err := ParseDetail(strings.NewReader(page), selectors)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if detail.Description != "Work on search." {
+		t.Errorf("unexpected description %q", detail.Description)
+	}
+	if detail.Requirements != "Go or Python" {
+		t.Errorf("unexpected requirements %q", detail.Requirements)
+	}
+	if detail.Deadline == nil || detail.Deadline.Day() != 1 {
+		t.Errorf("unexpected deadline %v", detail.Deadline)
+	}
+	if detail.Salary != "$45/hr" {
+		t.Errorf("unexpected salary %q", detail.Salary)
+	}
+}
+
+func TestParseDetail_MetaDescriptionFallback(t *testing.T) {
+	page := `<html><head><meta property="og:description" content="Join our team"></head></html>`
+
+	detail, err := ParseDetail(strings.NewReader(page), DetailSelectors{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if detail.Description != "Join our team" {
+		t.Errorf("unexpected description %q", detail.Description)
+	}
+}
+
+func TestParseDetailSelectors_Invalid(t *testing.T) {
+	if _, err := ParseDetailSelectors("description"); err == nil {
+		t.Error("expected error for missing selector")
+	}
+	if _, err := ParseDetailSelectors("title=h1"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestDetailFetcher_RateLimit(t *testing.T) {
+	var hits []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, time.Now())
+		w.Write([]byte(`<html><head><meta name="description" content="Intern role"></head></html>`))
+	}))
+	defer server.Close()
+
+	fetcher := NewDetailFetcher(&http.Client{}, 50*time.Millisecond, DetailSelectors{})
+	job := &model.Job{URL: server.URL}
+	fetcher.Enrich(job)
+	fetcher.Enrich(job)
+
+	if job.Detail == nil || job.Detail.Description != "Intern role" {
+		t.Fatalf("expected detail to be attached, got %+v", job.Detail)
+	}
+	if !strings.Contains(string(job.Detail.Page), `content="Intern role"`) {
+		t.Errorf("expected raw page to be kept, got %q", job.Detail.Page)
+	}
+	// Allow for the gap between the fetcher's clock and the server receiving
+	// the request.
+	if len(hits) != 2 || hits[1].Sub(hits[0]) < 40*time.Millisecond {
+		t.Errorf("expected requests about 50ms apart, got %v", hits)
+	}
+}
+
+func TestDetailFetcher_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	job := &model.Job{URL: server.URL}
+	NewDetailFetcher(&http.Client{}, 0, DetailSelectors{}).Enrich(job)
+
+	if job.Detail != nil {
+		t.Error("expected no detail for a missing page")
+	}
+}
+
+func TestDetailFetcher_KeepsListedPostingDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><meta name="description" content="Intern role"></head></html>`))
+	}))
+	defer server.Close()
+	fetcher := NewDetailFetcher(&http.Client{}, 0, DetailSelectors{})
+
+	// A curated list gave the posting date; the page gives none.
+	posted := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	job := &model.Job{URL: server.URL, Detail: &model.JobDetail{PostedAt: &posted}}
+	fetcher.Enrich(job)
+
+	if job.Detail.Description != "Intern role" {
+		t.Errorf("expected the fetched description, got %+v", job.Detail)
+	}
+	if job.Detail.PostedAt == nil || !job.Detail.PostedAt.Equal(posted) {
+		t.Errorf("expected the listed posting date to be kept, got %+v", job.Detail)
+	}
+
+	// Refetching a stored job replaces its details, so fields the page no
+	// longer has are seen as removed.
+	deadline := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+	stored := &model.Job{ID: 1, URL: server.URL, Detail: &model.JobDetail{PostedAt: &posted, Deadline: &deadline, Salary: "USD 45 per HOUR"}}
+	fetcher.Enrich(stored)
+
+	if stored.Detail.PostedAt != nil || stored.Detail.Deadline != nil || stored.Detail.Salary != "" {
+		t.Errorf("expected the refetched details alone, got %+v", stored.Detail)
+	}
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Fingerprint summarizes the structure of an HTML page: the set of tag and
+// class pairs it uses, ignoring text and how often each appears. Listing more
+// or fewer jobs leaves it unchanged, while a redesign usually changes it.
+// Class names containing digits are skipped, as they are often generated
+// per build.
+func Fingerprint(r io.Reader) string {
+	seen := make(map[string]bool)
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		t := z.Token()
+		seen[t.Data] = true
+		for _, attr := range t.Attr {
+			if attr.Key != "class" {
+				continue
+			}
+			for _, class := range strings.Fields(attr.Val) {
+				if !strings.ContainsAny(class, "0123456789") {
+					seen[t.Data+"."+class] = true
+				}
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// challengeMarkers are strings found on the interstitial pages bot protection
+// services serve instead of the requested page.
+var challengeMarkers = [][]byte{
+	[]byte("cf-chl"),
+	[]byte("challenge-platform"),
+	[]byte("<title>just a moment...</title>"),
+	[]byte("attention required! | cloudflare"),
+	[]byte("px-captcha"),
+	[]byte("_incapsula_resource"),
+	[]byte("verify you are a human"),
+	[]byte("are you a robot"),
+}
+
+// isChallengePage reports whether a page looks like a bot challenge.
+func isChallengePage(page []byte) bool {
+	lower := bytes.ToLower(page)
+	for _, marker := range challengeMarkers {
+		if bytes.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
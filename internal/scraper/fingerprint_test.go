@@ -0,0 +1,55 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	one := `<html><body><ul class="jobs"><li class="job css-1a2b"><a href="/1">Intern</a></li></ul></body></html>`
+	two := `<html><body><ul class="jobs"><li class="job css-9z8y"><a href="/1">Intern</a></li>
+		<li class="job css-9z8y"><a href="/2">Other Intern</a></li></ul></body></html>`
+	redesign := `<html><body><div class="openings"><div class="card"><a href="/1">Intern</a></div></div></body></html>`
+
+	a, b, c := Fingerprint(strings.NewReader(one)), Fingerprint(strings.NewReader(two)), Fingerprint(strings.NewReader(redesign))
+	if a == "" || a != b {
+		t.Errorf("expected listings of different length to share a fingerprint, got %q and %q", a, b)
+	}
+	if a == c {
+		t.Error("expected a redesigned page to have a different fingerprint")
+	}
+	if got := Fingerprint(strings.NewReader("")); got != "" {
+		t.Errorf("expected empty fingerprint for an empty page, got %q", got)
+	}
+}
+
+func TestScraper_Scrape_Challenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blocked":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<html><head><title>Attention Required! | Cloudflare</title></head></html>`))
+		case "/interstitial":
+			w.Write([]byte(`<html><head><title>Just a moment...</title></head><body><div id="challenge-platform"></div></body></html>`))
+		default:
+			w.Write([]byte(`<html><body><a href="/jobs/1">SWE Intern</a><p>Are you a robot? Join our robotics team.</p></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	scraper := NewScraper(&http.Client{})
+	for _, path := range []string{"/blocked", "/interstitial"} {
+		result, err := scraper.Scrape(CompanyConfig{Name: "Acme", CareerURL: server.URL + path, SearchTerm: "intern"})
+		if err == nil || !result.Challenge || result.ErrorClass != "challenge" {
+			t.Errorf("%s: expected a challenge error, got %+v (%v)", path, result, err)
+		}
+	}
+
+	// A page that lists jobs is not a challenge, whatever its text says.
+	result, err := scraper.Scrape(CompanyConfig{Name: "Acme", CareerURL: server.URL, SearchTerm: "intern"})
+	if err != nil || result.Challenge || len(result.Jobs) != 1 || result.Fingerprint == "" {
+		t.Errorf("expected a normal scrape, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,220 @@
+package scraper
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ListSelectors locate job listings on career pages where matching every
+// link finds too much or too little. Selectors use the syntax of
+// DetailSelectors.
+type ListSelectors struct {
+	// Job matches the element of each listing. The listing's link is the
+	// element itself if it is a link, or else the first link inside it.
+	Job string
+	// Title and Location match elements inside a listing. Without Title, the
+	// link text is the title.
+	Title    string
+	Location string
+}
+
+// ParseListSelectors parses selectors written as comma-separated
+// key=selector pairs, e.g. "job=li.opening,title=h3,location=.city".
+func ParseListSelectors(s string) (ListSelectors, error) {
+	var sel ListSelectors
+	if strings.TrimSpace(s) == "" {
+		return sel, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return sel, fmt.Errorf("invalid selector %q: expected key=selector", pair)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "job":
+			sel.Job = value
+		case "title":
+			sel.Title = value
+		case "location":
+			sel.Location = value
+		default:
+			return sel, fmt.Errorf("unknown selector key %q, expected job, title or location", key)
+		}
+	}
+	if sel.Job == "" && (sel.Title != "" || sel.Location != "") {
+		return sel, fmt.Errorf("title and location selectors require a job selector")
+	}
+	return sel, nil
+}
+
+// Rejection is a link on a career page that was not taken as a job, as
+// reported by Preview.
+type Rejection struct {
+	Text   string `json:"text"`
+	URL    string `json:"url,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Reasons for rejecting a link.
+const (
+	RejectNoLink       = "no link"
+	RejectNoText       = "no text"
+	RejectNoSearchTerm = "search term not in text"
+	RejectInvalidURL   = "invalid URL"
+	RejectDuplicate    = "duplicate URL"
+)
+
+// linkFilter decides which links are jobs, resolving their URLs and
+// reporting the others to reject, which may be nil.
+type linkFilter struct {
+	base       *url.URL
+	searchTerm string
+	seen       map[string]bool
+	reject     func(Rejection)
+}
+
+func newLinkFilter(baseURL, searchTerm string, reject func(Rejection)) *linkFilter {
+	base, _ := url.Parse(baseURL)
+	return &linkFilter{base: base, searchTerm: strings.ToLower(searchTerm), seen: make(map[string]bool), reject: reject}
+}
+
+// accept returns the resolved URL of a job link, or "" if it is not one.
+func (f *linkFilter) accept(href, text string) string {
+	reason := ""
+	var resolved string
+	switch {
+	case href == "":
+		reason = RejectNoLink
+	case text == "":
+		reason = RejectNoText
+	case !strings.Contains(strings.ToLower(text), f.searchTerm):
+		reason = RejectNoSearchTerm
+	default:
+		linkURL, err := url.Parse(href)
+		if err != nil {
+			reason = RejectInvalidURL
+			break
+		}
+		resolved = f.base.ResolveReference(linkURL).String()
+		if f.seen[resolved] {
+			reason = RejectDuplicate
+			break
+		}
+		f.seen[resolved] = true
+		return resolved
+	}
+	if f.reject != nil {
+		if resolved == "" {
+			resolved = href
+		}
+		f.reject(Rejection{Text: text, URL: resolved, Reason: reason})
+	}
+	return ""
+}
+
+// parseListings extracts job links from the elements matching sel.Job.
+func parseListings(r io.Reader, baseURL, searchTerm string, sel ListSelectors, reject func(Rejection)) ([]jobLink, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	filter := newLinkFilter(baseURL, searchTerm, reject)
+
+	var links []jobLink
+	for _, el := range querySelectorAll(doc, sel.Job) {
+		link := firstLink(el)
+
+		title := ""
+		if sel.Title != "" {
+			if n := querySelector(el, sel.Title); n != nil {
+				title = strings.Join(strings.Fields(nodeText(n)), " ")
+			}
+		} else if link != nil {
+			title = strings.Join(strings.Fields(nodeText(link)), " ")
+		}
+		href := ""
+		if link != nil {
+			href = attr(link, "href")
+		}
+
+		u := filter.accept(href, title)
+		if u == "" {
+			continue
+		}
+		job := jobLink{url: u, title: title}
+		if sel.Location != "" {
+			if n := querySelector(el, sel.Location); n != nil {
+				job.location = strings.Join(strings.Fields(nodeText(n)), " ")
+			}
+		}
+		links = append(links, job)
+	}
+	return links, nil
+}
+
+// firstLink returns n if it is a link, or else the first link inside it, or
+// nil.
+func firstLink(n *html.Node) *html.Node {
+	var found *html.Node
+	walk(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == html.ElementNode && c.Data == "a" && attr(c, "href") != "" {
+			found = c
+		}
+		return true
+	})
+	return found
+}
+
+// querySelectorAll returns the elements matching a descendant chain of simple
+// selectors, in document order. Matches nested in another match are
+// included.
+func querySelectorAll(root *html.Node, selector string) []*html.Node {
+	var chain []simpleSelector
+	for _, part := range strings.Fields(selector) {
+		chain = append(chain, parseSimpleSelector(part))
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	var matches []*html.Node
+	seen := make(map[*html.Node]bool)
+	var collect func(n *html.Node, chain []simpleSelector)
+	collect = func(n *html.Node, chain []simpleSelector) {
+		walk(n, func(c *html.Node) bool {
+			if c == n || !chain[0].matches(c) {
+				return true
+			}
+			if len(chain) == 1 {
+				if !seen[c] {
+					seen[c] = true
+					matches = append(matches, c)
+				}
+			} else {
+				collect(c, chain[1:])
+			}
+			return true
+		})
+	}
+	collect(root, chain)
+
+	// Chains of more than one selector can collect matches out of order.
+	order := make(map[*html.Node]int)
+	i := 0
+	walk(root, func(n *html.Node) bool {
+		order[n] = i
+		i++
+		return true
+	})
+	sort.Slice(matches, func(i, j int) bool { return order[matches[i]] < order[matches[j]] })
+	return matches
+}
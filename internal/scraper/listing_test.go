@@ -0,0 +1,110 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseListSelectors(t *testing.T) {
+	sel, err := ParseListSelectors("job=li.opening, title=h3 ,location=span.city")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sel.Job != "li.opening" || sel.Title != "h3" || sel.Location != "span.city" {
+		t.Errorf("unexpected selectors %+v", sel)
+	}
+
+	for _, s := range []string{"job", "company=.name", "title=h3"} {
+		if _, err := ParseListSelectors(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+const listingPage = `<html><body>
+	<nav><a href="/internships">Internships</a></nav>
+	<div id="results">
+		<div class="card"><a href="/jobs/1"><span>Software</span> <span>Intern</span></a><p class="where">Austin, TX</p></div>
+		<div class="card"><h4>Hardware Intern</h4><a href="/jobs/2">Apply</a></div>
+		<div class="card"><a href="/jobs/3">Staff Engineer</a></div>
+		<div class="card"><span>Closed Intern role</span></div>
+	</div>
+</body></html>`
+
+func TestScrape_Selectors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(listingPage))
+	}))
+	defer server.Close()
+
+	scraper := NewScraper(nil)
+	result, err := scraper.Preview(CompanyConfig{
+		Name: "Acme", CareerURL: server.URL, SearchTerm: "intern",
+		Selectors: ListSelectors{Job: "#results div.card", Location: ".where"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The navigation link is outside the listings, and the second card's
+	// link text does not mention the search term.
+	if len(result.Jobs) != 1 || result.Jobs[0].Title != "Software Intern" || result.Jobs[0].Location != "Austin, TX" {
+		t.Fatalf("unexpected jobs %+v", result.Jobs)
+	}
+	if result.Jobs[0].URL != server.URL+"/jobs/1" {
+		t.Errorf("expected a resolved URL, got %q", result.Jobs[0].URL)
+	}
+
+	reasons := make(map[string]string)
+	for _, r := range result.Rejected {
+		reasons[r.Text] = r.Reason
+	}
+	if reasons["Apply"] != RejectNoSearchTerm || reasons["Staff Engineer"] != RejectNoSearchTerm || reasons[""] != RejectNoLink {
+		t.Errorf("unexpected rejections %+v", result.Rejected)
+	}
+
+	// A title selector names the listing when the link text does not.
+	result, _ = scraper.Scrape(CompanyConfig{
+		Name: "Acme", CareerURL: server.URL, SearchTerm: "intern",
+		Selectors: ListSelectors{Job: "div.card", Title: "h4"},
+	})
+	if len(result.Jobs) != 1 || result.Jobs[0].Title != "Hardware Intern" || result.Jobs[0].URL != server.URL+"/jobs/2" {
+		t.Errorf("unexpected jobs %+v", result.Jobs)
+	}
+	if result.Rejected != nil {
+		t.Error("expected Scrape not to record rejections")
+	}
+}
+
+func TestPreview_Rejections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+			<a href="/jobs/1">Software Intern</a>
+			<a href="/jobs/1">Software Intern</a>
+			<a href="/team">Our team</a>
+			<a name="top">Intern top</a>
+			<a href="/jobs/2"><img src="logo.png"></a>
+		</body></html>`))
+	}))
+	defer server.Close()
+
+	result, err := NewScraper(nil).Preview(CompanyConfig{Name: "Acme", CareerURL: server.URL, SearchTerm: "intern"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(result.Jobs))
+	}
+	var got []string
+	for _, r := range result.Rejected {
+		got = append(got, r.Reason)
+	}
+	want := []string{RejectDuplicate, RejectNoSearchTerm, RejectNoLink, RejectNoText}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected rejections %v, got %v", want, got)
+	}
+	if result.FinalURL != server.URL {
+		t.Errorf("expected final URL %q, got %q", server.URL, result.FinalURL)
+	}
+}
@@ -0,0 +1,368 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"intern-job-tracker/internal/model"
+)
+
+// SourceMarkdown reads jobs from the Markdown tables of a curated internship
+// list, such as a GitHub README with company, role, location, link and date
+// columns. Each row becomes a job of the company it names, tagged with the
+// list's name as its source.
+const SourceMarkdown = "markdown"
+
+// RejectClosed is the reason rows marked closed in a Markdown list are not
+// taken as jobs.
+const RejectClosed = "closed"
+
+// Markers lists put on rows.
+const (
+	// continuationMarker stands for the company of the row above.
+	continuationMarker = "↳"
+	// closedMarker marks a role that no longer takes applications.
+	closedMarker = "🔒"
+)
+
+// maxListSize caps how much of a curated list is read. The largest lists
+// run to a few megabytes.
+const maxListSize = 10 << 20
+
+// markdownRow is a job row of a Markdown table.
+type markdownRow struct {
+	company  string
+	title    string
+	location string
+	url      string
+	posted   *time.Time
+	closed   bool
+}
+
+// markdownColumns are the positions of the columns of a Markdown table that
+// rows are read from, -1 when a table has no such column.
+type markdownColumns struct {
+	company, role, location, link, date int
+}
+
+// columnsFor recognizes the columns of a table by their headers. It returns
+// false if the table lacks a company or role column.
+func columnsFor(header []string) (markdownColumns, bool) {
+	cols := markdownColumns{-1, -1, -1, -1, -1}
+	for i, cell := range header {
+		name := strings.ToLower(cellText(cell))
+		words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		set := func(col *int) {
+			if *col < 0 {
+				*col = i
+			}
+		}
+		switch {
+		case strings.Contains(name, "company"):
+			set(&cols.company)
+		case strings.Contains(name, "role"), strings.Contains(name, "position"), strings.Contains(name, "title"):
+			set(&cols.role)
+		case strings.Contains(name, "location"):
+			set(&cols.location)
+		case strings.Contains(name, "link"), strings.Contains(name, "appl"):
+			set(&cols.link)
+		// Whole words, so "Stage" or "Language" is not taken for "Age".
+		case slices.Contains(words, "date"), slices.Contains(words, "posted"), slices.Contains(words, "age"):
+			set(&cols.date)
+		}
+	}
+	return cols, cols.company >= 0 && cols.role >= 0
+}
+
+// delimiterCell matches a cell of the line separating a table's header from
+// its rows, such as "---" or ":---:".
+var delimiterCell = regexp.MustCompile(`^:?-+:?$`)
+
+// parseMarkdownTables reads the job rows of every table with company and role
+// columns. Continuation rows take the company of the row above. It also
+// returns the number of such tables found.
+func parseMarkdownTables(r io.Reader, now time.Time) ([]markdownRow, int) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		lines = append(lines, strings.TrimSpace(sc.Text()))
+	}
+
+	var rows []markdownRow
+	tables := 0
+	for i := 0; i+1 < len(lines); i++ {
+		if !isTableLine(lines[i]) || !isDelimiterLine(lines[i+1]) {
+			continue
+		}
+		cols, ok := columnsFor(splitRow(lines[i]))
+		i++
+		if !ok {
+			continue
+		}
+		tables++
+
+		company := ""
+		for i+1 < len(lines) && isTableLine(lines[i+1]) {
+			i++
+			cells := splitRow(lines[i])
+			cell := func(col int) string {
+				if col >= 0 && col < len(cells) {
+					return cells[col]
+				}
+				return ""
+			}
+
+			row := markdownRow{
+				title:    cellText(cell(cols.role)),
+				location: cellText(cell(cols.location)),
+				posted:   parseListDate(cellText(cell(cols.date)), now),
+			}
+			if name := cellText(cell(cols.company)); strings.HasPrefix(name, continuationMarker) {
+				row.company = company
+			} else {
+				row.company, company = name, name
+			}
+			for _, c := range cells {
+				if strings.Contains(c, closedMarker) || isStruckThrough(c) {
+					row.closed = true
+				}
+			}
+			// The link column holds the application link. Lists without one
+			// link the role itself.
+			row.url = firstURL(cell(cols.link))
+			if row.url == "" {
+				row.url = firstURL(cell(cols.role))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, tables
+}
+
+func isTableLine(line string) bool {
+	return strings.HasPrefix(line, "|")
+}
+
+func isDelimiterLine(line string) bool {
+	if !isTableLine(line) {
+		return false
+	}
+	for _, cell := range splitRow(line) {
+		if !delimiterCell.MatchString(strings.TrimSpace(cell)) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitRow splits a table line into its cells. Escaped pipes stay in their
+// cell.
+func splitRow(line string) []string {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+var (
+	summaryTag   = regexp.MustCompile(`(?is)<summary>.*?</summary>`)
+	lineBreakTag = regexp.MustCompile(`(?i)<\s*/?\s*br\s*/?\s*>`)
+	htmlTag      = regexp.MustCompile(`<[^>]*>`)
+	markdownImg  = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	hrefAttr     = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+	linkTarget   = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	bareURL      = regexp.MustCompile(`https?://[^\s<>()|"']+`)
+)
+
+// cellText returns the plain text of a cell, without links, images, HTML
+// tags or emphasis. Line breaks, as used between locations, become commas.
+func cellText(cell string) string {
+	s := summaryTag.ReplaceAllString(cell, "")
+	s = lineBreakTag.ReplaceAllString(s, ", ")
+	s = htmlTag.ReplaceAllString(s, " ")
+	s = markdownImg.ReplaceAllString(s, "")
+	s = markdownLink.ReplaceAllString(s, "$1")
+	s = strings.NewReplacer("**", "", "__", "", "~~", "", "`", "").Replace(s)
+	s = html.UnescapeString(s)
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.ReplaceAll(s, " ,", ",")
+	return strings.Trim(s, ", ")
+}
+
+// firstURL returns the first link target in a cell: an HTML href, a Markdown
+// link or a bare URL.
+func firstURL(cell string) string {
+	best, at := "", len(cell)
+	for _, re := range []*regexp.Regexp{hrefAttr, linkTarget} {
+		if m := re.FindStringSubmatchIndex(cell); m != nil && m[0] < at {
+			best, at = cell[m[2]:m[3]], m[0]
+		}
+	}
+	if m := bareURL.FindStringIndex(cell); m != nil && m[0] < at {
+		best = cell[m[0]:m[1]]
+	}
+	return html.UnescapeString(best)
+}
+
+// isStruckThrough reports whether a cell's text is crossed out, as some lists
+// do for closed roles.
+func isStruckThrough(cell string) bool {
+	s := strings.TrimSpace(strings.Trim(strings.TrimSpace(cell), "*"))
+	return len(s) > 4 && strings.HasPrefix(s, "~~") && strings.HasSuffix(s, "~~")
+}
+
+// ageDays matches the ages some lists show instead of dates, such as "3d".
+var ageDays = regexp.MustCompile(`^(\d+)\s*d$`)
+
+// parseListDate parses a list's posting date: a full date, a month and day
+// such as "Oct 10", taken as the most recent such day, or an age in days.
+func parseListDate(s string, now time.Time) *time.Time {
+	if m := ageDays.FindStringSubmatch(strings.ToLower(s)); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		t := now.AddDate(0, 0, -days).Truncate(24 * time.Hour)
+		return &t
+	}
+	if t := parseDate(s); t != nil {
+		return t
+	}
+	for _, layout := range []string{"Jan 2", "January 2", "Jan 02", "01/02"} {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		t = t.AddDate(now.Year(), 0, 0)
+		if t.After(now) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return &t
+	}
+	return nil
+}
+
+// rawMarkdownURL turns the address of a file viewed on GitHub into the
+// address of its raw content. Other URLs are returned as they are.
+func rawMarkdownURL(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil || !strings.EqualFold(u.Host, "github.com") {
+		return pageURL
+	}
+	// /{owner}/{repo}/blob/{ref}/{path}
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 4)
+	if len(parts) < 4 || parts[2] != "blob" {
+		return pageURL
+	}
+	return "https://raw.githubusercontent.com/" + parts[0] + "/" + parts[1] + "/" + parts[3]
+}
+
+// scrapeMarkdown reads the jobs of a curated list, from the local file named
+// by the company's source ID or else from its career URL. Every open role is
+// taken: the list is curated already, and its roles need not name the search
+// term, as with co-ops or "SWE, Summer 2027".
+func (s *Scraper) scrapeMarkdown(config CompanyConfig, result *Result, reject func(Rejection)) error {
+	var data []byte
+	location := config.SourceID
+	if location != "" {
+		f, err := os.Open(location)
+		if err == nil {
+			data, err = io.ReadAll(io.LimitReader(f, maxListSize))
+			f.Close()
+		}
+		if err != nil {
+			result.ErrorClass = "read"
+			return fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		result.Pages = 1
+		result.Bytes = int64(len(data))
+		result.FinalURL = config.CareerURL
+	} else {
+		location = rawMarkdownURL(config.CareerURL)
+		resp, err := s.client.Get(location)
+		if err != nil {
+			result.ErrorClass = fetchErrorClass(err)
+			return fmt.Errorf("failed to fetch %s: %w", location, err)
+		}
+		defer resp.Body.Close()
+
+		result.StatusCode = resp.StatusCode
+		result.FinalURL = resp.Request.URL.String()
+		result.Pages = 1
+		body := &countingReader{r: io.LimitReader(resp.Body, maxListSize)}
+		data, _ = io.ReadAll(body)
+		result.Bytes = body.n
+		if resp.StatusCode != http.StatusOK {
+			result.ErrorClass = "http_status"
+			return fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, location)
+		}
+		if body.err != nil {
+			result.ErrorClass = "read"
+			return fmt.Errorf("failed to read %s: %w", location, body.err)
+		}
+	}
+
+	now := time.Now()
+	rows, tables := parseMarkdownTables(bytes.NewReader(data), now)
+	if tables == 0 {
+		result.ErrorClass = "read"
+		return fmt.Errorf("no table with company and role columns in %s", location)
+	}
+
+	filter := newLinkFilter(result.FinalURL, "", reject)
+	for _, row := range rows {
+		if row.closed {
+			if reject != nil {
+				reject(Rejection{Text: row.company + ": " + row.title, URL: row.url, Reason: RejectClosed})
+			}
+			continue
+		}
+		u := filter.accept(row.url, row.title)
+		if u == "" {
+			continue
+		}
+		company := row.company
+		if company == "" {
+			company = config.Name
+		}
+		job := &model.Job{
+			Company:      company,
+			Title:        row.title,
+			URL:          u,
+			Location:     row.location,
+			Source:       config.Name,
+			DiscoveredAt: now,
+		}
+		if row.posted != nil {
+			job.Detail = &model.JobDetail{PostedAt: row.posted, FetchedAt: now}
+		}
+		result.Jobs = append(result.Jobs, job)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const internshipList = `# Summer 2027 Internships
+
+Use this list to find internships. 🔒 means applications are closed.
+
+## Software Engineering
+
+| Company | Role | Location | Application/Link | Date Posted |
+| ------- | ---- | -------- | :--------------: | :---------: |
+| **[Stripe](https://stripe.com)** | Software Engineer Intern | San Francisco, CA | <a href="https://stripe.com/jobs/1?utm_source=list"><img src="apply.png" alt="Apply"></a> <a href="https://simplify.jobs/p/1"><img src="simplify.png"></a> | Oct 10 |
+| ↳ | Data Science Intern | New York, NY</br>Remote | <a href="https://stripe.com/jobs/2">Apply</a> | 2d |
+| ↳ | Infrastructure Intern | Seattle, WA | 🔒 | Sep 01 |
+| Acme \| Labs | ~~ML Intern~~ | Remote | [Apply](https://acme.com/jobs/3) | 2026-09-20 |
+| Globex | Software Engineering Co-op | <details><summary>**3 locations**</summary>Austin, TX<br>Boston, MA<br>Denver, CO</details> | [Apply](https://globex.com/jobs/4) | Oct 01 |
+| Initech | [Hardware Intern](/jobs/5) | Austin, TX | | Oct 02 |
+
+## Other
+
+| Name | Notes |
+| --- | --- |
+| Not a job | Ignored |
+`
+
+func TestParseMarkdownTables(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	rows, tables := parseMarkdownTables(strings.NewReader(internshipList), now)
+	if tables != 1 {
+		t.Errorf("expected 1 job table, got %d", tables)
+	}
+	if len(rows) != 6 {
+		t.Fatalf("expected 6 rows, got %d: %+v", len(rows), rows)
+	}
+
+	tests := []struct {
+		company, title, location, url string
+		closed                        bool
+		posted                        string
+	}{
+		{"Stripe", "Software Engineer Intern", "San Francisco, CA", "https://stripe.com/jobs/1?utm_source=list", false, "2026-10-10"},
+		{"Stripe", "Data Science Intern", "New York, NY, Remote", "https://stripe.com/jobs/2", false, "2026-10-16"},
+		{"Stripe", "Infrastructure Intern", "Seattle, WA", "", true, "2026-09-01"},
+		{"Acme | Labs", "ML Intern", "Remote", "https://acme.com/jobs/3", true, "2026-09-20"},
+		{"Globex", "Software Engineering Co-op", "Austin, TX, Boston, MA, Denver, CO", "https://globex.com/jobs/4", false, "2026-10-01"},
+		{"Initech", "Hardware Intern", "Austin, TX", "/jobs/5", false, "2026-10-02"},
+	}
+	for i, tt := range tests {
+		row := rows[i]
+		posted := ""
+		if row.posted != nil {
+			posted = row.posted.Format("2006-01-02")
+		}
+		if row.company != tt.company || row.title != tt.title || row.location != tt.location ||
+			row.url != tt.url || row.closed != tt.closed || posted != tt.posted {
+			t.Errorf("row %d: got %+v (posted %s), want %+v", i, row, posted, tt)
+		}
+	}
+}
+
+func TestColumnsFor(t *testing.T) {
+	tests := []struct {
+		header []string
+		date   int
+	}{
+		{[]string{"Company", "Role", "Date Posted"}, 2},
+		{[]string{"Company", "Role", "Age"}, 2},
+		{[]string{"Company", "Role", "Stage", "Language", "Page", "Posted"}, 5},
+		{[]string{"Company", "Role", "Stage"}, -1},
+	}
+	for _, tt := range tests {
+		cols, ok := columnsFor(tt.header)
+		if !ok || cols.date != tt.date {
+			t.Errorf("%q: expected the date in column %d, got %+v", tt.header, tt.date, cols)
+		}
+	}
+}
+
+func TestParseListDate(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"Jan 02":     "2026-01-02",
+		"Dec 30":     "2025-12-30",
+		"0d":         "2026-01-05",
+		"2026-01-01": "2026-01-01",
+		"soon":       "",
+	}
+	for in, want := range tests {
+		got := ""
+		if t := parseListDate(in, now); t != nil {
+			got = t.Format("2006-01-02")
+		}
+		if got != want {
+			t.Errorf("parseListDate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestScrape_Markdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/list/README.md" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(internshipList))
+	}))
+	defer server.Close()
+
+	s := NewScraper(server.Client())
+	config := CompanyConfig{Name: "Summer 2027 list", CareerURL: server.URL + "/list/README.md", SearchTerm: "intern", SourceType: SourceMarkdown}
+	result, err := s.Preview(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Jobs) != 4 {
+		t.Fatalf("expected 4 open jobs, got %d", len(result.Jobs))
+	}
+	job := result.Jobs[1]
+	if job.Company != "Stripe" || job.Source != "Summer 2027 list" || job.Detail == nil || job.Detail.PostedAt == nil {
+		t.Errorf("unexpected job %+v", job)
+	}
+	// The list is curated, so roles are taken whether or not they name the
+	// search term.
+	if result.Jobs[2].Title != "Software Engineering Co-op" {
+		t.Errorf("expected a role without the search term to be kept, got %+v", result.Jobs[2])
+	}
+	if result.Jobs[3].URL != server.URL+"/jobs/5" {
+		t.Errorf("expected relative links to resolve against the list, got %q", result.Jobs[3].URL)
+	}
+
+	reasons := make(map[string]int)
+	for _, r := range result.Rejected {
+		reasons[r.Reason]++
+	}
+	if reasons[RejectClosed] != 2 || reasons[RejectNoSearchTerm] != 0 {
+		t.Errorf("unexpected rejections %+v", result.Rejected)
+	}
+
+	// A local file is read instead of the URL.
+	path := filepath.Join(t.TempDir(), "README.md")
+	os.WriteFile(path, []byte(internshipList), 0o644)
+	config.CareerURL, config.SourceID = "https://github.com/example/internships", path
+	result, err = s.Scrape(config)
+	if err != nil || len(result.Jobs) != 4 || result.Jobs[3].URL != "https://github.com/jobs/5" {
+		t.Errorf("unexpected result from file: %v %+v", err, result)
+	}
+
+	config.SourceID = ""
+	config.CareerURL = server.URL + "/other.md"
+	if result, err := s.Scrape(config); err == nil || result.ErrorClass != "http_status" {
+		t.Errorf("expected a status error, got %v", err)
+	}
+	os.WriteFile(path, []byte("# No tables here\n"), 0o644)
+	config.SourceID = path
+	if result, err := s.Scrape(config); err == nil || result.ErrorClass != "read" {
+		t.Errorf("expected an error for a list without tables, got %v", err)
+	}
+}
+
+func TestRawMarkdownURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/example/internships/blob/dev/README.md":           "https://raw.githubusercontent.com/example/internships/dev/README.md",
+		"https://github.com/example/internships":                              "https://github.com/example/internships",
+		"https://raw.githubusercontent.com/example/internships/dev/README.md": "https://raw.githubusercontent.com/example/internships/dev/README.md",
+	}
+	for in, want := range tests {
+		if got := rawMarkdownURL(in); got != want {
+			t.Errorf("rawMarkdownURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package scraper
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"intern-job-tracker/internal/metrics"
+)
+
+var (
+	scrapesTotal = metrics.Default.NewCounterVec(prometheus.CounterOpts{
+		Name: "intern_tracker_scrapes_total",
+		Help: "Career page scrapes by company and outcome (success or failure).",
+	}, []string{"company", "outcome"})
+	scrapeDuration = metrics.Default.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "intern_tracker_scrape_duration_seconds",
+		Help:    "Time taken to fetch and parse a company's career page.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"company"})
+)
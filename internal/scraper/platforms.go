@@ -0,0 +1,263 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"intern-job-tracker/internal/model"
+)
+
+// workdayPageSize and workdayMaxPages bound how many postings are read from a
+// Workday board, which pages its search results.
+const (
+	workdayPageSize = 20
+	workdayMaxPages = 10
+)
+
+// platformJob is a posting read from a platform's API.
+type platformJob struct {
+	title    string
+	url      string
+	location string
+}
+
+// usesAPI reports whether a source type reads jobs from a platform's API.
+func usesAPI(sourceType string) bool {
+	switch sourceType {
+	case PlatformGreenhouse, PlatformLever, PlatformAshby, PlatformWorkday:
+		return true
+	}
+	return false
+}
+
+// boardURL returns the page listing a company's jobs on a platform scraped
+// as HTML, or the career page for SourceHTML.
+func (s *Scraper) boardURL(config CompanyConfig) string {
+	switch config.SourceType {
+	case PlatformICIMS:
+		return s.platformURL("https://" + config.SourceID + "/jobs/search?ss=1&in_iframe=1&searchKeyword=" + url.QueryEscape(config.SearchTerm))
+	case PlatformJobvite:
+		return s.platformURL("https://jobs.jobvite.com/" + config.SourceID + "/jobs")
+	}
+	return config.CareerURL
+}
+
+// platformURL points a platform URL at apiBase when it is set.
+func (s *Scraper) platformURL(raw string) string {
+	if s.apiBase == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	base, err := url.Parse(s.apiBase)
+	if err != nil {
+		return raw
+	}
+	u.Scheme, u.Host = base.Scheme, base.Host
+	return u.String()
+}
+
+// scrapeAPI reads a company's jobs from its platform's API, keeping the
+// postings whose title contains the search term.
+func (s *Scraper) scrapeAPI(config CompanyConfig, result *Result, reject func(Rejection)) error {
+	var jobs []platformJob
+	var err error
+	switch config.SourceType {
+	case PlatformGreenhouse:
+		jobs, err = s.greenhouseJobs(config, result)
+	case PlatformLever:
+		jobs, err = s.leverJobs(config, result)
+	case PlatformAshby:
+		jobs, err = s.ashbyJobs(config, result)
+	case PlatformWorkday:
+		jobs, err = s.workdayJobs(config, result)
+	default:
+		return fmt.Errorf("source type %q has no API", config.SourceType)
+	}
+	if err != nil {
+		return err
+	}
+
+	filter := newLinkFilter(result.FinalURL, config.SearchTerm, reject)
+	for _, j := range jobs {
+		u := filter.accept(j.url, strings.Join(strings.Fields(j.title), " "))
+		if u == "" {
+			continue
+		}
+		result.Jobs = append(result.Jobs, &model.Job{
+			Company:      config.Name,
+			Title:        strings.Join(strings.Fields(j.title), " "),
+			URL:          u,
+			Location:     strings.TrimSpace(j.location),
+			DiscoveredAt: time.Now(),
+		})
+	}
+	return nil
+}
+
+func (s *Scraper) greenhouseJobs(config CompanyConfig, result *Result) ([]platformJob, error) {
+	var resp struct {
+		Jobs []struct {
+			Title       string `json:"title"`
+			AbsoluteURL string `json:"absolute_url"`
+			Location    struct {
+				Name string `json:"name"`
+			} `json:"location"`
+		} `json:"jobs"`
+	}
+	apiURL := "https://boards-api.greenhouse.io/v1/boards/" + config.SourceID + "/jobs"
+	if err := s.requestJSON(http.MethodGet, apiURL, nil, &resp, result); err != nil {
+		return nil, err
+	}
+	jobs := make([]platformJob, len(resp.Jobs))
+	for i, j := range resp.Jobs {
+		jobs[i] = platformJob{title: j.Title, url: j.AbsoluteURL, location: j.Location.Name}
+	}
+	return jobs, nil
+}
+
+func (s *Scraper) leverJobs(config CompanyConfig, result *Result) ([]platformJob, error) {
+	var resp []struct {
+		Text       string `json:"text"`
+		HostedURL  string `json:"hostedUrl"`
+		Categories struct {
+			Location string `json:"location"`
+		} `json:"categories"`
+	}
+	apiURL := "https://api.lever.co/v0/postings/" + config.SourceID + "?mode=json"
+	if err := s.requestJSON(http.MethodGet, apiURL, nil, &resp, result); err != nil {
+		return nil, err
+	}
+	jobs := make([]platformJob, len(resp))
+	for i, j := range resp {
+		jobs[i] = platformJob{title: j.Text, url: j.HostedURL, location: j.Categories.Location}
+	}
+	return jobs, nil
+}
+
+func (s *Scraper) ashbyJobs(config CompanyConfig, result *Result) ([]platformJob, error) {
+	var resp struct {
+		Jobs []struct {
+			Title    string `json:"title"`
+			JobURL   string `json:"jobUrl"`
+			Location string `json:"location"`
+		} `json:"jobs"`
+	}
+	apiURL := "https://api.ashbyhq.com/posting-api/job-board/" + config.SourceID
+	if err := s.requestJSON(http.MethodGet, apiURL, nil, &resp, result); err != nil {
+		return nil, err
+	}
+	jobs := make([]platformJob, len(resp.Jobs))
+	for i, j := range resp.Jobs {
+		jobs[i] = platformJob{title: j.Title, url: j.JobURL, location: j.Location}
+	}
+	return jobs, nil
+}
+
+// workdayJobs searches a Workday board for the search term, reading up to
+// workdayMaxPages pages of results.
+func (s *Scraper) workdayJobs(config CompanyConfig, result *Result) ([]platformJob, error) {
+	host, rest, _ := strings.Cut(config.SourceID, "/")
+	tenant, site, _ := strings.Cut(rest, "/")
+	apiURL := "https://" + host + "/wday/cxs/" + tenant + "/" + site + "/jobs"
+	jobBase := "https://" + host + "/" + site
+	if hasDomain(host, "myworkdaysite.com") {
+		jobBase = "https://" + host + "/recruiting/" + tenant + "/" + site
+	}
+
+	var jobs []platformJob
+	for page := 0; page < workdayMaxPages; page++ {
+		req := map[string]interface{}{
+			"appliedFacets": map[string]interface{}{},
+			"limit":         workdayPageSize,
+			"offset":        page * workdayPageSize,
+			"searchText":    config.SearchTerm,
+		}
+		var resp struct {
+			Total       int `json:"total"`
+			JobPostings []struct {
+				Title         string `json:"title"`
+				ExternalPath  string `json:"externalPath"`
+				LocationsText string `json:"locationsText"`
+			} `json:"jobPostings"`
+		}
+		if err := s.requestJSON(http.MethodPost, apiURL, req, &resp, result); err != nil {
+			return nil, err
+		}
+		for _, j := range resp.JobPostings {
+			jobs = append(jobs, platformJob{title: j.Title, url: jobBase + j.ExternalPath, location: j.LocationsText})
+		}
+		if len(resp.JobPostings) < workdayPageSize || len(jobs) >= resp.Total {
+			break
+		}
+	}
+	return jobs, nil
+}
+
+// requestJSON sends a request to a platform API and decodes its JSON response
+// into v, recording the fetch in result as scrape does for pages.
+func (s *Scraper) requestJSON(method, apiURL string, body, v interface{}, result *Result) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, s.platformURL(apiURL), reqBody)
+	if err != nil {
+		result.ErrorClass = "network"
+		return fmt.Errorf("failed to fetch %s: %w", apiURL, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		result.ErrorClass = fetchErrorClass(err)
+		return fmt.Errorf("failed to fetch %s: %w", apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+	result.Pages++
+	cr := &countingReader{r: resp.Body}
+	data, _ := io.ReadAll(cr)
+	result.Bytes += cr.n
+	if resp.StatusCode != http.StatusOK {
+		result.ErrorClass = "http_status"
+		return fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, apiURL)
+	}
+	if cr.err != nil {
+		result.ErrorClass = "read"
+		return fmt.Errorf("failed to read %s: %w", apiURL, cr.err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		result.ErrorClass = "read"
+		return fmt.Errorf("invalid response from %s: %w", apiURL, err)
+	}
+	return nil
+}
+
+// fetchErrorClass returns the Result.ErrorClass of a failed request.
+func fetchErrorClass(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	return "network"
+}
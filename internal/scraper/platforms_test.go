@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestScrape_Platforms(t *testing.T) {
+	var workdayRequests []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/boards/acme/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": [
+			{"title": "Software Engineering Intern", "absolute_url": "https://boards.greenhouse.io/acme/jobs/1", "location": {"name": "Austin"}},
+			{"title": "Staff Engineer", "absolute_url": "https://boards.greenhouse.io/acme/jobs/2", "location": {"name": "Remote"}}
+		]}`))
+	})
+	mux.HandleFunc("/v0/postings/acme", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("mode") != "json" {
+			http.Error(w, "expected mode=json", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`[{"text": "Data Intern", "hostedUrl": "https://jobs.lever.co/acme/1", "categories": {"location": "NYC"}}]`))
+	})
+	mux.HandleFunc("/posting-api/job-board/acme", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": [{"title": "Design Intern", "jobUrl": "https://jobs.ashbyhq.com/acme/1", "location": "SF"}]}`))
+	})
+	mux.HandleFunc("/wday/cxs/acme/External/jobs", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "expected a JSON POST", http.StatusBadRequest)
+			return
+		}
+		workdayRequests = append(workdayRequests, req)
+		if req["offset"] == float64(0) {
+			postings := `{"title": "Filler Intern", "externalPath": "/job/Filler", "locationsText": "Remote"}`
+			for i := 1; i < workdayPageSize; i++ {
+				postings += `, {"title": "Filler Intern", "externalPath": "/job/Filler", "locationsText": "Remote"}`
+			}
+			w.Write([]byte(`{"total": 21, "jobPostings": [` + postings + `]}`))
+			return
+		}
+		w.Write([]byte(`{"total": 21, "jobPostings": [{"title": "Finance Intern", "externalPath": "/job/Finance_R1", "locationsText": "Chicago"}]}`))
+	})
+	mux.HandleFunc("/acme/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<table><tr><td><a href="/acme/job/o1">Marketing Intern</a></td></tr></table>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := NewScraper(server.Client())
+	s.apiBase = server.URL
+
+	tests := []struct {
+		sourceType, sourceID string
+		title, url, location string
+	}{
+		{PlatformGreenhouse, "acme", "Software Engineering Intern", "https://boards.greenhouse.io/acme/jobs/1", "Austin"},
+		{PlatformLever, "acme", "Data Intern", "https://jobs.lever.co/acme/1", "NYC"},
+		{PlatformAshby, "acme", "Design Intern", "https://jobs.ashbyhq.com/acme/1", "SF"},
+		{PlatformJobvite, "acme", "Marketing Intern", server.URL + "/acme/job/o1", ""},
+	}
+	for _, tt := range tests {
+		config := CompanyConfig{Name: "Acme", SearchTerm: "intern", SourceType: tt.sourceType, SourceID: tt.sourceID}
+		result, err := s.Preview(config)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.sourceType, err)
+			continue
+		}
+		if len(result.Jobs) != 1 {
+			t.Errorf("%s: expected 1 job, got %d", tt.sourceType, len(result.Jobs))
+			continue
+		}
+		job := result.Jobs[0]
+		if job.Company != "Acme" || job.Title != tt.title || job.URL != tt.url || job.Location != tt.location {
+			t.Errorf("%s: unexpected job %+v", tt.sourceType, job)
+		}
+		if result.StatusCode != 200 || result.Pages != 1 {
+			t.Errorf("%s: unexpected result %+v", tt.sourceType, result)
+		}
+	}
+
+	greenhouse, _ := s.Preview(CompanyConfig{Name: "Acme", SearchTerm: "intern", SourceType: PlatformGreenhouse, SourceID: "acme"})
+	if len(greenhouse.Rejected) != 1 || greenhouse.Rejected[0].Text != "Staff Engineer" {
+		t.Errorf("expected the other posting to be rejected, got %+v", greenhouse.Rejected)
+	}
+
+	// Workday pages through the search results, which repeat a posting here.
+	result, err := s.Scrape(CompanyConfig{Name: "Acme", SearchTerm: "intern", SourceType: PlatformWorkday,
+		SourceID: "acme.wd5.myworkdayjobs.com/acme/External"})
+	if err != nil {
+		t.Fatalf("workday: unexpected error: %v", err)
+	}
+	if len(workdayRequests) != 2 || workdayRequests[1]["offset"] != float64(workdayPageSize) || workdayRequests[0]["searchText"] != "intern" {
+		t.Errorf("unexpected workday requests %v", workdayRequests)
+	}
+	if len(result.Jobs) != 2 || result.Jobs[1].URL != "https://acme.wd5.myworkdayjobs.com/External/job/Finance_R1" ||
+		result.Jobs[1].Location != "Chicago" || result.Pages != 2 {
+		t.Errorf("unexpected workday result %+v", result.Jobs)
+	}
+
+	_, err = s.Scrape(CompanyConfig{Name: "Acme", SearchTerm: "intern", SourceType: PlatformGreenhouse, SourceID: "missing"})
+	if err == nil {
+		t.Error("expected an error for a missing board")
+	}
+}
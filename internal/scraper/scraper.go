@@ -1,10 +1,11 @@
 package scraper
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 // Scraper fetches and parses job listings from company career pages.
 type Scraper struct {
 	client *http.Client
+	// apiBase, when set, replaces the scheme and host of platform URLs, so
+	// tests can serve them.
+	apiBase string
 }
 
 // NewScraper creates a new scraper with the given HTTP client.
@@ -31,100 +35,291 @@ func (s *Scraper) ScrapeAll() ([]*model.Job, error) {
 	return s.ScrapeAllWithConfigs(DefaultCompanies())
 }
 
-// ScrapeAllWithConfigs scrapes all given companies.
+// ScrapeAllWithConfigs scrapes all given companies. A company that fails does
+// not stop the others: the jobs found are returned together with a
+// *CompanyError for each failed company, joined with errors.Join.
 func (s *Scraper) ScrapeAllWithConfigs(configs []CompanyConfig) ([]*model.Job, error) {
 	var allJobs []*model.Job
+	var errs []error
 
 	for _, config := range configs {
-		jobs, err := s.ScrapeCompany(config)
+		result, err := s.Scrape(config)
 		if err != nil {
-			// Log error but continue with other companies
-			fmt.Printf("Error scraping %s: %v\n", config.Name, err)
+			errs = append(errs, &CompanyError{Company: config.Name, Class: result.ErrorClass, Err: err})
 			continue
 		}
-		allJobs = append(allJobs, jobs...)
+		allJobs = append(allJobs, result.Jobs...)
 	}
 
-	return allJobs, nil
+	return allJobs, errors.Join(errs...)
+}
+
+// CompanyError is the failed scrape of one company.
+type CompanyError struct {
+	Company string
+	// Class is the failure's Result.ErrorClass.
+	Class string
+	Err   error
+}
+
+func (e *CompanyError) Error() string {
+	return fmt.Sprintf("scraping %s: %v", e.Company, e.Err)
+}
+
+func (e *CompanyError) Unwrap() error {
+	return e.Err
+}
+
+// CompanyErrors returns the *CompanyError values in an error returned by
+// ScrapeAllWithConfigs.
+func CompanyErrors(err error) []*CompanyError {
+	var out []*CompanyError
+	var ce *CompanyError
+	if errors.As(err, &ce) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				out = append(out, CompanyErrors(e)...)
+			}
+			return out
+		}
+		return []*CompanyError{ce}
+	}
+	return nil
+}
+
+// Result describes one scrape of a company's career page.
+type Result struct {
+	Jobs       []*model.Job
+	StatusCode int   // HTTP status of the career page, 0 if it was not reached
+	Bytes      int64 // bytes of page content read
+	Pages      int   // pages fetched
+	Duration   time.Duration
+	// ErrorClass groups failures for reporting: "timeout", "network",
+	// "http_status", "read" or "challenge". Empty on success.
+	ErrorClass string
+	// Fingerprint summarizes the page's markup structure, so that a site
+	// redesign can be told apart from a genuine lack of openings.
+	Fingerprint string
+	// Challenge is set when the page is a bot check rather than the career
+	// page.
+	Challenge bool
+	// FinalURL is the career page's URL after redirects.
+	FinalURL string
+	// Detection is the applicant tracking system Preview found behind a
+	// career page scraped as HTML, or nil if none was recognized.
+	Detection *Detection
+	// Rejected lists the links Preview did not take as jobs, and why.
+	Rejected []Rejection
 }
 
 // ScrapeCompany scrapes a single company's career page.
 func (s *Scraper) ScrapeCompany(config CompanyConfig) ([]*model.Job, error) {
-	resp, err := s.client.Get(config.CareerURL)
+	result, err := s.Scrape(config)
+	return result.Jobs, err
+}
+
+// Scrape scrapes a single company's career page and reports how the fetch
+// went. The result is never nil, even when an error is returned.
+func (s *Scraper) Scrape(config CompanyConfig) (*Result, error) {
+	start := time.Now()
+	result := &Result{}
+	err := s.scrape(config, result, false)
+	result.Duration = time.Since(start)
+
+	scrapeDuration.WithLabelValues(config.Name).Observe(result.Duration.Seconds())
+	if err != nil {
+		scrapesTotal.WithLabelValues(config.Name, "failure").Inc()
+	} else {
+		scrapesTotal.WithLabelValues(config.Name, "success").Inc()
+	}
+	return result, err
+}
+
+// Preview scrapes a company like Scrape, without recording metrics, and also
+// reports the detected platform and the links rejected. It is meant for
+// trying out a company's configuration before saving it.
+func (s *Scraper) Preview(config CompanyConfig) (*Result, error) {
+	start := time.Now()
+	result := &Result{}
+	err := s.scrape(config, result, true)
+	result.Duration = time.Since(start)
+	return result, err
+}
+
+// scrape fetches and parses a career page, or reads the jobs from the
+// company's platform or curated list. With explain, rejected links and the page's platform
+// are recorded as well.
+func (s *Scraper) scrape(config CompanyConfig, result *Result, explain bool) error {
+	var reject func(Rejection)
+	if explain {
+		reject = func(r Rejection) { result.Rejected = append(result.Rejected, r) }
+	}
+	if config.SourceType == SourceMarkdown {
+		return s.scrapeMarkdown(config, result, reject)
+	}
+	if usesAPI(config.SourceType) {
+		return s.scrapeAPI(config, result, reject)
+	}
+
+	pageURL := s.boardURL(config)
+	resp, err := s.client.Get(pageURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", config.CareerURL, err)
+		result.ErrorClass = fetchErrorClass(err)
+		return fmt.Errorf("failed to fetch %s: %w", pageURL, err)
 	}
 	defer resp.Body.Close()
 
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+	result.Pages = 1
+	body := &countingReader{r: resp.Body}
+	page, _ := io.ReadAll(body)
+	result.Bytes = body.n
+	if explain && config.SourceType != PlatformICIMS && config.SourceType != PlatformJobvite {
+		result.Detection = DetectPage(result.FinalURL, page)
+		if d := result.Detection; d != nil && d.Evidence == "url" && result.FinalURL != pageURL {
+			d.Evidence = "redirect"
+		}
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, config.CareerURL)
+		if isChallengePage(page) {
+			result.Challenge = true
+			result.ErrorClass = "challenge"
+			return fmt.Errorf("bot challenge (status %d) for %s", resp.StatusCode, pageURL)
+		}
+		result.ErrorClass = "http_status"
+		return fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, pageURL)
+	}
+	if body.err != nil {
+		result.ErrorClass = "read"
+		return fmt.Errorf("failed to read %s: %w", pageURL, body.err)
 	}
 
-	links := parseJobLinks(resp.Body, config.CareerURL, config.SearchTerm)
+	var links []jobLink
+	if config.Selectors.Job != "" {
+		links, err = parseListings(bytes.NewReader(page), pageURL, config.SearchTerm, config.Selectors, reject)
+		if err != nil {
+			result.ErrorClass = "read"
+			return fmt.Errorf("failed to parse %s: %w", pageURL, err)
+		}
+	} else {
+		links = findJobLinks(bytes.NewReader(page), pageURL, config.SearchTerm, reject)
+	}
+	result.Fingerprint = Fingerprint(bytes.NewReader(page))
+	if len(links) == 0 && isChallengePage(page) {
+		result.Challenge = true
+		result.ErrorClass = "challenge"
+		return fmt.Errorf("bot challenge for %s", pageURL)
+	}
 
-	var jobs []*model.Job
 	for _, link := range links {
 		job := &model.Job{
 			Company:      config.Name,
 			Title:        link.title,
 			URL:          link.url,
+			Location:     link.location,
 			DiscoveredAt: time.Now(),
 		}
-		jobs = append(jobs, job)
+		result.Jobs = append(result.Jobs, job)
 	}
 
-	return jobs, nil
+	return nil
+}
+
+// countingReader counts the bytes read through it and remembers the first
+// error other than io.EOF.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if err != nil && err != io.EOF && c.err == nil {
+		c.err = err
+	}
+	return n, err
 }
 
 type jobLink struct {
-	url   string
-	title string
+	url      string
+	title    string
+	location string
 }
 
-// parseJobLinks extracts job links from HTML content.
+// parseJobLinks extracts job links from HTML content. An element whose class
+// mentions "location" following a job link is taken as that job's location.
 func parseJobLinks(r io.Reader, baseURL string, searchTerm string) []jobLink {
-	var links []jobLink
-	seen := make(map[string]bool)
+	return findJobLinks(r, baseURL, searchTerm, nil)
+}
 
-	base, _ := url.Parse(baseURL)
+// findJobLinks is parseJobLinks reporting the links that are not jobs to
+// reject, which may be nil.
+func findJobLinks(r io.Reader, baseURL string, searchTerm string, reject func(Rejection)) []jobLink {
+	var links []jobLink
+	filter := newLinkFilter(baseURL, searchTerm, reject)
 	z := html.NewTokenizer(r)
 
+	// last is the index of the most recent matched link, or -1 when the most
+	// recent anchor was not a job link.
+	last := -1
+	captureLocation := false
+
 	for {
 		tt := z.Next()
 		switch tt {
 		case html.ErrorToken:
 			return links
+		case html.TextToken:
+			if captureLocation {
+				if text := strings.TrimSpace(string(z.Text())); text != "" {
+					links[last].location = text
+					captureLocation = false
+				}
+			}
 		case html.StartTagToken:
 			t := z.Token()
-			if t.Data == "a" {
-				var href, text string
-				for _, attr := range t.Attr {
-					if attr.Key == "href" {
-						href = attr.Val
-					}
+			if t.Data != "a" {
+				if last >= 0 && links[last].location == "" && isLocationElement(t) {
+					captureLocation = true
 				}
+				continue
+			}
+			last = -1
+			captureLocation = false
 
-				// Get the text content
-				if z.Next() == html.TextToken {
-					text = strings.TrimSpace(z.Token().Data)
+			var href, text string
+			for _, attr := range t.Attr {
+				if attr.Key == "href" {
+					href = attr.Val
 				}
+			}
 
-				// Filter for intern positions
-				lowerText := strings.ToLower(text)
-				if href != "" && text != "" && strings.Contains(lowerText, strings.ToLower(searchTerm)) {
-					// Resolve relative URLs
-					linkURL, err := url.Parse(href)
-					if err != nil {
-						continue
-					}
-					resolvedURL := base.ResolveReference(linkURL).String()
-
-					if !seen[resolvedURL] {
-						seen[resolvedURL] = true
-						links = append(links, jobLink{url: resolvedURL, title: text})
-					}
-				}
+			// Get the text content
+			if z.Next() == html.TextToken {
+				text = strings.TrimSpace(z.Token().Data)
+			}
+
+			// Filter for intern positions
+			if resolvedURL := filter.accept(href, text); resolvedURL != "" {
+				links = append(links, jobLink{url: resolvedURL, title: text})
+				last = len(links) - 1
+			}
+		}
+	}
+}
+
+// isLocationElement reports whether an element is marked up as a location.
+func isLocationElement(t html.Token) bool {
+	for _, attr := range t.Attr {
+		switch attr.Key {
+		case "class", "data-testid", "itemprop":
+			if strings.Contains(strings.ToLower(attr.Val), "location") {
+				return true
 			}
 		}
 	}
+	return false
 }
@@ -84,6 +84,37 @@ func TestScraper_ScrapeAll(t *testing.T) {
 	}
 }
 
+func TestScraper_ScrapeAll_CompanyErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<html><body><a href="/job/1">Software Intern</a></body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewScraper(&http.Client{})
+	jobs, err := scraper.ScrapeAllWithConfigs([]CompanyConfig{
+		{Name: "Up", CareerURL: server.URL, SearchTerm: "intern"},
+		{Name: "Down", CareerURL: server.URL + "/down", SearchTerm: "intern"},
+	})
+	if len(jobs) != 1 || jobs[0].Company != "Up" {
+		t.Errorf("expected the working company's job, got %v", jobs)
+	}
+
+	errs := CompanyErrors(err)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 company error, got %v", err)
+	}
+	if errs[0].Company != "Down" || errs[0].Class != "http_status" || !strings.Contains(err.Error(), "scraping Down") {
+		t.Errorf("unexpected error %+v", errs[0])
+	}
+	if CompanyErrors(nil) != nil {
+		t.Error("expected no company errors without an error")
+	}
+}
+
 func TestScraper_ParseJobLinks(t *testing.T) {
 	html := `
 	<a href="/careers/job/123">Software Engineering Intern</a>
@@ -116,6 +147,46 @@ func TestScraper_HTTPError(t *testing.T) {
 	}
 }
 
+func TestScraper_Scrape_Result(t *testing.T) {
+	page := `<html><body>
+		<a href="/jobs/1">Software Engineer Intern</a>
+		<a href="/jobs/2">Data Science Intern</a>
+	</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	scraper := NewScraper(&http.Client{})
+	result, err := scraper.Scrape(CompanyConfig{Name: "Acme", CareerURL: server.URL, SearchTerm: "intern"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Jobs) != 2 || result.StatusCode != 200 || result.Pages != 1 || result.Bytes != int64(len(page)) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.ErrorClass != "" {
+		t.Errorf("expected no error class, got %q", result.ErrorClass)
+	}
+
+	result, err = scraper.Scrape(CompanyConfig{Name: "Acme", CareerURL: server.URL + "/missing"})
+	if err == nil {
+		t.Fatal("expected error for HTTP 404")
+	}
+	if result.StatusCode != 404 || result.ErrorClass != "http_status" {
+		t.Errorf("expected http_status error with code 404, got %+v", result)
+	}
+
+	result, _ = scraper.Scrape(CompanyConfig{Name: "Acme", CareerURL: "http://localhost:99999"})
+	if result.ErrorClass != "network" {
+		t.Errorf("expected network error class, got %q", result.ErrorClass)
+	}
+}
+
 func TestScraper_Timeout(t *testing.T) {
 	// Test with invalid URL (connection refused)
 	scraper := NewScraper(&http.Client{})
@@ -129,3 +200,33 @@ func TestScraper_Timeout(t *testing.T) {
 		t.Error("expected error for connection failure")
 	}
 }
+
+func TestScraper_ParseJobLinks_Location(t *testing.T) {
+	html := `
+	<div class="job">
+		<a href="/jobs/1">Software Engineering Intern</a>
+		<span class="job-location">Mountain View, CA</span>
+	</div>
+	<div class="job">
+		<a href="/jobs/2">Data Intern</a>
+		<div data-testid="location"><span>Remote - US</span></div>
+	</div>
+	<div class="job">
+		<a href="/jobs/3">Backend Intern</a>
+	</div>
+	<a href="/about">About</a>
+	<span class="location">Headquarters</span>
+	`
+
+	links := parseJobLinks(strings.NewReader(html), "https://example.com", "intern")
+
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %d", len(links))
+	}
+	want := []string{"Mountain View, CA", "Remote - US", ""}
+	for i, link := range links {
+		if link.location != want[i] {
+			t.Errorf("link %d: expected location %q, got %q", i, want[i], link.location)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Notification modes.
+const (
+	// NotifyAll notifies every new job, and sends a summary when a run finds
+	// none.
+	NotifyAll = "all"
+	// NotifyNewJobs notifies every new job but sends no summaries.
+	NotifyNewJobs = "new_jobs"
+	// NotifyOff sends no job notifications. Alerts about failures are still
+	// sent.
+	NotifyOff = "off"
+)
+
+// MaxConcurrency is the most companies that may be scraped at once.
+const MaxConcurrency = 16
+
+// Settings are the options that can be changed while the server runs.
+type Settings struct {
+	Recipient         string `json:"recipient"`
+	Schedule          string `json:"schedule"`
+	Timezone          string `json:"timezone"`
+	NotificationMode  string `json:"notification_mode"`
+	ScrapeConcurrency int    `json:"scrape_concurrency"`
+}
+
+// Location returns the time zone schedules are evaluated in.
+func (s Settings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// Update changes some settings. Nil fields are left as they are.
+type Update struct {
+	Recipient         *string `json:"recipient"`
+	Schedule          *string `json:"schedule"`
+	Timezone          *string `json:"timezone"`
+	NotificationMode  *string `json:"notification_mode"`
+	ScrapeConcurrency *int    `json:"scrape_concurrency"`
+}
+
+// ValidationError reports an invalid setting.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// Validate checks every setting.
+func (s Settings) Validate() error {
+	if _, err := cron.ParseStandard(s.Schedule); err != nil {
+		return &ValidationError{"schedule", err.Error()}
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return &ValidationError{"timezone", fmt.Sprintf("unknown time zone %q", s.Timezone)}
+	}
+	switch s.NotificationMode {
+	case NotifyAll, NotifyNewJobs, NotifyOff:
+	default:
+		return &ValidationError{"notification_mode", fmt.Sprintf("must be %q, %q or %q", NotifyAll, NotifyNewJobs, NotifyOff)}
+	}
+	if s.ScrapeConcurrency < 1 || s.ScrapeConcurrency > MaxConcurrency {
+		return &ValidationError{"scrape_concurrency", fmt.Sprintf("must be between 1 and %d", MaxConcurrency)}
+	}
+	if strings.ContainsAny(s.Recipient, " \t\n") {
+		return &ValidationError{"recipient", "must be a phone number or Apple ID without spaces"}
+	}
+	return nil
+}
+
+// Store persists settings as strings under keys.
+type Store interface {
+	GetAll() (map[string]string, error)
+	SetMany(values map[string]string) error
+}
+
+// Keys the settings are stored under.
+const (
+	keyRecipient         = "recipient"
+	keySchedule          = "schedule"
+	keyTimezone          = "timezone"
+	keyNotificationMode  = "notification_mode"
+	keyScrapeConcurrency = "scrape_concurrency"
+)
+
+// Service holds the current settings, persists changes to a store and
+// notifies listeners so that changes apply without a restart.
+type Service struct {
+	store Store
+
+	mu        sync.Mutex
+	current   Settings
+	listeners []func(Settings)
+}
+
+// NewService loads settings from store. Settings that were never saved take
+// their value from defaults, so command line flags provide initial values
+// and settings saved through the API take precedence over them.
+func NewService(store Store, defaults Settings) (*Service, error) {
+	values, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	s := defaults
+	if v, ok := values[keyRecipient]; ok {
+		s.Recipient = v
+	}
+	if v, ok := values[keySchedule]; ok {
+		s.Schedule = v
+	}
+	if v, ok := values[keyTimezone]; ok {
+		s.Timezone = v
+	}
+	if v, ok := values[keyNotificationMode]; ok {
+		s.NotificationMode = v
+	}
+	if v, ok := values[keyScrapeConcurrency]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, &ValidationError{"scrape_concurrency", fmt.Sprintf("stored value %q is not a number", v)}
+		}
+		s.ScrapeConcurrency = n
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &Service{store: store, current: s}, nil
+}
+
+// Get returns the current settings.
+func (s *Service) Get() Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// OnChange registers a function called with the new settings after every
+// successful update.
+func (s *Service) OnChange(fn func(Settings)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Update validates and saves the settings it changes, then notifies
+// listeners. On error nothing is changed.
+func (s *Service) Update(u Update) (Settings, error) {
+	s.mu.Lock()
+	next := s.current
+	changed := make(map[string]string)
+	if u.Recipient != nil {
+		next.Recipient = strings.TrimSpace(*u.Recipient)
+		changed[keyRecipient] = next.Recipient
+	}
+	if u.Schedule != nil {
+		next.Schedule = strings.TrimSpace(*u.Schedule)
+		changed[keySchedule] = next.Schedule
+	}
+	if u.Timezone != nil {
+		next.Timezone = strings.TrimSpace(*u.Timezone)
+		changed[keyTimezone] = next.Timezone
+	}
+	if u.NotificationMode != nil {
+		next.NotificationMode = *u.NotificationMode
+		changed[keyNotificationMode] = next.NotificationMode
+	}
+	if u.ScrapeConcurrency != nil {
+		next.ScrapeConcurrency = *u.ScrapeConcurrency
+		changed[keyScrapeConcurrency] = strconv.Itoa(next.ScrapeConcurrency)
+	}
+	if err := next.Validate(); err != nil {
+		s.mu.Unlock()
+		return s.Get(), err
+	}
+
+	// Only changed settings are saved, so the others keep following their
+	// defaults.
+	if err := s.store.SetMany(changed); err != nil {
+		s.mu.Unlock()
+		return s.Get(), err
+	}
+	s.current = next
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(next)
+	}
+	return next, nil
+}
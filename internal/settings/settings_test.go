@@ -0,0 +1,94 @@
+package settings
+
+import (
+	"errors"
+	"testing"
+)
+
+type mapStore map[string]string
+
+func (m mapStore) GetAll() (map[string]string, error) {
+	return m, nil
+}
+
+func (m mapStore) SetMany(values map[string]string) error {
+	for k, v := range values {
+		m[k] = v
+	}
+	return nil
+}
+
+func defaults() Settings {
+	return Settings{Schedule: "0 9 * * *", Timezone: "Local", NotificationMode: NotifyAll, ScrapeConcurrency: 1}
+}
+
+func TestNewService_StoredOverDefaults(t *testing.T) {
+	store := mapStore{"schedule": "*/30 * * * *", "scrape_concurrency": "4"}
+	svc, err := NewService(store, defaults())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := svc.Get()
+	if got.Schedule != "*/30 * * * *" || got.ScrapeConcurrency != 4 || got.NotificationMode != NotifyAll {
+		t.Errorf("unexpected settings %+v", got)
+	}
+
+	if _, err := NewService(mapStore{"scrape_concurrency": "many"}, defaults()); err == nil {
+		t.Error("expected error for an invalid stored value")
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	store := mapStore{}
+	svc, _ := NewService(store, defaults())
+
+	var applied []Settings
+	svc.OnChange(func(s Settings) { applied = append(applied, s) })
+
+	recipient, schedule := " +1234567890 ", "0 8 * * 1-5"
+	got, err := svc.Update(Update{Recipient: &recipient, Schedule: &schedule})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Recipient != "+1234567890" || got.Schedule != schedule {
+		t.Errorf("unexpected settings %+v", got)
+	}
+	if len(applied) != 1 || applied[0] != got {
+		t.Errorf("expected listeners to get the new settings, got %+v", applied)
+	}
+	if len(store) != 2 || store["schedule"] != schedule {
+		t.Errorf("expected only changed settings to be saved, got %v", store)
+	}
+}
+
+func TestService_UpdateInvalid(t *testing.T) {
+	svc, _ := NewService(mapStore{}, defaults())
+	calls := 0
+	svc.OnChange(func(Settings) { calls++ })
+
+	bad := func(u Update, field string) {
+		t.Helper()
+		_, err := svc.Update(u)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != field {
+			t.Errorf("expected %s validation error, got %v", field, err)
+		}
+	}
+	schedule, zone, mode, concurrency, recipient := "every morning", "Mars/Olympus", "loud", 0, "John Appleseed"
+	bad(Update{Schedule: &schedule}, "schedule")
+	bad(Update{Timezone: &zone}, "timezone")
+	bad(Update{NotificationMode: &mode}, "notification_mode")
+	bad(Update{ScrapeConcurrency: &concurrency}, "scrape_concurrency")
+	bad(Update{Recipient: &recipient}, "recipient")
+
+	if calls != 0 || svc.Get() != defaults() {
+		t.Errorf("expected invalid updates to change nothing, got %+v", svc.Get())
+	}
+}
+
+func TestSettings_Location(t *testing.T) {
+	s := Settings{Timezone: "America/New_York"}
+	if s.Location().String() != "America/New_York" {
+		t.Errorf("unexpected location %v", s.Location())
+	}
+}
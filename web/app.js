@@ -6,6 +6,8 @@ let jobs = [];
 let companies = [];
 let logs = [];
 let metrics = {};
+let eventsConnected = false;
+let schedulerState = null;
 
 // DOM Elements
 const refreshBtn = document.getElementById('refresh-btn');
@@ -15,6 +17,7 @@ const toast = document.getElementById('toast');
 document.addEventListener('DOMContentLoaded', () => {
     setupTabs();
     setupEventListeners();
+    setupEventStream();
     loadAllData();
 });
 
@@ -34,16 +37,78 @@ function setupTabs() {
 // Event Listeners
 function setupEventListeners() {
     refreshBtn.addEventListener('click', handleRefresh);
+    document.getElementById('pause-btn').addEventListener('click', handlePauseToggle);
     document.getElementById('company-filter').addEventListener('change', renderJobs);
     document.getElementById('add-company-btn').addEventListener('click', () => openCompanyModal());
     document.getElementById('modal-cancel').addEventListener('click', closeCompanyModal);
+    document.getElementById('modal-preview').addEventListener('click', handleCompanyPreview);
     document.getElementById('company-form').addEventListener('submit', handleCompanySubmit);
 }
 
+// Live run events
+function setupEventStream() {
+    const source = new EventSource(`${API_BASE}/events`);
+    source.onopen = () => eventsConnected = true;
+    source.onerror = () => eventsConnected = false;
+
+    const on = (type, handler) => source.addEventListener(type, e => handler(JSON.parse(e.data)));
+    on('run_started', e => {
+        setRefreshRunning(true);
+        showRunProgress(`Run #${e.run_id} started`, 0);
+    });
+    on('company_started', e => {
+        showRunProgress(`Checking ${e.data.company} (${e.data.index}/${e.data.total})...`, (e.data.index - 1) / e.data.total);
+    });
+    on('company_finished', e => {
+        const c = e.data;
+        const text = c.status === 'success'
+            ? `${c.company}: ${c.jobs_found} found, ${c.new_jobs} new`
+            : `${c.company}: failed (${c.error_class || 'error'})`;
+        showRunProgress(text, null);
+    });
+    on('job_found', e => addJob(e.data));
+    on('notification_failed', e => showToast(`Notification failed for ${e.data.title}`, 'error'));
+    on('run_finished', e => {
+        const run = e.data;
+        showRunProgress(`Run #${run.id} ${run.state}: ${run.new_jobs} new jobs`, 1);
+        setTimeout(() => document.getElementById('run-progress').classList.add('hidden'), 5000);
+        setRefreshRunning(false);
+        Promise.all([loadLogs(), loadMetrics(), loadCompanyHealth()]).catch(console.error);
+    });
+}
+
+// Show run progress; fraction is between 0 and 1, or null to leave the bar
+function showRunProgress(text, fraction) {
+    document.getElementById('run-progress').classList.remove('hidden');
+    document.getElementById('run-progress-text').textContent = text;
+    if (fraction !== null) {
+        document.getElementById('run-progress-bar').style.width = `${Math.round(fraction * 100)}%`;
+    }
+}
+
+// Insert a newly found job without reloading the list
+function addJob(job) {
+    if (job.duplicate_of || jobs.some(j => j.id === job.id)) return;
+    jobs.push(job);
+    jobs.sort((a, b) => b.score - a.score);
+    populateCompanyFilter();
+    renderJobs();
+    renderJobsByCompany();
+    document.getElementById('total-jobs').textContent = jobs.length;
+    showToast(`✨ New: ${job.title} at ${job.company}`, 'success');
+}
+
+function setRefreshRunning(running) {
+    refreshBtn.disabled = running;
+    refreshBtn.innerHTML = running
+        ? '<span class="btn-icon">⏳</span> Running...'
+        : '<span class="btn-icon">🔄</span> Run Check Now';
+}
+
 // Load all data
 async function loadAllData() {
     try {
-        await Promise.all([loadJobs(), loadCompanies(), loadLogs(), loadMetrics()]);
+        await Promise.all([loadJobs(), loadCompanies(), loadLogs(), loadMetrics(), loadCompanyHealth(), loadSchedulerStatus()]);
     } catch (error) {
         showToast('Failed to load data', 'error');
         console.error(error);
@@ -82,6 +147,73 @@ async function loadMetrics() {
     renderMetrics();
 }
 
+// Load the global schedule's status
+async function loadSchedulerStatus() {
+    const response = await fetch(`${API_BASE}/scheduler`);
+    const status = response.ok ? await response.json() : null;
+    renderSchedulerStatus(status);
+}
+
+// Render the next scheduled check and the pause button in the header
+function renderSchedulerStatus(status) {
+    schedulerState = status?.state;
+    const el = document.getElementById('next-run');
+    const pauseBtn = document.getElementById('pause-btn');
+    pauseBtn.classList.toggle('hidden', !status || status.state === 'stopped');
+    pauseBtn.textContent = status?.state === 'paused' ? '▶️ Resume Schedule' : '⏸️ Pause Schedule';
+
+    if (status?.state === 'paused') {
+        el.textContent = 'Scheduled checks paused';
+    } else if (!status || status.state !== 'running' || !status.next_run) {
+        el.textContent = 'Scheduled checks off';
+    } else {
+        el.textContent = `Next check: ${formatDateTime(status.next_run)} (${status.timezone})`;
+    }
+}
+
+// Pause or resume scheduled checks
+async function handlePauseToggle() {
+    const action = schedulerState === 'paused' ? 'resume' : 'pause';
+    try {
+        const response = await fetch(`${API_BASE}/scheduler/${action}`, { method: 'POST' });
+        if (!response.ok) throw new Error(await response.text());
+        renderSchedulerStatus(await response.json());
+        showToast(action === 'pause' ? 'Scheduled checks paused' : 'Scheduled checks resumed', 'success');
+    } catch (error) {
+        showToast(`Failed to ${action} schedule`, 'error');
+    }
+}
+
+// Load company health
+async function loadCompanyHealth() {
+    const response = await fetch(`${API_BASE}/companies/health`);
+    const health = await response.json() || [];
+    renderCompanyHealth(health);
+}
+
+// Render companies needing attention on dashboard
+function renderCompanyHealth(health) {
+    const container = document.getElementById('company-health');
+    const flagged = health.filter(h => h.flags?.length);
+
+    if (flagged.length === 0) {
+        container.innerHTML = '<p class="empty">All companies healthy</p>';
+        return;
+    }
+
+    container.innerHTML = flagged.map(h => `
+        <div class="log-item ${h.flags.includes('failing') ? 'error' : 'warning'}">
+            <div class="log-details">
+                <strong>${escapeHtml(h.company)}</strong>
+                <span class="log-duration">${h.flags.join(', ')}</span>
+            </div>
+            <div class="log-time">
+                ${Math.round(h.success_rate * 100)}% ok · last success ${h.last_success ? formatDate(h.last_success) : 'never'}
+            </div>
+        </div>
+    `).join('');
+}
+
 // Render metrics on dashboard
 function renderMetrics() {
     document.getElementById('total-jobs').textContent = metrics.jobs?.total || 0;
@@ -146,14 +278,15 @@ function renderJobs() {
     const tbody = document.getElementById('jobs-tbody');
 
     if (filtered.length === 0) {
-        tbody.innerHTML = '<tr><td colspan="5"><div class="empty-state"><div class="empty-icon">🔍</div><p>No jobs found</p></div></td></tr>';
+        tbody.innerHTML = '<tr><td colspan="6"><div class="empty-state"><div class="empty-icon">🔍</div><p>No jobs found</p></div></td></tr>';
         return;
     }
 
     tbody.innerHTML = filtered.map(job => `
         <tr>
+            <td><span class="score-badge" title="${escapeHtml(formatBreakdown(job.score_breakdown))}">${job.score.toFixed(1)}</span></td>
             <td><span class="company-badge ${job.company.toLowerCase()}">${job.company}</span></td>
-            <td>${escapeHtml(job.title)}</td>
+            <td>${escapeHtml(job.title)}${job.duplicates ? ` <span class="dup-badge" title="Also posted under ${job.duplicates} other link(s)">+${job.duplicates}</span>` : ''}${job.source ? ` <span class="dup-badge" title="Imported from ${escapeHtml(job.source)}">list</span>` : ''}</td>
             <td>${job.location || 'N/A'}</td>
             <td>${formatDate(job.discovered_at)}</td>
             <td><a href="${job.url}" target="_blank" class="btn-apply">Apply →</a></td>
@@ -178,6 +311,7 @@ function renderCompanies() {
             </div>
             <p class="company-url">${truncateUrl(c.career_url)}</p>
             <p class="company-search">Search: "${c.search_term}"</p>
+            <p class="company-search">Checked: ${c.schedule ? escapeHtml(c.schedule) : 'global schedule'}${formatActiveRange(c)}</p>
             <div class="company-actions">
                 <button class="btn-small" onclick="editCompany(${c.id})">Edit</button>
                 <button class="btn-small btn-danger" onclick="deleteCompany(${c.id})">Delete</button>
@@ -214,40 +348,107 @@ function openCompanyModal(company = null) {
     document.getElementById('company-name').value = company?.name || '';
     document.getElementById('company-url').value = company?.career_url || '';
     document.getElementById('company-search').value = company?.search_term || 'intern';
+    document.getElementById('company-schedule').value = company?.schedule || '';
+    document.getElementById('company-active-from').value = company?.active_from || '';
+    document.getElementById('company-active-until').value = company?.active_until || '';
+    document.getElementById('company-selectors').value = company?.selectors || '';
+    document.getElementById('company-modal').dataset.sourceType = company?.source_type || '';
+    document.getElementById('company-modal').dataset.sourceId = company?.source_id || '';
     document.getElementById('company-modal').classList.remove('hidden');
 }
 
 function closeCompanyModal() {
     document.getElementById('company-modal').classList.add('hidden');
     document.getElementById('company-form').reset();
+    document.getElementById('company-preview').classList.add('hidden');
 }
 
-async function handleCompanySubmit(e) {
-    e.preventDefault();
-    const id = document.getElementById('company-id').value;
-    const data = {
+// Company fields as entered in the modal
+function companyFormData() {
+    return {
         name: document.getElementById('company-name').value,
         career_url: document.getElementById('company-url').value,
         search_term: document.getElementById('company-search').value || 'intern',
+        schedule: document.getElementById('company-schedule').value.trim(),
+        active_from: document.getElementById('company-active-from').value,
+        active_until: document.getElementById('company-active-until').value,
+        source_type: document.getElementById('company-modal').dataset.sourceType || '',
+        source_id: document.getElementById('company-modal').dataset.sourceId || '',
+        selectors: document.getElementById('company-selectors').value.trim(),
         enabled: true
     };
+}
+
+// Scrape the company as entered, without saving it
+async function handleCompanyPreview() {
+    const container = document.getElementById('company-preview');
+    const button = document.getElementById('modal-preview');
+    container.classList.remove('hidden');
+    container.innerHTML = '<p class="preview-summary">Checking the career page...</p>';
+    button.disabled = true;
 
     try {
-        if (id) {
-            await fetch(`${API_BASE}/companies/${id}`, {
-                method: 'PUT',
-                headers: { 'Content-Type': 'application/json' },
-                body: JSON.stringify(data)
-            });
-            showToast('Company updated!', 'success');
-        } else {
-            await fetch(`${API_BASE}/companies`, {
-                method: 'POST',
-                headers: { 'Content-Type': 'application/json' },
-                body: JSON.stringify(data)
-            });
-            showToast('Company added!', 'success');
+        const response = await fetch(`${API_BASE}/companies/preview`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(companyFormData())
+        });
+        if (!response.ok) throw new Error(await response.text());
+        renderCompanyPreview(await response.json());
+    } catch (error) {
+        container.innerHTML = `<p class="preview-summary error">${escapeHtml(error.message)}</p>`;
+    } finally {
+        button.disabled = false;
+    }
+}
+
+function renderCompanyPreview(p) {
+    let platform = '';
+    if (p.detection) {
+        platform = ` · Detected ${escapeHtml(p.detection.platform)} board ${escapeHtml(p.detection.source_id)} (${escapeHtml(p.detection.evidence)})`;
+    } else if (p.platform) {
+        platform = ` · Read from ${escapeHtml(p.platform)}`;
+    }
+    const status = `HTTP ${p.status_code || '—'} · ${p.duration_ms}ms${platform}`;
+    const summary = p.error
+        ? `<p class="preview-summary error">${escapeHtml(p.error)}<br>${status}</p>`
+        : `<p class="preview-summary">${p.jobs.length} matching job${p.jobs.length === 1 ? '' : 's'} · ${status}</p>`;
+
+    const jobs = p.jobs.map(j => `
+        <li>
+            <a href="${escapeHtml(j.url)}" target="_blank" rel="noopener">${escapeHtml(j.title)}</a>
+            ${j.location ? `· ${escapeHtml(j.location)}` : ''}
+            ${j.known ? '<span class="known">(already tracked)</span>' : ''}
+        </li>
+    `).join('');
+
+    const rejected = p.rejected.slice(0, 100).map(r => `
+        <li>${escapeHtml(r.text || r.url || '(empty link)')} <span class="reason">— ${escapeHtml(r.reason)}</span></li>
+    `).join('');
+
+    document.getElementById('company-preview').innerHTML = `
+        ${summary}
+        ${jobs ? `<ul>${jobs}</ul>` : ''}
+        ${p.rejected.length ? `<details><summary>${p.rejected.length} other links</summary><ul>${rejected}</ul></details>` : ''}
+    `;
+}
+
+async function handleCompanySubmit(e) {
+    e.preventDefault();
+    const id = document.getElementById('company-id').value;
+    const data = companyFormData();
+
+    try {
+        const response = await fetch(id ? `${API_BASE}/companies/${id}` : `${API_BASE}/companies`, {
+            method: id ? 'PUT' : 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(data)
+        });
+        if (!response.ok) {
+            showToast(await response.text(), 'error');
+            return;
         }
+        showToast(id ? 'Company updated!' : 'Company added!', 'success');
         closeCompanyModal();
         await loadCompanies();
     } catch (error) {
@@ -273,18 +474,39 @@ window.deleteCompany = async function (id) {
 
 // Handle refresh
 async function handleRefresh() {
-    refreshBtn.disabled = true;
-    refreshBtn.innerHTML = '<span class="btn-icon">⏳</span> Running...';
+    setRefreshRunning(true);
 
     try {
-        await fetch(`${API_BASE}/refresh`, { method: 'POST' });
-        showToast('Job check complete!', 'success');
-        await loadAllData();
+        const response = await fetch(`${API_BASE}/refresh`, { method: 'POST' });
+        if (!response.ok) throw new Error(await response.text());
+        const { run_id } = await response.json();
+        const run = await waitForRun(run_id);
+        if (run.state === 'done') {
+            showToast(`Job check complete! ${run.new_jobs} new`, 'success');
+        } else {
+            showToast(`Job check ${run.state}${run.error ? ': ' + run.error : ''}`, 'error');
+        }
+        // Live events already added new jobs; reload only without them
+        if (!eventsConnected) await loadAllData();
     } catch (error) {
         showToast('Failed: ' + error.message, 'error');
     } finally {
-        refreshBtn.disabled = false;
-        refreshBtn.innerHTML = '<span class="btn-icon">🔄</span> Run Check Now';
+        setRefreshRunning(false);
+    }
+}
+
+// Poll a run until it finishes
+async function waitForRun(id) {
+    for (;;) {
+        const response = await fetch(`${API_BASE}/runs/${id}`);
+        if (!response.ok) throw new Error(await response.text());
+        const run = await response.json();
+        if (['done', 'failed', 'cancelled', 'interrupted'].includes(run.state)) return run;
+
+        if (!eventsConnected && run.state === 'running' && run.companies_total) {
+            refreshBtn.innerHTML = `<span class="btn-icon">⏳</span> ${run.companies_done}/${run.companies_total} companies...`;
+        }
+        await new Promise(resolve => setTimeout(resolve, 1000));
     }
 }
 
@@ -301,12 +523,22 @@ function formatDateTime(dateStr) {
     });
 }
 
+function formatBreakdown(breakdown) {
+    if (!breakdown || breakdown.length === 0) return 'No scoring factors';
+    return breakdown.map(c => `${c.points > 0 ? '+' : ''}${c.points} ${c.reason}`).join('\n');
+}
+
 function escapeHtml(text) {
     const div = document.createElement('div');
     div.textContent = text;
     return div.innerHTML;
 }
 
+function formatActiveRange(company) {
+    if (!company.active_from && !company.active_until) return '';
+    return ` (${company.active_from || '…'} to ${company.active_until || '…'})`;
+}
+
 function truncateUrl(url) {
     try {
         const u = new URL(url);